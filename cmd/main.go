@@ -14,6 +14,9 @@ func main() {
 			return
 		}
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if hint := cli.Hint(err); hint != "" {
+			fmt.Fprintf(os.Stderr, "Hint: %s\n", hint)
+		}
 		os.Exit(1)
 	}
 }
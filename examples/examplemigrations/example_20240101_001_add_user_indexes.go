@@ -4,10 +4,11 @@ package examplemigrations
 
 import (
 	"context"
+	"errors"
 
-	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
 )
 
 // AddUserIndexesMigration adds indexes to the users collection
@@ -23,52 +24,27 @@ func (m *AddUserIndexesMigration) Description() string {
 	return "Add indexes to users collection for email and created_at fields"
 }
 
-// Up executes the migration
+// Up executes the migration. The indexes are created one at a time through
+// TrackedIndexes, so if a later one fails (e.g. duplicate emails break the unique
+// index) the ones already built are dropped again instead of leaking.
 func (m *AddUserIndexesMigration) Up(
 	ctx context.Context, db *mongo.Database,
-) error {
-	collection := db.Collection("users")
-
-	// Create index for email field (unique)
-	emailIndexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "email", Value: 1},
-		},
-		Options: options.Index().
-			SetName("idx_users_email_unique").
-			SetUnique(true),
-		// SetBackground is deprecated in MongoDB 4.2+
-	}
-
-	// Create index for created_at field
-	createdAtIndexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "created_at", Value: -1},
-		},
-		Options: options.Index().
-			SetName("idx_users_created_at"),
-		// SetBackground is deprecated in MongoDB 4.2+
-	}
-
-	// Create compound index for status and created_at
-	compoundIndexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "status", Value: 1},
-			{Key: "created_at", Value: -1},
-		},
-		Options: options.Index().
-			SetName("idx_users_status_created_at"),
-		// SetBackground is deprecated in MongoDB 4.2+
-	}
-
-	// Create all indexes
-	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		emailIndexModel,
-		createdAtIndexModel,
-		compoundIndexModel,
-	})
-
-	return err
+) (err error) {
+	tracked := migration.NewTrackedIndexes(db.Collection("users"))
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, tracked.Rollback(ctx))
+		}
+	}()
+
+	return tracked.Create(ctx,
+		// Index for created_at field
+		migration.Index(migration.Desc("created_at")).Name("idx_users_created_at"),
+		// Compound index for status and created_at
+		migration.Index(migration.Asc("status"), migration.Desc("created_at")).Name("idx_users_status_created_at"),
+		// Index for email field (unique); fails if existing users share an email
+		migration.Index(migration.Asc("email")).Name("idx_users_email_unique").Unique(),
+	)
 }
 
 // Down rolls back the migration
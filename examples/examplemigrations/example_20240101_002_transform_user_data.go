@@ -5,12 +5,13 @@ package examplemigrations
 import (
 	"context"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
 )
 
 // TransformUserDataMigration demonstrates data transformation operations
@@ -31,30 +32,19 @@ func (m *TransformUserDataMigration) Up(
 	ctx context.Context, db *mongo.Database,
 ) error {
 	collection := db.Collection("users")
-
-	// Find all users and transform their data
-	cursor, err := collection.Find(ctx, bson.D{})
-	if err != nil {
-		return fmt.Errorf("failed to find users: %w", err)
+	if err := migration.ProgressFromContext(ctx).CountTotal(ctx, collection, nil); err != nil {
+		return err
 	}
-	defer func() {
-		if closeErr := cursor.Close(ctx); closeErr != nil {
-			log.Printf("Error closing cursor: %v", closeErr)
-		}
-	}()
 
-	for cursor.Next(ctx) {
-		var user bson.M
-		if err := cursor.Decode(&user); err != nil {
-			return fmt.Errorf("failed to decode user: %w", err)
+	// Transform users in batches so large collections never hold one long-running cursor
+	return migration.ForEachBatch(ctx, collection, bson.D{}, 500, func(ctx context.Context, users []bson.M) error {
+		for _, user := range users {
+			if err := m.transformSingleUser(ctx, collection, user); err != nil {
+				return fmt.Errorf("failed to transform user %v: %w", user["_id"], err)
+			}
 		}
-
-		if err := m.transformSingleUser(ctx, collection, user); err != nil {
-			return fmt.Errorf("failed to transform user %v: %w", user["_id"], err)
-		}
-	}
-
-	return cursor.Err()
+		return nil
+	})
 }
 
 func (m *TransformUserDataMigration) transformSingleUser(
@@ -14,10 +14,14 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/dbconn"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
 )
 
-const connectionTimeout = 10 * time.Second
+const (
+	connectionTimeout = 10 * time.Second
+	sampleMessage     = "Hello from mongo-migration!"
+)
 
 type ExampleMigration struct{}
 
@@ -31,7 +35,7 @@ func (m *ExampleMigration) Up(ctx context.Context, db *mongo.Database) error {
 	collection := db.Collection("sample_collection")
 
 	_, err := collection.InsertOne(ctx, bson.M{
-		"message":    "Hello from mongo-migration!",
+		"message":    sampleMessage,
 		"created_at": time.Now(),
 	})
 	if err != nil {
@@ -53,12 +57,18 @@ func (m *ExampleMigration) Up(ctx context.Context, db *mongo.Database) error {
 }
 
 func (m *ExampleMigration) Down(ctx context.Context, db *mongo.Database) error {
-	err := db.Collection("sample_collection").Drop(ctx)
+	collection := db.Collection("sample_collection")
+
+	deleted, err := migration.DeleteInBatches(ctx, collection, bson.M{"message": sampleMessage}, 1000)
 	if err != nil {
-		return fmt.Errorf("failed to drop collection: %w", err)
+		return fmt.Errorf("failed to delete sample documents: %w", err)
+	}
+
+	if err := collection.Indexes().DropOne(ctx, "idx_sample_created_at"); err != nil {
+		return fmt.Errorf("failed to drop index: %w", err)
 	}
 
-	fmt.Println("Dropped sample_collection")
+	fmt.Printf("Deleted %d sample document(s) and dropped idx_sample_created_at\n", deleted)
 	return nil
 }
 
@@ -84,9 +94,12 @@ func main() {
 		}
 	}()
 
-	migration.MustRegister(&ExampleMigration{})
-
-	engine := migration.NewEngine(db, cfg.MigrationsCollection, migration.RegisteredMigrations())
+	engine, err := migration.NewEngineFromSlice(db, cfg.MigrationsCollection, []migration.Migration{
+		&ExampleMigration{},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	if err := runExampleFlow(ctx, engine); err != nil {
 		log.Fatal(err)
@@ -119,7 +132,7 @@ func connectToMongoDB(ctx context.Context, cfg *config.Config) (*mongo.Client, *
 	defer cancel()
 
 	fmt.Printf("Connecting to: %s/%s\n", cfg.MongoURL, cfg.Database)
-	client, err := mongo.Connect(options.Client().ApplyURI(cfg.GetConnectionString()))
+	client, err := mongo.Connect(dbconn.ClientOptions(cfg))
 	if err != nil {
 		return nil, nil, fmt.Errorf("connection failed: %w", err)
 	}
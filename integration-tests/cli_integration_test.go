@@ -75,6 +75,14 @@ func TestCLICommands(t *testing.T) {
 				assert.Contains(t, output, "idx_users_email_unique")
 			},
 		},
+		{
+			name: "Schema indexes filtered by collection",
+			args: []string{"schema", "--collection", "drew", "indexes"},
+			assert: func(t *testing.T, _ *TestEnv, output string) {
+				assert.Contains(t, output, "idx_address_created_at")
+				assert.NotContains(t, output, "idx_users_email_unique")
+			},
+		},
 		{
 			name: "MCP config command",
 			args: []string{"mcp", "config"},
@@ -83,6 +91,31 @@ func TestCLICommands(t *testing.T) {
 				assert.Contains(t, output, "\"mt\"")
 			},
 		},
+		{
+			name: "Create database on startup",
+			args: []string{"--create-db", "status"},
+			assert: func(t *testing.T, env *TestEnv, _ string) {
+				names, err := env.MongoClient.Database(env.DBName).ListCollectionNames(ctx, bson.M{"name": env.ColName})
+				require.NoError(t, err)
+				assert.Contains(t, names, env.ColName)
+			},
+		},
+		{
+			name: "Database override targets another database",
+			args: []string{"--database", env.DBName + "_override", "up"},
+			assert: func(t *testing.T, env *TestEnv, _ string) {
+				other := env.MongoClient.Database(env.DBName + "_override")
+				n, err := other.Collection(env.ColName).CountDocuments(ctx, bson.M{"version": latest})
+				require.NoError(t, err)
+				assert.Equal(t, int64(1), n)
+				assertLockReleasedIn(t, other)
+
+				n, err = env.MongoClient.Database(env.DBName).Collection(env.ColName).
+					CountDocuments(ctx, bson.M{})
+				require.NoError(t, err)
+				assert.Zero(t, n, "default database must be untouched")
+			},
+		},
 		{
 			name: "Initial status is pending",
 			args: []string{"status"},
@@ -94,10 +127,17 @@ func TestCLICommands(t *testing.T) {
 			name: "Migrate up to latest",
 			args: []string{"up"},
 			assert: func(t *testing.T, _ *TestEnv, output string) {
-				assert.Contains(t, output, "Database is")
+				assert.Contains(t, output, "Applied")
 				assertMigrationRecordExists(t, env, latest)
 			},
 		},
+		{
+			name: "Resume with nothing pending",
+			args: []string{"resume"},
+			assert: func(t *testing.T, _ *TestEnv, output string) {
+				assert.Contains(t, output, "Nothing to resume")
+			},
+		},
 		{
 			name: "Users indexes exist after migrations",
 			args: []string{"status"},
@@ -190,7 +230,14 @@ func TestCLICommands(t *testing.T) {
 }
 
 func setupIntegrationEnv(t *testing.T, ctx context.Context) *TestEnv {
-	container, err := mongodb.RunContainer(ctx, testcontainers.WithImage("mongo:8.0"))
+	return setupIntegrationEnvWith(t, ctx)
+}
+
+// setupIntegrationEnvWith is setupIntegrationEnv with extra container options, e.g.
+// mongodb.WithReplicaSet for tests that need one.
+func setupIntegrationEnvWith(t *testing.T, ctx context.Context, opts ...testcontainers.ContainerCustomizer) *TestEnv {
+	opts = append([]testcontainers.ContainerCustomizer{testcontainers.WithImage("mongo:8.0")}, opts...)
+	container, err := mongodb.RunContainer(ctx, opts...)
 	require.NoError(t, err)
 	t.Cleanup(func() { container.Terminate(context.Background()) })
 
@@ -284,7 +331,12 @@ func assertMigrationRecordExists(t *testing.T, env *TestEnv, version string) {
 
 func assertLockReleased(t *testing.T, env *TestEnv) {
 	t.Helper()
-	coll := env.MongoClient.Database(env.DBName).Collection("migrations_lock")
+	assertLockReleasedIn(t, env.MongoClient.Database(env.DBName))
+}
+
+func assertLockReleasedIn(t *testing.T, db *mongo.Database) {
+	t.Helper()
+	coll := db.Collection("migrations_lock")
 	ctx := context.Background()
 	count, err := coll.CountDocuments(ctx, bson.M{"lock_id": "migration_engine_lock"})
 	require.NoError(t, err)
@@ -336,3 +388,66 @@ func sortedMigrationVersions() []string {
 	sort.Strings(v)
 	return v
 }
+
+func TestStatusStrictChecksum(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	env.RunCLI(t, "up")
+
+	versions := sortedMigrationVersions()
+	require.NotEmpty(t, versions)
+	env.RunCLI(t, "status", "--strict-checksum")
+
+	tampered := versions[0]
+	_, err := env.MongoClient.Database(env.DBName).Collection(env.ColName).UpdateOne(ctx,
+		bson.M{"version": tampered}, bson.M{"$set": bson.M{"checksum": "tampered"}})
+	require.NoError(t, err)
+
+	oldArgs := os.Args
+	os.Args = []string{"mongo-tool", "--config", env.ConfigPath, "status", "--strict-checksum"}
+	defer func() { os.Args = oldArgs }()
+
+	stdout, _, err := captureOutput(cli.Execute)
+	require.ErrorIs(t, err, cli.ErrChecksumDrift)
+	assert.Contains(t, err.Error(), tampered)
+	assertVersionState(t, stdout, tampered, "[✓]")
+}
+
+func TestUpReportsDriftWithNothingPending(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	env.RunCLI(t, "up")
+	coll := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+
+	runUp := func(args ...string) error {
+		oldArgs := os.Args
+		os.Args = append([]string{"mongo-tool", "--config", env.ConfigPath}, args...)
+		defer func() { os.Args = oldArgs }()
+		_, _, err := captureOutput(cli.Execute)
+		return err
+	}
+
+	t.Run("Checksum drift", func(t *testing.T) {
+		tampered := sortedMigrationVersions()[0]
+		var rec migration.MigrationRecord
+		require.NoError(t, coll.FindOne(ctx, bson.M{"version": tampered}).Decode(&rec))
+		_, err := coll.UpdateOne(ctx, bson.M{"version": tampered}, bson.M{"$set": bson.M{"checksum": "tampered"}})
+		require.NoError(t, err)
+		defer func() {
+			_, err := coll.UpdateOne(ctx, bson.M{"version": tampered}, bson.M{"$set": bson.M{"checksum": rec.Checksum}})
+			require.NoError(t, err)
+		}()
+
+		err = runUp("up")
+		require.ErrorIs(t, err, migration.ErrChecksumMismatch)
+		assert.Contains(t, err.Error(), tampered)
+	})
+
+	t.Run("Orphaned records", func(t *testing.T) {
+		_, err := coll.InsertOne(ctx, migration.MigrationRecord{Version: "19990101_001", Description: "gone"})
+		require.NoError(t, err)
+
+		require.ErrorIs(t, runUp("up", "--fail-on-orphaned"), migration.ErrOrphanedRecords)
+		env.RunCLI(t, "up")
+	})
+}
@@ -0,0 +1,1485 @@
+//go:build integration
+
+package integration_tests_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+type countingMigration struct {
+	version string
+	ups     int
+	downs   int
+}
+
+func (m *countingMigration) Version() string     { return m.version }
+func (m *countingMigration) Description() string { return "counting migration " + m.version }
+
+func (m *countingMigration) Up(_ context.Context, _ *mongo.Database) error {
+	m.ups++
+	return nil
+}
+
+func (m *countingMigration) Down(_ context.Context, _ *mongo.Database) error {
+	m.downs++
+	return nil
+}
+
+func newTestEngine(
+	t *testing.T, env *TestEnv, opts []migration.EngineOption, ms ...migration.Migration,
+) *migration.Engine {
+	t.Helper()
+	set := make(map[string]migration.Migration, len(ms))
+	for _, m := range ms {
+		set[m.Version()] = m
+	}
+	return migration.NewEngine(env.MongoClient.Database(env.DBName), env.ColName, set, opts...)
+}
+
+func countRecords(t *testing.T, env *TestEnv, version string) int64 {
+	t.Helper()
+	coll := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+	n, err := coll.CountDocuments(context.Background(), bson.M{"version": version})
+	require.NoError(t, err)
+	return n
+}
+
+func TestEngineRunOne(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	m := &countingMigration{version: "20240101_001"}
+	engine := newTestEngine(t, env, []migration.EngineOption{migration.WithAllowRunOne(true)}, m)
+
+	require.NoError(t, engine.Up(ctx, ""))
+	require.Equal(t, 1, m.ups)
+
+	t.Run("Up re-runs an applied migration", func(t *testing.T) {
+		require.NoError(t, engine.RunOne(ctx, m.version, migration.DirectionUp))
+		assert.Equal(t, 2, m.ups)
+		assert.Equal(t, int64(1), countRecords(t, env, m.version))
+	})
+
+	t.Run("Down removes the record", func(t *testing.T) {
+		require.NoError(t, engine.RunOne(ctx, m.version, migration.DirectionDown))
+		assert.Equal(t, 1, m.downs)
+		assert.Zero(t, countRecords(t, env, m.version))
+	})
+
+	t.Run("Up records a pending migration", func(t *testing.T) {
+		require.NoError(t, engine.RunOne(ctx, m.version, migration.DirectionUp))
+		assert.Equal(t, 3, m.ups)
+		assert.Equal(t, int64(1), countRecords(t, env, m.version))
+	})
+}
+
+func TestEngineForceBatch(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	first := &countingMigration{version: "20240101_001"}
+	second := &countingMigration{version: "20240102_001"}
+	engine := newTestEngine(t, env, nil, first, second)
+
+	results, err := engine.ForceBatch(ctx, []string{first.version, "20991231_999", second.version})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.NoError(t, results[0].Err)
+	assert.Error(t, results[1].Err)
+	assert.NoError(t, results[2].Err)
+
+	assert.Equal(t, int64(1), countRecords(t, env, first.version))
+	assert.Equal(t, int64(1), countRecords(t, env, second.version))
+	assert.Zero(t, first.ups+second.ups, "force must not execute migrations")
+	assertLockReleased(t, env)
+}
+
+func TestEngineForceWithResult(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	m := &countingMigration{version: "20240101_001"}
+	engine := newTestEngine(t, env, nil, m)
+
+	t.Run("New record", func(t *testing.T) {
+		res, err := engine.ForceWithResult(ctx, m.version)
+		require.NoError(t, err)
+		assert.False(t, res.AlreadyApplied)
+		assert.True(t, res.PreviousAppliedAt.IsZero())
+		assert.Equal(t, int64(1), countRecords(t, env, m.version))
+	})
+
+	t.Run("Existing record is reported and kept", func(t *testing.T) {
+		var before migration.MigrationRecord
+		coll := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+		require.NoError(t, coll.FindOne(ctx, bson.M{"version": m.version}).Decode(&before))
+
+		res, err := engine.ForceWithResult(ctx, m.version)
+		require.NoError(t, err)
+		assert.True(t, res.AlreadyApplied)
+		assert.True(t, before.AppliedAt.Equal(res.PreviousAppliedAt))
+		assert.Equal(t, int64(1), countRecords(t, env, m.version))
+	})
+
+	t.Run("Held lock refuses force", func(t *testing.T) {
+		other := &countingMigration{version: "20240102_001"}
+		locks := env.MongoClient.Database(env.DBName).Collection("migrations_lock")
+		_, err := locks.InsertOne(ctx, bson.M{
+			"lock_id": "migration_engine_lock", "fence": int64(1), "acquired_at": time.Now().UTC(),
+		})
+		require.NoError(t, err)
+
+		_, err = newTestEngine(t, env, nil, m, other).ForceWithResult(ctx, other.version)
+		require.ErrorIs(t, err, migration.ErrLockHeld)
+		assert.Zero(t, countRecords(t, env, other.version))
+		_, err = locks.DeleteMany(ctx, bson.M{"lock_id": "migration_engine_lock"})
+		require.NoError(t, err)
+	})
+
+	assert.Zero(t, m.ups, "force must not execute migrations")
+	assertLockReleased(t, env)
+}
+
+func TestEngineEnsureMigrationsCollection(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	engine := newTestEngine(t, env, nil)
+
+	created, err := engine.EnsureMigrationsCollection(ctx)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	names, err := env.MongoClient.ListDatabaseNames(ctx, bson.M{"name": env.DBName})
+	require.NoError(t, err)
+	assert.Contains(t, names, env.DBName)
+
+	collections, err := env.MongoClient.Database(env.DBName).ListCollectionNames(ctx, bson.M{"name": env.ColName})
+	require.NoError(t, err)
+	assert.Contains(t, collections, env.ColName)
+
+	created, err = engine.EnsureMigrationsCollection(ctx)
+	require.NoError(t, err)
+	assert.False(t, created)
+}
+
+type seedMigration struct {
+	version string
+}
+
+func (m *seedMigration) Version() string     { return m.version }
+func (m *seedMigration) Description() string { return "seed causal docs" }
+
+func (m *seedMigration) Up(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("causal_docs").InsertOne(ctx, bson.M{"seeded": true})
+	return err
+}
+
+func (m *seedMigration) Down(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("causal_docs").Drop(ctx)
+}
+
+type readBackMigration struct {
+	version string
+	seen    int64
+}
+
+func (m *readBackMigration) Version() string     { return m.version }
+func (m *readBackMigration) Description() string { return "read causal docs" }
+
+func (m *readBackMigration) Up(ctx context.Context, db *mongo.Database) error {
+	n, err := db.Collection("causal_docs").CountDocuments(ctx, bson.M{"seeded": true})
+	m.seen = n
+	return err
+}
+
+func (m *readBackMigration) Down(_ context.Context, _ *mongo.Database) error { return nil }
+
+func TestEngineCausalConsistencyReadYourWrites(t *testing.T) {
+	ctx := context.Background()
+	env := setupReplicaSetEnv(t, ctx)
+
+	seed := &seedMigration{version: "20240101_001"}
+	read := &readBackMigration{version: "20240102_001"}
+	engine := newTestEngine(t, env, []migration.EngineOption{migration.WithCausalConsistency(true)}, seed, read)
+
+	require.NoError(t, engine.Up(ctx, ""))
+	assert.Equal(t, int64(1), read.seen, "second migration should observe the first migration's write")
+}
+
+func setupReplicaSetEnv(t *testing.T, ctx context.Context) *TestEnv {
+	t.Helper()
+	container, err := mongodb.Run(ctx, "mongo:8.0", mongodb.WithReplicaSet("rs0"))
+	require.NoError(t, err)
+	t.Cleanup(func() { container.Terminate(context.Background()) })
+
+	connStr, err := container.ConnectionString(ctx)
+	require.NoError(t, err)
+
+	client, err := mongo.Connect(options.Client().ApplyURI(connStr))
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return &TestEnv{
+		DBName:      fmt.Sprintf("it_rs_%d", time.Now().UnixNano()),
+		ColName:     "schema_migrations",
+		MongoClient: client,
+	}
+}
+
+func TestEngineExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	first := &countingMigration{version: "20240101_001"}
+	second := &countingMigration{version: "20240102_001"}
+	source := newTestEngine(t, env, nil, first, second)
+	require.NoError(t, source.Up(ctx, ""))
+
+	exported, err := source.ListApplied(ctx)
+	require.NoError(t, err)
+	require.Len(t, exported, 2)
+
+	data, err := jsonutil.Marshal(exported)
+	require.NoError(t, err)
+	var decoded []migration.MigrationRecord
+	require.NoError(t, jsonutil.Unmarshal(data, &decoded))
+
+	restoreEnv := *env
+	restoreEnv.ColName = "schema_migrations_restore"
+	target := newTestEngine(t, &restoreEnv, nil, first, second)
+
+	result, err := target.ImportRecords(ctx, decoded)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{first.version, second.version}, result.Imported)
+	assert.Empty(t, result.Conflicts)
+
+	restored, err := target.ListApplied(ctx)
+	require.NoError(t, err)
+	require.Len(t, restored, 2)
+	for i := range exported {
+		assert.Equal(t, exported[i].Version, restored[i].Version)
+		assert.Equal(t, exported[i].Checksum, restored[i].Checksum)
+		assert.True(t, exported[i].AppliedAt.Equal(restored[i].AppliedAt))
+	}
+
+	t.Run("Checksum conflicts are skipped", func(t *testing.T) {
+		tampered := decoded[0]
+		tampered.Checksum = "deadbeef"
+		result, err := target.ImportRecords(ctx, []migration.MigrationRecord{tampered})
+		require.NoError(t, err)
+		assert.Empty(t, result.Imported)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, tampered.Version, result.Conflicts[0].Version)
+	})
+}
+
+type parallelMigration struct {
+	version string
+	running *atomic.Int32
+	peak    *atomic.Int32
+}
+
+func (m *parallelMigration) Version() string     { return m.version }
+func (m *parallelMigration) Description() string { return "parallel migration " + m.version }
+func (m *parallelMigration) Independent() bool   { return true }
+
+func (m *parallelMigration) Up(_ context.Context, _ *mongo.Database) error {
+	n := m.running.Add(1)
+	defer m.running.Add(-1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
+
+func (m *parallelMigration) Down(_ context.Context, _ *mongo.Database) error { return nil }
+
+func TestEngineParallelIndependentMigrations(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	var running, peak atomic.Int32
+	var ms []migration.Migration
+	for i := 1; i <= 4; i++ {
+		ms = append(ms, &parallelMigration{version: fmt.Sprintf("20240101_00%d", i), running: &running, peak: &peak})
+	}
+
+	var progress bytes.Buffer
+	engine := newTestEngine(t, env, []migration.EngineOption{
+		migration.WithMaxParallel(4),
+		migration.WithProgress(&progress),
+	}, ms...)
+	require.NoError(t, engine.Up(ctx, ""))
+
+	for _, m := range ms {
+		assert.Equal(t, int64(1), countRecords(t, env, m.Version()), "record for %s", m.Version())
+	}
+	assert.Greater(t, peak.Load(), int32(1), "expected migrations to overlap")
+	assert.Equal(t, 8, strings.Count(progress.String(), "\n"))
+}
+
+// lockWatchMigration records the lock heartbeat before and after a slow Up, optionally
+// force-unlocking in between to simulate the lock being reclaimed by another process.
+type lockWatchMigration struct {
+	version   string
+	steal     func(ctx context.Context) error
+	before    time.Time
+	after     time.Time
+	stillOpen bool
+}
+
+func (m *lockWatchMigration) Version() string     { return m.version }
+func (m *lockWatchMigration) Description() string { return "watch lock heartbeat" }
+
+func (m *lockWatchMigration) Up(ctx context.Context, db *mongo.Database) error {
+	var err error
+	if m.before, err = readLockHeartbeat(ctx, db); err != nil {
+		return err
+	}
+	if m.steal != nil {
+		if err := m.steal(ctx); err != nil {
+			return err
+		}
+	}
+	select {
+	case <-time.After(time.Second):
+		m.stillOpen = true
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	m.after, err = readLockHeartbeat(ctx, db)
+	return err
+}
+
+func (m *lockWatchMigration) Down(_ context.Context, _ *mongo.Database) error { return nil }
+
+func readLockHeartbeat(ctx context.Context, db *mongo.Database) (time.Time, error) {
+	var doc struct {
+		Heartbeat time.Time `bson:"heartbeat"`
+	}
+	err := db.Collection("migrations_lock").FindOne(ctx, bson.M{"lock_id": "migration_engine_lock"}).Decode(&doc)
+	return doc.Heartbeat, err
+}
+
+func TestEngineLockHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	t.Run("Heartbeat renews the lock during a slow migration", func(t *testing.T) {
+		m := &lockWatchMigration{version: "20240101_001"}
+		engine := newTestEngine(t, env, []migration.EngineOption{migration.WithLockHeartbeat(100 * time.Millisecond)}, m)
+
+		require.NoError(t, engine.Up(ctx, ""))
+		assert.True(t, m.after.After(m.before), "heartbeat %s should advance past %s", m.after, m.before)
+		assertLockReleased(t, env)
+	})
+
+	t.Run("Run aborts when the lock is reclaimed", func(t *testing.T) {
+		m := &lockWatchMigration{version: "20240102_001"}
+		engine := newTestEngine(t, env, []migration.EngineOption{migration.WithLockHeartbeat(100 * time.Millisecond)}, m)
+		m.steal = func(context.Context) error {
+			// Write outside the migration's session, as another process would.
+			ctx := context.Background()
+			if err := engine.ForceUnlock(ctx); err != nil {
+				return err
+			}
+			_, err := env.MongoClient.Database(env.DBName).Collection("migrations_lock").
+				InsertOne(ctx, bson.M{"lock_id": "migration_engine_lock", "acquired_at": time.Now().UTC()})
+			return err
+		}
+
+		err := engine.Up(ctx, "")
+		require.ErrorIs(t, err, migration.ErrLockLost)
+		assert.False(t, m.stillOpen)
+		assert.Zero(t, countRecords(t, env, m.version))
+
+		count, err := env.MongoClient.Database(env.DBName).Collection("migrations_lock").
+			CountDocuments(ctx, bson.M{"lock_id": "migration_engine_lock"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count, "the other holder's lock must not be released")
+	})
+}
+
+type reclaimMigration struct {
+	version string
+	reclaim func(ctx context.Context) error
+}
+
+func (m *reclaimMigration) Version() string     { return m.version }
+func (m *reclaimMigration) Description() string { return "lock reclaimed mid-run" }
+
+func (m *reclaimMigration) Up(ctx context.Context, _ *mongo.Database) error { return m.reclaim(ctx) }
+
+func (m *reclaimMigration) Down(_ context.Context, _ *mongo.Database) error { return nil }
+
+func TestEngineFencingTokenAbortsStaleHolder(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	locks := env.MongoClient.Database(env.DBName).Collection("migrations_lock")
+
+	m := &reclaimMigration{version: "20240101_001"}
+	stale := newTestEngine(t, env, []migration.EngineOption{migration.WithLockHeartbeat(0)}, m)
+	m.reclaim = func(context.Context) error {
+		// Another process finds the lock expired, clears it and takes it with a newer token.
+		ctx := context.Background()
+		if _, err := locks.DeleteMany(ctx, bson.M{"lock_id": "migration_engine_lock"}); err != nil {
+			return err
+		}
+		_, err := locks.InsertOne(ctx, bson.M{
+			"lock_id":     "migration_engine_lock",
+			"fence":       int64(1 << 40),
+			"acquired_at": time.Now().UTC(),
+		})
+		return err
+	}
+
+	err := stale.Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrLockLost)
+	assert.Zero(t, countRecords(t, env, m.version), "stale holder must not write its record")
+
+	count, err := locks.CountDocuments(ctx, bson.M{"lock_id": "migration_engine_lock", "fence": int64(1 << 40)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count, "the new holder's lock must survive the stale release")
+
+	t.Run("Tokens increase on every acquisition", func(t *testing.T) {
+		require.NoError(t, stale.ForceUnlock(ctx))
+		engine := newTestEngine(t, env, nil)
+		var fences []int64
+		for range 2 {
+			require.NoError(t, engine.Up(ctx, ""))
+			var counter struct {
+				Fence int64 `bson:"fence"`
+			}
+			require.NoError(t, locks.FindOne(ctx, bson.M{"lock_id": "migration_engine_lock_fence"}).Decode(&counter))
+			fences = append(fences, counter.Fence)
+		}
+		assert.Greater(t, fences[1], fences[0])
+	})
+}
+
+type describedMigration struct {
+	countingMigration
+	description string
+}
+
+func (m *describedMigration) Description() string { return m.description }
+
+func TestEngineDescriptionChange(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	records := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+
+	applied := &describedMigration{countingMigration{version: "20240101_001"}, "add users"}
+	require.NoError(t, newTestEngine(t, env, nil, applied).Up(ctx, ""))
+
+	renamed := &describedMigration{countingMigration{version: "20240101_001"}, "add users collection"}
+	pending := &countingMigration{version: "20240102_001"}
+
+	err := newTestEngine(t, env, nil, renamed, pending).Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrChecksumMismatch)
+	require.ErrorIs(t, err, migration.ErrDescriptionChanged)
+	assert.Zero(t, pending.ups, "nothing may run while a checksum mismatches")
+
+	repair := []migration.EngineOption{migration.WithAutoRepairDescriptions(true)}
+	repairing := newTestEngine(t, env, repair, renamed, pending)
+	require.NoError(t, repairing.Up(ctx, ""))
+	assert.Equal(t, 1, pending.ups)
+
+	var rec migration.MigrationRecord
+	require.NoError(t, records.FindOne(ctx, bson.M{"version": renamed.version}).Decode(&rec))
+	assert.Equal(t, "add users collection", rec.Description)
+	assert.Zero(t, renamed.ups, "the repaired migration must not run again")
+
+	t.Run("Behavioral changes are not repaired", func(t *testing.T) {
+		_, err := records.UpdateOne(ctx, bson.M{"version": renamed.version}, bson.M{"$set": bson.M{"checksum": "deadbeef"}})
+		require.NoError(t, err)
+
+		err = repairing.Up(ctx, "")
+		require.ErrorIs(t, err, migration.ErrChecksumMismatch)
+		assert.NotErrorIs(t, err, migration.ErrDescriptionChanged)
+	})
+}
+
+func TestEngineReadOnlyStatus(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	db := env.MongoClient.Database(env.DBName)
+
+	m := &countingMigration{version: "20240101_001"}
+	engine := newTestEngine(t, env, []migration.EngineOption{migration.WithReadOnly(true)}, m)
+
+	status, err := engine.GetStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, 1)
+	assert.False(t, status[0].Applied)
+
+	_, err = engine.Plan(ctx, migration.DirectionUp, "")
+	require.NoError(t, err)
+
+	require.ErrorIs(t, engine.Up(ctx, ""), migration.ErrReadOnly)
+	assert.Zero(t, m.ups)
+
+	names, err := db.ListCollectionNames(ctx, bson.M{})
+	require.NoError(t, err)
+	assert.NotContains(t, names, "migrations_lock", "read-only status must not create the lock or its indexes")
+	assert.NotContains(t, names, env.ColName)
+}
+
+// rfc3339Registry stores time.Time values as RFC 3339 strings instead of BSON dates.
+func rfc3339Registry() *bson.Registry {
+	reg := bson.NewRegistry()
+	timeType := reflect.TypeOf(time.Time{})
+	reg.RegisterTypeEncoder(timeType, bson.ValueEncoderFunc(
+		func(_ bson.EncodeContext, vw bson.ValueWriter, val reflect.Value) error {
+			return vw.WriteString(val.Interface().(time.Time).UTC().Format(time.RFC3339Nano))
+		}))
+	reg.RegisterTypeDecoder(timeType, bson.ValueDecoderFunc(
+		func(_ bson.DecodeContext, vr bson.ValueReader, val reflect.Value) error {
+			s, err := vr.ReadString()
+			if err != nil {
+				return err
+			}
+			parsed, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return err
+			}
+			val.Set(reflect.ValueOf(parsed))
+			return nil
+		}))
+	return reg
+}
+
+func TestEngineCustomRegistry(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	records := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+
+	m := &countingMigration{version: "20240101_001"}
+	engine := newTestEngine(t, env, []migration.EngineOption{migration.WithRegistry(rfc3339Registry())}, m)
+	require.NoError(t, engine.Up(ctx, ""))
+
+	raw, err := records.FindOne(ctx, bson.M{"version": m.version}).Raw()
+	require.NoError(t, err)
+	assert.Equal(t, bson.TypeString, raw.Lookup("applied_at").Type, "applied_at must be encoded by the custom codec")
+
+	applied, err := engine.ListApplied(ctx)
+	require.NoError(t, err)
+	require.Len(t, applied, 1)
+	assert.Equal(t, m.version, applied[0].Version)
+	assert.WithinDuration(t, time.Now(), applied[0].AppliedAt, time.Minute)
+
+	status, err := engine.GetStatus(ctx)
+	require.NoError(t, err)
+	assert.True(t, status[0].Applied)
+}
+
+func TestEngineExpectedDatabase(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	m := &countingMigration{version: "20240101_001"}
+	wrong := newTestEngine(t, env, []migration.EngineOption{migration.WithExpectedDatabase("other_db")}, m)
+	err := wrong.Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrWrongDatabase)
+	assert.Contains(t, err.Error(), env.DBName)
+	assert.Zero(t, m.ups)
+	assertLockReleasedIn(t, env.MongoClient.Database(env.DBName))
+
+	matching := newTestEngine(t, env, []migration.EngineOption{migration.WithExpectedDatabase(env.DBName)}, m)
+	require.NoError(t, matching.Up(ctx, ""))
+	assert.Equal(t, 1, m.ups)
+}
+
+type annotatedMigration struct {
+	countingMigration
+}
+
+func (m *annotatedMigration) Metadata() map[string]string {
+	return map[string]string{"author": "jane", "ticket": "OPS-42"}
+}
+
+func TestEngineStoresMigrationMetadata(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	annotated := &annotatedMigration{countingMigration{version: "20240101_001"}}
+	plain := &countingMigration{version: "20240102_001"}
+	engine := newTestEngine(t, env, nil, annotated, plain)
+	require.NoError(t, engine.Up(ctx, ""))
+
+	applied, err := engine.ListApplied(ctx)
+	require.NoError(t, err)
+	byVersion := map[string]migration.MigrationRecord{}
+	for _, rec := range applied {
+		byVersion[rec.Version] = rec
+	}
+	assert.Equal(t, annotated.Metadata(), byVersion[annotated.version].Metadata)
+	assert.Nil(t, byVersion[plain.version].Metadata)
+
+	raw, err := env.MongoClient.Database(env.DBName).Collection(env.ColName).
+		FindOne(ctx, bson.M{"version": plain.version}).Raw()
+	require.NoError(t, err)
+	_, err = raw.LookupErr("metadata")
+	assert.Error(t, err, "records without metadata must not store the field")
+}
+
+type failingMigration struct {
+	countingMigration
+	err error
+}
+
+func (m *failingMigration) Up(_ context.Context, _ *mongo.Database) error { return m.err }
+
+func TestEngineAuditCollection(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	audit := env.MongoClient.Database(env.DBName).Collection("migrations_audit")
+
+	ok := &countingMigration{version: "20240101_001"}
+	bad := &failingMigration{countingMigration{version: "20240102_001"}, fmt.Errorf("index build failed")}
+	engine := newTestEngine(t, env, []migration.EngineOption{migration.WithAuditCollection("migrations_audit")}, ok, bad)
+
+	require.Error(t, engine.Up(ctx, ""))
+
+	cur, err := audit.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}}))
+	require.NoError(t, err)
+	var entries []migration.AuditEntry
+	require.NoError(t, cur.All(ctx, &entries))
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, ok.version, entries[0].Version)
+	assert.Equal(t, "up", entries[0].Direction)
+	assert.Equal(t, migration.AuditSuccess, entries[0].Outcome)
+	assert.Empty(t, entries[0].Error)
+
+	assert.Equal(t, bad.version, entries[1].Version)
+	assert.Equal(t, migration.AuditFailure, entries[1].Outcome)
+	assert.Contains(t, entries[1].Error, "index build failed")
+	assert.GreaterOrEqual(t, entries[1].DurationMS, int64(0))
+
+	require.NoError(t, engine.Down(ctx, ""))
+	n, err := audit.CountDocuments(ctx, bson.M{"direction": "down", "outcome": migration.AuditSuccess})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n, "the rollback must be audited as well")
+}
+
+type scopedMigration struct {
+	countingMigration
+	envs []string
+}
+
+func (m *scopedMigration) Environments() []string { return m.envs }
+
+func TestEngineEnvironmentScopedMigrations(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	audit := env.MongoClient.Database(env.DBName).Collection("migrations_audit")
+
+	schema := &countingMigration{version: "20240101_001"}
+	seed := &scopedMigration{countingMigration{version: "20240102_001"}, []string{"dev", "test"}}
+
+	prod := newTestEngine(t, env, []migration.EngineOption{
+		migration.WithEnvironment("production"), migration.WithAuditCollection("migrations_audit"),
+	}, schema, seed)
+	require.NoError(t, prod.Up(ctx, ""))
+	assert.Equal(t, 1, schema.ups)
+	assert.Equal(t, 0, seed.ups, "seed data must not run in production")
+	assert.Equal(t, int64(0), countRecords(t, env, seed.version), "a skipped migration stays pending")
+
+	var skipped migration.AuditEntry
+	require.NoError(t, audit.FindOne(ctx, bson.M{"version": seed.version}).Decode(&skipped))
+	assert.Equal(t, migration.AuditSkipped, skipped.Outcome)
+	assert.Contains(t, skipped.Reason, "production")
+
+	dev := newTestEngine(t, env, []migration.EngineOption{migration.WithEnvironment("dev")}, schema, seed)
+	require.NoError(t, dev.Up(ctx, ""))
+	assert.Equal(t, 1, schema.ups, "already applied")
+	assert.Equal(t, 1, seed.ups)
+	assert.Equal(t, int64(1), countRecords(t, env, seed.version))
+}
+
+func TestEngineSelectedVersions(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	ms := []*countingMigration{
+		{version: "20240101_001"}, {version: "20240102_001"}, {version: "20240103_001"}, {version: "20240104_001"},
+	}
+	engine := newTestEngine(t, env, nil, ms[0], ms[1], ms[2], ms[3])
+
+	require.NoError(t, engine.UpSelected(ctx, []string{ms[0].version, ms[2].version}))
+	assert.Equal(t, []int{1, 0, 1, 0}, []int{ms[0].ups, ms[1].ups, ms[2].ups, ms[3].ups})
+	assert.Zero(t, countRecords(t, env, ms[1].version))
+
+	require.NoError(t, engine.Up(ctx, ""))
+	require.NoError(t, engine.DownSelected(ctx, []string{ms[1].version, ms[3].version}))
+	assert.Equal(t, []int{0, 1, 0, 1}, []int{ms[0].downs, ms[1].downs, ms[2].downs, ms[3].downs})
+	assert.Equal(t, int64(1), countRecords(t, env, ms[2].version), "unselected migrations stay applied")
+
+	require.ErrorIs(t, engine.UpSelected(ctx, []string{"20991231_001"}), migration.ErrMigrationNotFound)
+}
+
+type droppingMigration struct {
+	countingMigration
+	collection string
+}
+
+func (m *droppingMigration) UpSafe(ctx context.Context, db *migration.SafeDatabase) error {
+	return db.Collection(m.collection).Drop(ctx)
+}
+
+func TestEngineForbidDrops(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	db := env.MongoClient.Database(env.DBName)
+	require.NoError(t, db.CreateCollection(ctx, "legacy_users"))
+
+	m := &droppingMigration{countingMigration{version: "20240101_001"}, "legacy_users"}
+	strict := newTestEngine(t, env, []migration.EngineOption{migration.WithForbidDrops(true)}, m)
+
+	err := strict.Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrForbiddenOperation)
+	assert.Zero(t, countRecords(t, env, m.version))
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": "legacy_users"})
+	require.NoError(t, err)
+	assert.Len(t, names, 1, "the collection must survive")
+
+	plain := &countingMigration{version: "20240102_001"}
+	err = newTestEngine(t, env, []migration.EngineOption{migration.WithForbidDrops(true)}, plain).Up(ctx, "")
+	require.NoError(t, err, "migrations that do not drop run under the policy")
+	assert.Equal(t, 1, plain.ups)
+
+	require.NoError(t, newTestEngine(t, env, nil, m).Up(ctx, ""), "without the policy the drop is allowed")
+	names, err = db.ListCollectionNames(ctx, bson.M{"name": "legacy_users"})
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestEngineValidateDetectsPartialState(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	records := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+
+	first := &countingMigration{version: "20240101_001"}
+	second := &countingMigration{version: "20240102_001"}
+	engine := newTestEngine(t, env, nil, first, second)
+	require.NoError(t, engine.UpSelected(ctx, []string{first.version}))
+	require.NoError(t, engine.Validate(ctx))
+
+	// A crashed run left a record that does not match the code.
+	_, err := records.UpdateOne(ctx, bson.M{"version": first.version}, bson.M{"$set": bson.M{"checksum": "partial"}})
+	require.NoError(t, err)
+
+	err = engine.Validate(ctx)
+	require.ErrorIs(t, err, migration.ErrChecksumMismatch)
+	var migErr *migration.MigrationError
+	require.ErrorAs(t, err, &migErr)
+	assert.Equal(t, first.version, migErr.Version)
+
+	require.Error(t, engine.Up(ctx, ""), "up must not continue past a dirty record either")
+	assert.Zero(t, second.ups)
+
+	t.Run("Resumes after repair", func(t *testing.T) {
+		_, err := records.DeleteOne(ctx, bson.M{"version": first.version})
+		require.NoError(t, err)
+		require.NoError(t, engine.Validate(ctx))
+		require.NoError(t, engine.Up(ctx, ""))
+		assert.Equal(t, 1, second.ups)
+	})
+}
+
+type interruptingMigration struct {
+	countingMigration
+	interrupt func()
+}
+
+func (m *interruptingMigration) Up(ctx context.Context, db *mongo.Database) error {
+	m.interrupt()
+	return m.countingMigration.Up(ctx, db)
+}
+
+func TestEngineStopsOnCancelAndReleasesLock(t *testing.T) {
+	env := setupIntegrationEnv(t, context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := &countingMigration{version: "20240101_001"}
+	interrupted := &interruptingMigration{countingMigration: countingMigration{version: "20240102_001"}, interrupt: cancel}
+	last := &countingMigration{version: "20240103_001"}
+	engine := newTestEngine(t, env, nil, first, interrupted, last)
+
+	err := engine.Up(ctx, "")
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, first.ups)
+	assert.Equal(t, 1, interrupted.ups)
+	assert.Zero(t, last.ups, "no migration may start after the interrupt")
+	assert.EqualValues(t, 1, countRecords(t, env, first.version))
+	assertLockReleased(t, env)
+
+	require.NoError(t, engine.Up(context.Background(), ""), "a later run picks up where the interrupted one stopped")
+	assert.Equal(t, 1, last.ups)
+}
+
+func TestEngineClearsStaleLock(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	locks := env.MongoClient.Database(env.DBName).Collection("migrations_lock")
+
+	// A crashed job's lock: older than the threshold but still inside the 10 minute TTL.
+	_, err := locks.InsertOne(ctx, bson.M{
+		"lock_id":     "migration_engine_lock",
+		"fence":       int64(1),
+		"acquired_at": time.Now().UTC().Add(-5 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	m := &countingMigration{version: "20240101_001"}
+	require.ErrorIs(t, newTestEngine(t, env, nil, m).Up(ctx, ""), migration.ErrFailedToLock,
+		"without the option the stale lock blocks the run")
+
+	recent := newTestEngine(t, env, []migration.EngineOption{migration.WithStaleLockTimeout(time.Hour)}, m)
+	err = recent.Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrFailedToLock, "a lock younger than the threshold is kept")
+	require.ErrorIs(t, err, migration.ErrLockHeld)
+
+	engine := newTestEngine(t, env, []migration.EngineOption{migration.WithStaleLockTimeout(time.Minute)}, m)
+	require.NoError(t, engine.Up(ctx, ""))
+	assert.Equal(t, 1, m.ups)
+	assertLockReleased(t, env)
+}
+
+func TestForEachBatchVisitsEveryDocumentOnce(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	coll := env.MongoClient.Database(env.DBName).Collection("batch_docs")
+
+	docs := make([]any, 0, 25)
+	for i := range 25 {
+		docs = append(docs, bson.M{"_id": int32(i), "even": i%2 == 0})
+	}
+	_, err := coll.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	visited := make(map[int32]int)
+	var sizes []int
+	record := func(_ context.Context, batch []bson.M) error {
+		sizes = append(sizes, len(batch))
+		for _, doc := range batch {
+			visited[doc["_id"].(int32)]++
+		}
+		return nil
+	}
+
+	require.NoError(t, migration.ForEachBatch(ctx, coll, bson.D{}, 10, record))
+	assert.Equal(t, []int{10, 10, 5}, sizes)
+	require.Len(t, visited, 25)
+	for id, n := range visited {
+		assert.Equal(t, 1, n, "document %d visited %d times", id, n)
+	}
+
+	t.Run("Filter applies to every batch", func(t *testing.T) {
+		clear(visited)
+		sizes = nil
+		require.NoError(t, migration.ForEachBatch(ctx, coll, bson.M{"even": true}, 4, record))
+		assert.Len(t, visited, 13)
+		assert.Equal(t, []int{4, 4, 4, 1}, sizes)
+	})
+
+	t.Run("Resumes after a failed batch", func(t *testing.T) {
+		clear(visited)
+		calls := 0
+		failSecond := func(ctx context.Context, batch []bson.M) error {
+			if calls++; calls == 2 {
+				return errors.New("boom")
+			}
+			return record(ctx, batch)
+		}
+
+		err := migration.ForEachBatch(ctx, coll, bson.D{}, 10, failSecond)
+		var batchErr *migration.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.EqualValues(t, 9, batchErr.After)
+		assert.EqualValues(t, 10, batchErr.Processed)
+
+		resume := bson.M{"_id": bson.M{"$gt": batchErr.After}}
+		require.NoError(t, migration.ForEachBatch(ctx, coll, resume, 10, record))
+		require.Len(t, visited, 25)
+		for id, n := range visited {
+			assert.Equal(t, 1, n, "document %d visited %d times across the two runs", id, n)
+		}
+	})
+}
+
+// selfRecordingMigration writes its own migration record from Up, the way a retried
+// record insert finds the first attempt's write already on the server.
+type selfRecordingMigration struct {
+	version   string
+	coll      string
+	appliedAt time.Time
+}
+
+func (m *selfRecordingMigration) Version() string     { return m.version }
+func (m *selfRecordingMigration) Description() string { return "self-recording migration " + m.version }
+
+func (m *selfRecordingMigration) Up(ctx context.Context, db *mongo.Database) error {
+	appliedAt := m.appliedAt
+	if appliedAt.IsZero() {
+		appliedAt = time.Now().UTC()
+	}
+	_, err := db.Collection(m.coll).InsertOne(ctx, migration.MigrationRecord{
+		Version: m.version, Description: m.Description(), AppliedAt: appliedAt,
+	})
+	return err
+}
+
+func (m *selfRecordingMigration) Down(_ context.Context, _ *mongo.Database) error { return nil }
+
+func TestEngineRecordInsertIsRetrySafe(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	t.Run("Record written earlier in the run counts as success", func(t *testing.T) {
+		m := &selfRecordingMigration{version: "20240101_001", coll: env.ColName}
+		require.NoError(t, newTestEngine(t, env, nil, m).Up(ctx, ""))
+		assert.Equal(t, int64(1), countRecords(t, env, m.version))
+	})
+
+	t.Run("Record from before the run is not masked", func(t *testing.T) {
+		m := &selfRecordingMigration{version: "20240101_002", coll: env.ColName,
+			appliedAt: time.Now().UTC().Add(-time.Hour)}
+		err := newTestEngine(t, env, nil, m).Up(ctx, "")
+		require.ErrorIs(t, err, migration.ErrAlreadyApplied)
+	})
+
+	assertLockReleased(t, env)
+}
+
+func TestEnginePreflight(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	db := env.MongoClient.Database(env.DBName)
+
+	_, err := db.Collection("users").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName("email_1"),
+	})
+	require.NoError(t, err)
+
+	t.Run("Failing check applies nothing", func(t *testing.T) {
+		checks, err := migration.ParsePreflightChecks([]string{"collection:users", "collection:baseline"})
+		require.NoError(t, err)
+		m := &countingMigration{version: "20240101_001"}
+
+		err = newTestEngine(t, env, []migration.EngineOption{migration.WithPreflight(checks...)}, m).Up(ctx, "")
+		require.ErrorIs(t, err, migration.ErrPreflightFailed)
+		assert.Contains(t, err.Error(), "baseline")
+		assert.Zero(t, m.ups)
+		assert.Zero(t, countRecords(t, env, m.version))
+	})
+
+	t.Run("Passing checks let the run proceed", func(t *testing.T) {
+		checks, err := migration.ParsePreflightChecks([]string{"collection:users", "index:users.email_1"})
+		require.NoError(t, err)
+		m := &countingMigration{version: "20240101_001"}
+
+		require.NoError(t, newTestEngine(t, env, []migration.EngineOption{migration.WithPreflight(checks...)}, m).Up(ctx, ""))
+		assert.Equal(t, 1, m.ups)
+	})
+
+	assertLockReleased(t, env)
+}
+
+func TestCountCheckMigration(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	users := env.MongoClient.Database(env.DBName).Collection("users")
+	_, err := users.InsertMany(ctx, []any{
+		bson.M{"name": "Ada", "email": "ada@example.com"},
+		bson.M{"name": "Bob", "email": nil},
+	})
+	require.NoError(t, err)
+
+	passing := migration.NewCountCheck("20240101_001", "users", bson.M{"name": "Ada"}, false)
+	require.NoError(t, newTestEngine(t, env, nil, passing).Up(ctx, ""))
+	assert.Equal(t, int64(1), countRecords(t, env, passing.Version()))
+
+	failing := migration.NewCountCheck("20240102_001", "users", bson.M{"email": nil}, true)
+	err = newTestEngine(t, env, nil, passing, failing).Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrCheckFailed)
+	assert.Zero(t, countRecords(t, env, failing.Version()))
+
+	assertLockReleased(t, env)
+}
+
+func TestEngineFailOnOrphaned(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	records := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+
+	_, err := records.InsertOne(ctx, migration.MigrationRecord{
+		Version: "20231231_001", Description: "deleted migration", AppliedAt: time.Now().UTC(),
+	})
+	require.NoError(t, err)
+
+	m := &countingMigration{version: "20240101_001"}
+	strict := newTestEngine(t, env, []migration.EngineOption{migration.WithFailOnOrphaned(true)}, m)
+	err = strict.Up(ctx, "")
+	require.ErrorIs(t, err, migration.ErrOrphanedRecords)
+	assert.Contains(t, err.Error(), "20231231_001")
+	assert.Zero(t, m.ups, "nothing runs while an orphaned record exists")
+	require.ErrorIs(t, strict.Down(ctx, ""), migration.ErrOrphanedRecords)
+	assertLockReleased(t, env)
+
+	require.NoError(t, newTestEngine(t, env, nil, m).Up(ctx, ""), "without the option the orphan is ignored")
+	assert.Equal(t, 1, m.ups)
+}
+
+func TestEngineProbePermissions(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	db := env.MongoClient.Database(env.DBName)
+
+	checks, err := newTestEngine(t, env, nil).ProbePermissions(ctx)
+	require.NoError(t, err)
+	require.Len(t, checks, 5)
+	for _, c := range checks {
+		assert.True(t, c.Allowed, "%s: %s", c.Operation, c.Error)
+	}
+
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": bson.M{"$regex": "^" + migration.PermissionProbePrefix}})
+	require.NoError(t, err)
+	assert.Empty(t, names, "the probe collection must be dropped")
+
+	_, err = newTestEngine(t, env, []migration.EngineOption{migration.WithReadOnly(true)}).ProbePermissions(ctx)
+	require.ErrorIs(t, err, migration.ErrReadOnly)
+}
+
+func TestEngineSelfTest(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	db := env.MongoClient.Database(env.DBName)
+
+	m := &countingMigration{version: "20240101_001"}
+	steps, err := newTestEngine(t, env, nil, m).SelfTest(ctx)
+	require.NoError(t, err)
+	require.Len(t, steps, 5)
+	for _, s := range steps {
+		assert.True(t, s.Passed, "%s: %s", s.Step, s.Error)
+	}
+	assert.Equal(t, "record written", steps[1].Step, "the step checks the temporary collection was created")
+
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": env.ColName + migration.SelfTestSuffix})
+	require.NoError(t, err)
+	assert.Empty(t, names, "the self-test collection must be dropped")
+	assert.Zero(t, m.ups, "registered migrations must not run")
+	assert.Zero(t, countRecords(t, env, m.Version()))
+	assertLockReleased(t, env)
+
+	_, err = newTestEngine(t, env, []migration.EngineOption{migration.WithReadOnly(true)}).SelfTest(ctx)
+	require.ErrorIs(t, err, migration.ErrReadOnly)
+}
+
+func TestEngineConcurrentUp(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnvWith(t, ctx, mongodb.WithReplicaSet("rs0"))
+
+	const instances = 8
+	versions := []string{"20240101_001", "20240102_001", "20240103_001"}
+
+	// Every instance gets its own migration values, as separate processes would, so the
+	// counters are never shared between goroutines.
+	migrations := make([][]*countingMigration, instances)
+	engines := make([]*migration.Engine, instances)
+	for i := range instances {
+		ms := make([]migration.Migration, len(versions))
+		for j, v := range versions {
+			m := &countingMigration{version: v}
+			migrations[i] = append(migrations[i], m)
+			ms[j] = m
+		}
+		engines[i] = newTestEngine(t, env, nil, ms...)
+	}
+
+	start := make(chan struct{})
+	errs := make([]error, instances)
+	var wg sync.WaitGroup
+	for i := range instances {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			errs[i] = engines[i].Up(ctx, "")
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		assert.ErrorIs(t, err, migration.ErrLockHeld, "instance %d failed for another reason", i)
+	}
+	assert.NotZero(t, succeeded, "at least one instance must get the lock")
+
+	for j, v := range versions {
+		assert.Equal(t, int64(1), countRecords(t, env, v), "records for %s", v)
+		ups := 0
+		for i := range instances {
+			ups += migrations[i][j].ups
+		}
+		assert.Equal(t, 1, ups, "%s must run exactly once across all instances", v)
+	}
+	assertLockReleased(t, env)
+}
+
+type bulkSeedMigration struct {
+	version string
+	docs    int
+}
+
+func (m *bulkSeedMigration) Version() string     { return m.version }
+func (m *bulkSeedMigration) Description() string { return "seed and batch-delete documents" }
+
+func (m *bulkSeedMigration) Up(ctx context.Context, db *mongo.Database) error {
+	docs := make([]any, 0, m.docs)
+	for i := range m.docs {
+		docs = append(docs, bson.M{"n": i, "seeded_by": m.version})
+	}
+	_, err := db.Collection("bulk_seed").InsertMany(ctx, docs)
+	return err
+}
+
+func (m *bulkSeedMigration) Down(ctx context.Context, db *mongo.Database) error {
+	coll := db.Collection("bulk_seed")
+	filter := bson.M{"seeded_by": m.version}
+	if err := migration.ProgressFromContext(ctx).CountTotal(ctx, coll, filter); err != nil {
+		return err
+	}
+	_, err := migration.DeleteInBatches(ctx, coll, filter, 10)
+	return err
+}
+
+func TestDeleteInBatchesRemovesEveryMatch(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	coll := env.MongoClient.Database(env.DBName).Collection("batch_delete_docs")
+
+	docs := make([]any, 0, 25)
+	for i := range 25 {
+		docs = append(docs, bson.M{"_id": int32(i), "even": i%2 == 0})
+	}
+	_, err := coll.InsertMany(ctx, docs)
+	require.NoError(t, err)
+
+	deleted, err := migration.DeleteInBatches(ctx, coll, bson.M{"even": true}, 4)
+	require.NoError(t, err)
+	assert.EqualValues(t, 13, deleted, "13 even documents across 4 batches")
+
+	remaining, err := coll.CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 12, remaining, "documents outside the filter are kept")
+	evens, err := coll.CountDocuments(ctx, bson.M{"even": true})
+	require.NoError(t, err)
+	assert.Zero(t, evens)
+
+	t.Run("Reports each batch from Down", func(t *testing.T) {
+		var mu sync.Mutex
+		var updates []migration.ProgressUpdate
+		record := migration.WithProgressCallback(func(u migration.ProgressUpdate) {
+			mu.Lock()
+			defer mu.Unlock()
+			updates = append(updates, u)
+		})
+		m := &bulkSeedMigration{version: "20240601_001", docs: 25}
+		engine := newTestEngine(t, env, []migration.EngineOption{record}, m)
+
+		require.NoError(t, engine.Up(ctx, ""))
+		updates = nil
+		require.NoError(t, engine.Down(ctx, ""))
+
+		n, err := env.MongoClient.Database(env.DBName).Collection("bulk_seed").CountDocuments(ctx, bson.D{})
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		processed := make([]int64, 0, len(updates))
+		for _, u := range updates {
+			assert.EqualValues(t, 25, u.Total)
+			processed = append(processed, u.Processed)
+		}
+		assert.Equal(t, []int64{10, 20, 25}, processed)
+	})
+}
+
+type archivingMigration struct {
+	version string
+	downErr error
+}
+
+func (m *archivingMigration) Version() string              { return m.version }
+func (m *archivingMigration) Description() string          { return "create audit events" }
+func (m *archivingMigration) ArchiveOnDown() bool          { return true }
+func (m *archivingMigration) ArchiveCollections() []string { return []string{"audit_events"} }
+
+func (m *archivingMigration) Up(ctx context.Context, db *mongo.Database) error {
+	_, err := db.Collection("audit_events").InsertMany(ctx, []any{bson.M{"n": 1}, bson.M{"n": 2}})
+	return err
+}
+
+func (m *archivingMigration) Down(_ context.Context, _ *mongo.Database) error { return m.downErr }
+
+func TestEngineArchiveOnDown(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	db := env.MongoClient.Database(env.DBName)
+
+	m := &archivingMigration{version: "20240701_001", downErr: errors.New("down failed")}
+	engine := newTestEngine(t, env, nil, m)
+	require.NoError(t, engine.Up(ctx, ""))
+
+	archive := migration.ArchivedName("audit_events", m.version)
+	require.ErrorContains(t, engine.Down(ctx, ""), "down failed")
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": bson.M{"$in": bson.A{"audit_events", archive}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"audit_events"}, names, "a failed Down restores the archived collection")
+	assert.EqualValues(t, 1, countRecords(t, env, m.version))
+
+	m.downErr = nil
+	require.NoError(t, engine.Down(ctx, ""))
+	names, err = db.ListCollectionNames(ctx, bson.M{"name": bson.M{"$in": bson.A{"audit_events", archive}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{archive}, names, "the original is gone and the archive exists")
+	n, err := db.Collection(archive).CountDocuments(ctx, bson.D{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, n, "the archive keeps the data")
+	assert.Zero(t, countRecords(t, env, m.version))
+
+	t.Run("Missing collection is already archived", func(t *testing.T) {
+		target, err := migration.ArchiveCollection(ctx, db, "audit_events", m.version)
+		require.NoError(t, err)
+		assert.Equal(t, archive, target)
+	})
+
+	t.Run("Existing archive is not overwritten", func(t *testing.T) {
+		require.NoError(t, engine.Up(ctx, ""))
+		err := engine.Down(ctx, "")
+		require.ErrorIs(t, err, migration.ErrFailedToArchive)
+		assert.EqualValues(t, 1, countRecords(t, env, m.version), "the record stays when archiving fails")
+	})
+}
+
+func TestEngineRunResult(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	ms := []*countingMigration{{version: "20240101_001"}, {version: "20240102_001"}, {version: "20240103_001"}}
+	engine := newTestEngine(t, env, nil, ms[0], ms[1], ms[2])
+
+	res, err := engine.UpWithResult(ctx, ms[1].version)
+	require.NoError(t, err)
+	assert.Equal(t, migration.RunResult{
+		Direction: migration.DirectionUp, Pending: 2, Executed: []string{ms[0].version, ms[1].version},
+	}, res)
+
+	res, err = engine.UpWithResult(ctx, ms[1].version)
+	require.NoError(t, err)
+	assert.Zero(t, res.Pending, "the target is already satisfied")
+	assert.Empty(t, res.Executed)
+
+	res, err = engine.DownWithResult(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{ms[1].version, ms[0].version}, res.Executed)
+	assert.Equal(t, 2, res.Pending)
+
+	t.Run("Failed run reports what it applied", func(t *testing.T) {
+		env := setupIntegrationEnv(t, ctx)
+		ok := &countingMigration{version: "20240101_001"}
+		bad := &failingMigration{countingMigration{version: "20240102_001"}, fmt.Errorf("index build failed")}
+		engine := newTestEngine(t, env, nil, ok, bad)
+
+		res, err := engine.UpWithResult(ctx, "")
+		var migErr *migration.MigrationError
+		require.ErrorAs(t, err, &migErr)
+		assert.Equal(t, bad.version, migErr.Version)
+		assert.Equal(t, []string{ok.version}, res.Executed)
+		assert.Equal(t, 2, res.Pending)
+	})
+}
+
+func TestTrackedIndexesRollbackAfterPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	coll := env.MongoClient.Database(env.DBName).Collection("tracked_index_docs")
+
+	_, err := coll.InsertMany(ctx, []any{
+		bson.M{"email": "a@example.com", "status": "active"},
+		bson.M{"email": "a@example.com", "status": "active"},
+	})
+	require.NoError(t, err)
+	_, err = coll.Indexes().CreateOne(ctx, migration.Index(migration.Asc("status")).Name("idx_status").Model())
+	require.NoError(t, err)
+
+	tracked := migration.NewTrackedIndexes(coll)
+	err = tracked.Create(ctx,
+		migration.Index(migration.Asc("status")).Name("idx_status"),
+		migration.Index(migration.Desc("created_at")).Name("idx_created_at"),
+		migration.Index(migration.Asc("status"), migration.Desc("created_at")),
+		migration.Index(migration.Asc("email")).Name("idx_email_unique").Unique(),
+		migration.Index(migration.Asc("name")).Name("idx_name"),
+	)
+	require.Error(t, err, "duplicate emails break the unique index")
+	assert.Contains(t, err.Error(), "idx_email_unique")
+	assert.Equal(t, []string{"idx_created_at", "status_1_created_at_-1"}, tracked.Created(),
+		"the pre-existing index is not tracked and creation stops at the failure")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.NoError(t, tracked.Rollback(cancelled))
+	assert.Empty(t, tracked.Created())
+
+	specs, err := coll.Indexes().ListSpecifications(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, spec := range specs {
+		names = append(names, spec.Name)
+	}
+	assert.ElementsMatch(t, []string{"_id_", "idx_status"}, names)
+}
+
+func TestEngineTimings(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+
+	var timings []migration.PhaseTiming
+	record := migration.WithTimings(func(pt migration.PhaseTiming) { timings = append(timings, pt) })
+	ms := []*countingMigration{{version: "20240101_001"}, {version: "20240102_001"}}
+	require.NoError(t, newTestEngine(t, env, []migration.EngineOption{record}, ms[0], ms[1]).Up(ctx, ""))
+
+	require.Len(t, timings, 4)
+	assert.Equal(t, migration.PhaseLock, timings[0].Phase)
+	assert.Equal(t, migration.PhaseStatus, timings[1].Phase)
+	for i, m := range ms {
+		pt := timings[2+i]
+		assert.Equal(t, migration.PhaseMigration, pt.Phase)
+		assert.Equal(t, m.version, pt.Version)
+		assert.Equal(t, migration.DirectionUp, pt.Direction)
+		assert.False(t, pt.Failed)
+		assert.Positive(t, pt.Duration)
+	}
+}
+
+func TestEngineDownStopsAtFloor(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	ms := []*countingMigration{{version: "20240101_001"}, {version: "20240201_001"}, {version: "20240301_001"}}
+	floor := []migration.EngineOption{migration.WithFloorVersion(ms[1].version)}
+	require.NoError(t, newTestEngine(t, env, nil, ms[0], ms[1], ms[2]).Up(ctx, ""))
+
+	floored := newTestEngine(t, env, floor, ms[0], ms[1], ms[2])
+	stop, err := floored.FloorStop(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, ms[1].version, stop, "the floor cuts a full rollback short")
+	stop, err = floored.FloorStop(ctx, ms[2].version)
+	require.NoError(t, err)
+	assert.Empty(t, stop, "a target above the floor ends the run first")
+	stop, err = floored.FloorStop(ctx, "", migration.VersionFilter(ms[2].version))
+	require.NoError(t, err)
+	assert.Empty(t, stop, "a selection above the floor is not cut short")
+
+	res, err := floored.DownWithResult(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{ms[2].version}, res.Executed, "down stops above the floor")
+	assert.Equal(t, int64(1), countRecords(t, env, ms[1].version))
+	assert.Equal(t, int64(1), countRecords(t, env, ms[0].version))
+
+	err = floored.Down(ctx, ms[0].version)
+	require.ErrorIs(t, err, migration.ErrBelowFloor)
+	assert.Contains(t, err.Error(), ms[1].version)
+	assertLockReleased(t, env)
+
+	res, err = newTestEngine(t, env, nil, ms[0], ms[1], ms[2]).DownWithResult(ctx, "")
+	require.NoError(t, err, "without the floor, as with --ignore-floor, down goes all the way")
+	assert.Equal(t, []string{ms[1].version, ms[0].version}, res.Executed)
+}
+
+func TestEngineCompact(t *testing.T) {
+	ctx := context.Background()
+	env := setupIntegrationEnv(t, ctx)
+	records := env.MongoClient.Database(env.DBName).Collection(env.ColName)
+
+	stale := &describedMigration{countingMigration{version: "20240101_001"}, "add users"}
+	drifted := &countingMigration{version: "20240201_001"}
+	clean := &countingMigration{version: "20240301_001"}
+	orphan := &countingMigration{version: "20240401_001"}
+	require.NoError(t, newTestEngine(t, env, nil, stale, drifted, clean, orphan).Up(ctx, ""))
+
+	appliedAt := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, err := records.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{"applied_at": appliedAt}})
+	require.NoError(t, err)
+	_, err = records.UpdateOne(ctx, bson.M{"version": drifted.version}, bson.M{"$set": bson.M{"checksum": "deadbeef"}})
+	require.NoError(t, err)
+
+	renamed := &describedMigration{countingMigration{version: stale.version}, "add users collection"}
+	engine := newTestEngine(t, env, nil, renamed, drifted, clean)
+
+	preview, err := engine.Compact(ctx, migration.CompactOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{renamed.version, drifted.version}, preview.Rewritten)
+	assert.Equal(t, []string{orphan.version}, preview.Orphaned)
+	assert.Empty(t, preview.Dropped)
+
+	var rec migration.MigrationRecord
+	require.NoError(t, records.FindOne(ctx, bson.M{"version": drifted.version}).Decode(&rec))
+	assert.Equal(t, "deadbeef", rec.Checksum, "a dry run must not write")
+
+	res, err := engine.Compact(ctx, migration.CompactOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, preview.Rewritten, res.Rewritten)
+	assert.Equal(t, 1, res.Unchanged)
+	assert.Empty(t, res.Dropped)
+	assert.Equal(t, int64(1), countRecords(t, env, orphan.version), "orphans are kept unless dropping is confirmed")
+	assertLockReleased(t, env)
+
+	for _, m := range []migration.Migration{renamed, drifted, clean} {
+		var rec migration.MigrationRecord
+		require.NoError(t, records.FindOne(ctx, bson.M{"version": m.Version()}).Decode(&rec))
+		assert.Equal(t, m.Description(), rec.Description, m.Version())
+		assert.Equal(t, migration.Checksum(m), rec.Checksum, m.Version())
+		assert.True(t, appliedAt.Equal(rec.AppliedAt), "%s: applied_at must be preserved, got %s", m.Version(), rec.AppliedAt)
+	}
+	require.NoError(t, engine.Up(ctx, ""), "compacted records pass checksum validation")
+
+	_, err = records.InsertOne(ctx, migration.MigrationRecord{Version: "20240402_001", AppliedAt: appliedAt})
+	require.NoError(t, err)
+	res, err = engine.Compact(ctx, migration.CompactOptions{DropOrphaned: preview.Orphaned})
+	require.NoError(t, err)
+	assert.Empty(t, res.Rewritten)
+	assert.Equal(t, preview.Orphaned, res.Dropped)
+	assert.Zero(t, countRecords(t, env, orphan.version))
+	assert.Equal(t, int64(1), countRecords(t, env, "20240402_001"),
+		"an orphan that was not confirmed must be kept")
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newAdminCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "admin", Short: "Maintenance of the migrations history"}
+	cmd.AddCommand(newCompactCmd())
+	return cmd
+}
+
+func newCompactCmd() *cobra.Command {
+	var (
+		assumeYes bool
+		dryRun    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Rewrite applied records from the registered migrations and drop orphaned ones",
+		Long: "Rewrites every applied record with the registered description, checksum and metadata, " +
+			"keeping when it was applied. Records of migrations that are no longer registered are " +
+			"dropped after confirmation. Only run it once the registered migrations are known to be " +
+			"the ones that were applied, since it also accepts changed checksums.",
+		Annotations: map[string]string{annotationMutating: "true", annotationPreview: "dry-run"},
+		Example: `  mt admin compact --dry-run
+  mt admin compact --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			engine, err := getEngine(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			preview, err := engine.Compact(cmd.Context(), migration.CompactOptions{DryRun: true})
+			if err != nil {
+				return fmt.Errorf("%s: %w", ErrFailedToCompact, err)
+			}
+			if dryRun {
+				writeCompactResult(out, preview, true)
+				return nil
+			}
+			if len(preview.Rewritten) == 0 && len(preview.Orphaned) == 0 {
+				writeCompactResult(out, preview, false)
+				return nil
+			}
+
+			var drop []string
+			if len(preview.Orphaned) > 0 && (assumeYes || promptConfirmation(cmd, fmt.Sprintf(
+				"WARNING: This deletes the records of %d unregistered migration(s): %s. Drop them? [y/N]: ",
+				len(preview.Orphaned), strings.Join(preview.Orphaned, ", ")))) {
+				drop = preview.Orphaned
+			}
+
+			res, err := engine.Compact(cmd.Context(), migration.CompactOptions{DropOrphaned: drop})
+			if err != nil {
+				return fmt.Errorf("%s: %w", ErrFailedToCompact, err)
+			}
+			writeCompactResult(out, res, false)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Drop orphaned records without prompting")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the records that would change without writing")
+	return cmd
+}
+
+func writeCompactResult(w io.Writer, res migration.CompactResult, dryRun bool) {
+	rewritten, dropped := "Rewrote", "Dropped"
+	if dryRun {
+		rewritten, dropped = "Would rewrite", "Would drop"
+	}
+	if len(res.Rewritten) == 0 && len(res.Orphaned) == 0 {
+		fmt.Fprintf(w, "%s All %d record(s) are already compact.\n", ui.OK, res.Unchanged)
+		return
+	}
+
+	fmt.Fprintf(w, "%s %d record(s), %d unchanged.\n", rewritten, len(res.Rewritten), res.Unchanged)
+	for _, v := range res.Rewritten {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+	if dryRun {
+		writeVersions(w, fmt.Sprintf("%s %d orphaned record(s):", dropped, len(res.Orphaned)), res.Orphaned)
+		return
+	}
+	kept := slices.DeleteFunc(slices.Clone(res.Orphaned), func(v string) bool { return slices.Contains(res.Dropped, v) })
+	writeVersions(w, fmt.Sprintf("%s %d orphaned record(s):", dropped, len(res.Dropped)), res.Dropped)
+	writeVersions(w, fmt.Sprintf("%s Kept %d orphaned record(s):", ui.Warn, len(kept)), kept)
+}
+
+// writeVersions prints header and one indented line per version, or nothing without versions.
+func writeVersions(w io.Writer, header string, versions []string) {
+	if len(versions) == 0 {
+		return
+	}
+	fmt.Fprintln(w, header)
+	for _, v := range versions {
+		fmt.Fprintf(w, "  %s\n", v)
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestWriteCompactResult(t *testing.T) {
+	res := migration.CompactResult{
+		Rewritten: []string{"20240101_001"},
+		Unchanged: 2,
+		Orphaned:  []string{"20240401_001"},
+	}
+
+	tests := []struct {
+		name   string
+		res    migration.CompactResult
+		dryRun bool
+		want   []string
+	}{
+		{name: "dry run", res: res, dryRun: true,
+			want: []string{"Would rewrite 1 record(s), 2 unchanged.", "Would drop 1 orphaned record(s):", "20240401_001"}},
+		{name: "orphans kept", res: res,
+			want: []string{"Rewrote 1 record(s), 2 unchanged.", "Kept 1 orphaned record(s):"}},
+		{name: "orphans dropped", res: migration.CompactResult{Orphaned: res.Orphaned, Dropped: res.Orphaned},
+			want: []string{"Rewrote 0 record(s), 0 unchanged.", "Dropped 1 orphaned record(s):"}},
+		{name: "orphan found after confirming kept", res: migration.CompactResult{
+			Orphaned: []string{"20240401_001", "20240402_001"}, Dropped: []string{"20240401_001"},
+		}, want: []string{"Dropped 1 orphaned record(s):\n  20240401_001", "Kept 1 orphaned record(s):\n  20240402_001"}},
+		{name: "nothing to do", res: migration.CompactResult{Unchanged: 3},
+			want: []string{"All 3 record(s) are already compact."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			writeCompactResult(&buf, tt.res, tt.dryRun)
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("output missing %q:\n%s", want, buf.String())
+				}
+			}
+		})
+	}
+}
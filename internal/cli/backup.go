@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+type migrationExport struct {
+	ExportedAt time.Time                   `json:"exported_at"`
+	Database   string                      `json:"database"`
+	Collection string                      `json:"collection"`
+	Records    []migration.MigrationRecord `json:"records"`
+}
+
+func newExportCmd() *cobra.Command {
+	var out string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export applied migration records to JSON for backup",
+		Example: `  mt export --out migrations.json
+  mt export > migrations.json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			s, err := getServices(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			records, err := s.Engine.ListApplied(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			data, err := jsonutil.MarshalIndent(migrationExport{
+				ExportedAt: time.Now().UTC(),
+				Database:   s.Config.Database,
+				Collection: s.Config.MigrationsCollection,
+				Records:    records,
+			}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode export: %w", err)
+			}
+
+			if out == "" {
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			if err := os.WriteFile(out, append(data, '\n'), 0600); err != nil {
+				return fmt.Errorf("failed to write export: %w", err)
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d migration records to %s\n", len(records), out)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Output file (defaults to stdout)")
+	return cmd
+}
+
+func newImportCmd() *cobra.Command {
+	var in string
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Restore migration records from an export file",
+		Long: "Upserts exported records by version. Records whose checksum conflicts with the " +
+			"registered migration are skipped and reported.",
+		Annotations: map[string]string{annotationMutating: "true"},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			engine, err := getEngine(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			raw, err := readPayload(cmd.InOrStdin(), in)
+			if err != nil {
+				return fmt.Errorf("failed to read import: %w", err)
+			}
+			var export migrationExport
+			if err := jsonutil.Unmarshal(raw, &export); err != nil {
+				return fmt.Errorf("failed to decode import: %w", err)
+			}
+
+			result, err := engine.ImportRecords(cmd.Context(), export.Records)
+			if err != nil {
+				return err
+			}
+			renderImportResult(cmd.OutOrStdout(), result)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&in, "in", "i", "", "Input file (defaults to stdin)")
+	return cmd
+}
+
+func renderImportResult(w io.Writer, result migration.ImportResult) {
+	fmt.Fprintf(w, "Imported %d migration records.\n", len(result.Imported))
+	if len(result.Conflicts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s Skipped %d records with checksum conflicts:\n", ui.Warn, len(result.Conflicts))
+	for _, c := range result.Conflicts {
+		fmt.Fprintf(w, "  %s: recorded %s, registry %s\n", c.Version, c.Recorded, c.Current)
+	}
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"io"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/spf13/cobra"
+)
+
+func newCatalogCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:         "catalog",
+		Short:       "List the registered migrations without connecting to MongoDB",
+		Annotations: map[string]string{annotationOffline: "true"},
+		Example:     `  mt catalog --output json > catalog.json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return writeCatalog(cmd.OutOrStdout(), format, migration.RegisteredMigrations())
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "output", "o", render.FormatTable, render.FlagUsage)
+	return cmd
+}
+
+func writeCatalog(w io.Writer, format string, ms map[string]migration.Migration) error {
+	entries := migration.Catalog(ms)
+	list := render.List{
+		Columns: []string{"VERSION", "DESCRIPTION"},
+		Items:   entries,
+		Empty:   "No migrations registered.",
+	}
+	for _, e := range entries {
+		list.Rows = append(list.Rows, []string{e.Version, e.Description})
+	}
+	return render.Write(w, format, list)
+}
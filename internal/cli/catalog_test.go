@@ -0,0 +1,34 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestWriteCatalogJSON(t *testing.T) {
+	registry := map[string]migration.Migration{
+		"20240102_001": doctorMigration{version: "20240102_001"},
+		"20240101_001": doctorMigration{version: "20240101_001"},
+	}
+
+	var out bytes.Buffer
+	if err := writeCatalog(&out, "json", registry); err != nil {
+		t.Fatalf("writeCatalog() error = %v", err)
+	}
+
+	var got []migration.CatalogEntry
+	if err := jsonutil.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("catalog is not valid JSON: %v\n%s", err, out.String())
+	}
+	want := []migration.CatalogEntry{
+		{Version: "20240101_001", Description: "doctor", Checksum: migration.Checksum(registry["20240101_001"])},
+		{Version: "20240102_001", Description: "doctor", Checksum: migration.Checksum(registry["20240102_001"])},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("catalog = %+v, want %+v", got, want)
+	}
+}
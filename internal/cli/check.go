@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newCheckCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:         "check",
+		Short:       "Verify that registered migrations have distinct, non-empty versions",
+		Annotations: map[string]string{annotationOffline: "true"},
+		Long: "Inspects every registered migration without connecting to MongoDB. Empty or duplicated " +
+			"versions fail the check; versions outside the recommended formats only produce a warning.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			warnings, err := migration.CheckRegistry()
+			out := cmd.OutOrStdout()
+			for _, w := range warnings {
+				fmt.Fprintf(out, "%s %s\n", ui.Warn, w)
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(out, "%s %d migration(s) checked.\n", ui.OK, len(migration.RegisteredMigrations()))
+			return nil
+		},
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newChecksumGenCmd() *cobra.Command {
+	var path, output string
+
+	cmd := &cobra.Command{
+		Use:   "checksum-gen",
+		Short: "Generate checksums.go with hashes of each migration's Up and Down source",
+		Long: "Parses the migrations directory, hashes the Up and Down methods of every migration " +
+			"found there and writes a generated checksums.go that registers the hashes. Checksums then " +
+			"cover the migration code, so editing an applied migration is reported as drift. Rerun it " +
+			"whenever a migration changes, for example with go:generate.",
+		Annotations: map[string]string{annotationOffline: "true"},
+		Example: `  mt checksum-gen
+  mt checksum-gen --path migrations --output migrations/checksums.go`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if path == "" {
+				cfg, err := getConfig(cmd.Context())
+				if err != nil {
+					return err
+				}
+				path = cfg.MigrationsPath
+			}
+			if path == "" {
+				return fmt.Errorf("no migrations path configured; set MIGRATIONS_PATH or pass --path")
+			}
+			if output == "" {
+				output = filepath.Join(path, migration.BodyChecksumsFile)
+			}
+
+			n, err := generateBodyChecksums(path, output)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Wrote %d checksum(s) to %s\n", ui.Done, n, output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Directory to scan (defaults to MIGRATIONS_PATH)")
+	cmd.Flags().StringVar(&output, "output", "", "File to write (defaults to checksums.go in --path)")
+	return cmd
+}
+
+// generateBodyChecksums hashes the migrations in dir and writes the registering file to
+// output, returning how many migrations it covers.
+func generateBodyChecksums(dir, output string) (int, error) {
+	sums, err := migration.HashMigrationSources(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+	pkg, err := migration.PackageName(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	var buf bytes.Buffer
+	if err := migration.WriteBodyChecksums(&buf, pkg, sums); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(output, buf.Bytes(), 0600); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	return len(sums), nil
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "config", Short: "Configuration utilities"}
+	cmd.AddCommand(newConfigInitCmd())
+	return cmd
+}
+
+func newConfigInitCmd() *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:         "init [path]",
+		Short:       "Write a commented .env template with every setting and its default",
+		Args:        cobra.MaximumNArgs(1),
+		Annotations: map[string]string{annotationNoConfig: "true"},
+		Example: `  mt config init
+  mt config init configs/.env.staging --force`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ".env"
+			if len(args) == 1 {
+				path = args[0]
+			}
+
+			flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+			if !force {
+				flags |= os.O_EXCL
+			}
+			f, err := os.OpenFile(path, flags, 0600)
+			if errors.Is(err, fs.ErrExist) {
+				return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", path, err)
+			}
+			defer f.Close()
+
+			if err := config.WriteTemplate(f); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Wrote %s; set MONGO_DATABASE before running other commands.\n", ui.Done, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing file")
+	return cmd
+}
@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/config"
 	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
@@ -13,6 +14,30 @@ type safeConfig struct {
 	Database             string `json:"database"`
 	MigrationsPath       string `json:"migrations_path"`
 	MigrationsCollection string `json:"migrations_collection"`
+	VersionFormat        string `json:"version_format"`
+	VersionOrder         string `json:"version_order"`
+	Environment          string `json:"environment,omitempty"`
+	CausalConsistency    bool   `json:"causal_consistency"`
+	BulkConcurrency      int    `json:"bulk_concurrency"`
+	MaxOpsPerSec         int    `json:"max_ops_per_sec,omitempty"`
+	RecordWriteConcern   string `json:"record_write_concern,omitempty"`
+	RecordReadConcern    string `json:"record_read_concern,omitempty"`
+	ReadOnly             bool   `json:"read_only"`
+	ReadFromSecondary    bool   `json:"read_from_secondary"`
+	NoTransaction        bool   `json:"no_transaction"`
+	ExpectedDatabase     string `json:"expected_database,omitempty"`
+	AuditCollection      string `json:"audit_collection,omitempty"`
+	ForbidDrops          bool   `json:"forbid_drops"`
+	RequireReversible    bool   `json:"require_reversible"`
+	FailOnOrphaned       bool   `json:"fail_on_orphaned"`
+	RepairDescriptions   bool   `json:"auto_repair_descriptions"`
+	StrictChecksums      bool   `json:"strict_checksums"`
+	FloorVersion         string `json:"floor_version,omitempty"`
+	MaintenanceWindow    string `json:"maintenance_window,omitempty"`
+	MaintenanceTimezone  string `json:"maintenance_timezone,omitempty"`
+	Icons                string `json:"icons,omitempty"`
+	StaleLockTimeout     string `json:"stale_lock_timeout,omitempty"`
+	MCPHealthInterval    string `json:"mcp_health_interval,omitempty"`
 	Username             string `json:"username"`
 	Password             string `json:"password"`
 	AuthSource           string `json:"auth_source"`
@@ -23,6 +48,9 @@ type safeConfig struct {
 	TimeoutSeconds       int    `json:"timeout_seconds"`
 	GoogleDocsEnabled    bool   `json:"google_docs_enabled"`
 	GoogleCredentials    string `json:"google_credentials"`
+
+	Preflight         []string          `json:"preflight,omitempty"`
+	ConnectionOptions map[string]string `json:"connection_options,omitempty"`
 }
 
 func renderConfig(out io.Writer, cfg *config.Config) error {
@@ -33,6 +61,30 @@ func renderConfig(out io.Writer, cfg *config.Config) error {
 		Database:             cfg.Database,
 		MigrationsPath:       cfg.MigrationsPath,
 		MigrationsCollection: cfg.MigrationsCollection,
+		VersionFormat:        cfg.VersionFormat,
+		VersionOrder:         cfg.VersionOrder,
+		Environment:          cfg.Environment,
+		CausalConsistency:    cfg.CausalConsistency,
+		BulkConcurrency:      cfg.BulkConcurrency,
+		MaxOpsPerSec:         cfg.MaxOpsPerSec,
+		RecordWriteConcern:   cfg.RecordWriteConcern,
+		RecordReadConcern:    cfg.RecordReadConcern,
+		ReadOnly:             cfg.ReadOnly,
+		ReadFromSecondary:    cfg.ReadFromSecondary,
+		NoTransaction:        cfg.NoTransaction,
+		ExpectedDatabase:     cfg.ExpectedDatabase,
+		AuditCollection:      cfg.AuditCollection,
+		ForbidDrops:          cfg.ForbidDrops,
+		RequireReversible:    cfg.RequireReversible,
+		FailOnOrphaned:       cfg.FailOnOrphaned,
+		RepairDescriptions:   cfg.RepairDescriptions,
+		StrictChecksums:      cfg.StrictChecksums,
+		FloorVersion:         cfg.FloorVersion,
+		MaintenanceWindow:    cfg.MaintenanceWindow,
+		MaintenanceTimezone:  cfg.MaintenanceTimezone,
+		Icons:                cfg.Icons,
+		StaleLockTimeout:     durationString(cfg.StaleLockTimeout),
+		MCPHealthInterval:    durationString(cfg.MCPHealthInterval),
 		Username:             cfg.Username,
 		Password:             maskSecret(cfg.Password),
 		AuthSource:           cfg.MongoAuthSource,
@@ -43,6 +95,8 @@ func renderConfig(out io.Writer, cfg *config.Config) error {
 		TimeoutSeconds:       cfg.Timeout,
 		GoogleDocsEnabled:    cfg.GoogleDocsEnabled,
 		GoogleCredentials:    maskSecret(firstNonEmpty(cfg.GoogleCredentialsPath, cfg.GoogleCredentialsJSON)),
+		Preflight:            cfg.Preflight,
+		ConnectionOptions:    cfg.ConnectionOptions,
 	}
 	if err := enc.Encode(safe); err != nil {
 		return fmt.Errorf("render config: %w", err)
@@ -68,3 +122,10 @@ func firstNonEmpty(values ...string) string {
 	}
 	return ""
 }
+
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
+}
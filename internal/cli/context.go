@@ -6,6 +6,7 @@ import (
 
 	"github.com/drewjocham/mongo-migration-tool/internal/config"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/spf13/cobra"
 )
 
 type ctxKey string
@@ -39,3 +40,21 @@ func getConfig(ctx context.Context) (*config.Config, error) {
 	}
 	return cfg, nil
 }
+
+// engineWith returns the engine of cmd rebuilt for a copy of the config changed by adjust,
+// with extra options. bootstrap builds the engine before the subcommand runs, so a
+// subcommand flag that changes how the engine is built goes through here.
+func engineWith(
+	cmd *cobra.Command, adjust func(*config.Config), extra ...migration.EngineOption,
+) (*migration.Engine, error) {
+	s, err := getServices(cmd.Context())
+	if err != nil {
+		return nil, err
+	}
+	if s.MongoClient == nil {
+		return nil, fmt.Errorf("mongo client unavailable")
+	}
+	cfg := *s.Config
+	adjust(&cfg)
+	return newEngine(s.MongoClient, &cfg, cmd.OutOrStdout(), extra...)
+}
@@ -2,26 +2,47 @@ package cli
 
 import (
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 func newCreateCmd() *cobra.Command {
+	var (
+		versionFormat string
+		version       string
+		withTest      bool
+	)
+
 	cmd := &cobra.Command{
 		Use:         "create [migration_name]",
 		Short:       "Create a new migration file",
 		Args:        cobra.ExactArgs(1),
 		Annotations: map[string]string{annotationOffline: "true"},
+		Example: `  mt create add_user_indexes
+  mt create add_user_indexes --version-format sequence
+  mt create add_user_indexes --version-format semver --version v1.4.0
+  mt create add_user_indexes --with-test`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := getConfig(cmd.Context())
 			if err != nil {
 				return err
 			}
 
+			if versionFormat == "" {
+				versionFormat = cfg.VersionFormat
+			}
 			gen := &migration.Generator{
-				OutputPath: cfg.MigrationsPath,
+				OutputPath:    cfg.MigrationsPath,
+				VersionFormat: versionFormat,
+				Version:       version,
+				Existing:      slices.Collect(maps.Keys(migration.RegisteredMigrations())),
+				WithTest:      withTest,
 			}
 
 			path, version, err := gen.Create(args[0])
@@ -29,22 +50,35 @@ func newCreateCmd() *cobra.Command {
 				return err
 			}
 
-			renderSuccess(path, version)
+			renderSuccess(path, version, withTest)
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVar(&versionFormat, "version-format", "",
+		"Version stamp: timestamp, sequence or semver (overrides MIGRATIONS_VERSION_FORMAT)")
+	cmd.Flags().StringVar(&version, "version", "", "Explicit version for the semver format (e.g. v1.4.0)")
+	cmd.Flags().BoolVar(&withTest, "with-test", false, "Also generate a _test.go skeleton that runs Up and Down")
 	return cmd
 }
 
-func renderSuccess(path, version string) {
+func renderSuccess(path, version string, withTest bool) {
 	displayPath := path
 	if rel, err := filepath.Rel(".", path); err == nil {
 		displayPath = rel
 	}
 
-	fmt.Printf("\n✨ Migration created: %s\n", displayPath)
+	fmt.Printf("\n%s Migration created: %s\n", ui.Done, displayPath)
+	if withTest {
+		fmt.Printf("🧪 Test created:      %s\n", strings.TrimSuffix(displayPath, ".go")+"_test.go")
+	}
 	fmt.Printf("\nNext steps:\n")
 	fmt.Printf("  1. Edit logic: code %s\n", displayPath)
+	if withTest {
+		fmt.Printf("  2. Unit test:  MONGO_URL=mongodb://localhost:27017 go test ./%s\n",
+			filepath.ToSlash(filepath.Dir(displayPath)))
+		fmt.Printf("  3. Test run:   mt up --target %s\n\n", version)
+		return
+	}
 	fmt.Printf("  2. Test run:   mt up --target %s\n\n", version)
 }
@@ -9,11 +9,13 @@ import (
 	"time"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/tidwall/gjson"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type HealthReport struct {
@@ -22,6 +24,12 @@ type HealthReport struct {
 	OplogWindow string            `json:"oplog_window"`
 	OplogSize   string            `json:"oplog_size"`
 	Connections string            `json:"connections"`
+	Created     int64             `json:"connections_total_created"`
+	Active      int64             `json:"connections_active"`
+	Utilization string            `json:"connections_utilization"`
+	Since       string            `json:"since,omitempty"`
+	SinceWindow string            `json:"since_window,omitempty"`
+	SinceCount  int64             `json:"since_entries,omitempty"`
 	Lag         map[string]string `json:"lag,omitempty"`
 	Warnings    []string          `json:"warnings,omitempty"`
 }
@@ -33,11 +41,16 @@ func NewDBCmd() *cobra.Command {
 }
 
 func newDBHealthCmd() *cobra.Command {
-	var output string
+	var output, since string
 	cmd := &cobra.Command{
 		Use:   "health",
 		Short: "Show database health and metrics",
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			sinceTime, err := parseSince(since, time.Now())
+			if err != nil {
+				return err
+			}
+
 			s, err := getServices(cmd.Context())
 			if err != nil {
 				return err
@@ -47,6 +60,11 @@ func newDBHealthCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
+			if sinceTime != nil {
+				if err := addSinceWindow(cmd.Context(), s.MongoClient, &report, *sinceTime); err != nil {
+					return err
+				}
+			}
 
 			if strings.ToLower(output) == "json" {
 				data, err := bson.MarshalExtJSONIndent(report, true, false, "", "  ")
@@ -62,16 +80,94 @@ func newDBHealthCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json)")
+	cmd.Flags().StringVar(&since, "since", "",
+		"Report the oplog window from this point (RFC3339, YYYY-MM-DD, or a duration such as 24h)")
 	return cmd
 }
 
+// parseSince accepts an absolute time or a duration counted back from now.
+func parseSince(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		t := now.Add(-d)
+		return &t, nil
+	}
+	t, err := parseOpslogTime(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --since: %w", err)
+	}
+	return &t, nil
+}
+
+// addSinceWindow counts the oplog entries at or after since and checks that since is
+// still retained, which is what resuming a change stream from that point requires.
+func addSinceWindow(ctx context.Context, client *mongo.Client, report *HealthReport, since time.Time) error {
+	coll, err := oplogCollection(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	var oldest, newest oplogEntry
+	natural := func(dir int) *options.FindOneOptionsBuilder {
+		return options.FindOne().SetSort(bson.D{{Key: "$natural", Value: dir}})
+	}
+	if err := coll.FindOne(ctx, bson.D{}, natural(1)).Decode(&oldest); err != nil {
+		return fmt.Errorf("failed to read oldest oplog entry: %w", err)
+	}
+	if err := coll.FindOne(ctx, bson.D{}, natural(-1)).Decode(&newest); err != nil {
+		return fmt.Errorf("failed to read newest oplog entry: %w", err)
+	}
+
+	count, err := coll.CountDocuments(ctx, bson.D{{Key: "ts", Value: bson.D{
+		{Key: "$gte", Value: bson.Timestamp{T: uint32(since.Unix())}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("failed to count oplog entries: %w", err)
+	}
+
+	applySinceWindow(report, since, oplogEntryTime(oldest), oplogEntryTime(newest), count)
+	return nil
+}
+
+func applySinceWindow(report *HealthReport, since, oldest, newest time.Time, count int64) {
+	report.Since = since.UTC().Format(time.RFC3339)
+	report.SinceCount = count
+	window := newest.Sub(since)
+	if window < 0 {
+		window = 0
+	}
+	report.SinceWindow = window.Truncate(time.Second).String()
+
+	if since.Before(oldest) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(
+			"Point %s is no longer in the oplog (oldest entry %s); change streams cannot resume from it",
+			report.Since, oldest.UTC().Format(time.RFC3339)))
+	}
+}
+
+func oplogEntryTime(e oplogEntry) time.Time {
+	if e.Wall != nil {
+		return *e.Wall
+	}
+	return time.Unix(int64(e.TS.T), 0)
+}
+
+// poolUtilizationWarning is the share of the server's connection capacity in use above
+// which the report suggests revisiting pool sizing.
+const poolUtilizationWarning = 80.0
+
 func buildReport(ctx context.Context, client *mongo.Client, dbName string) (HealthReport, error) {
 	var raw bson.M
 	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "serverStatus", Value: 1}}).Decode(&raw); err != nil {
 		return HealthReport{}, err
 	}
+	return reportFromStatus(raw, dbName), nil
+}
 
-	data, _ := bson.MarshalExtJSON(raw, true, true)
+func reportFromStatus(raw bson.M, dbName string) HealthReport {
+	data, _ := bson.MarshalExtJSON(raw, false, true)
 	json := gjson.ParseBytes(data)
 
 	report := HealthReport{
@@ -83,6 +179,17 @@ func buildReport(ctx context.Context, client *mongo.Client, dbName string) (Heal
 	curr := json.Get("connections.current").Int()
 	avail := json.Get("connections.available").Int()
 	report.Connections = fmt.Sprintf("%d / %d", curr, avail)
+	report.Created = json.Get("connections.totalCreated").Int()
+	report.Active = json.Get("connections.active").Int()
+	if total := curr + avail; total > 0 {
+		used := float64(curr) / float64(total) * 100
+		report.Utilization = fmt.Sprintf("%.1f%%", used)
+		if used > poolUtilizationWarning {
+			report.Warnings = append(report.Warnings, fmt.Sprintf(
+				"Connection utilization is %.1f%% (%d of %d); consider lowering MONGO_MAX_POOL_SIZE or raising the server limit",
+				used, curr, total))
+		}
+	}
 
 	windowSecs := json.Get("oplog.windowSeconds").Float()
 	report.OplogWindow = (time.Duration(windowSecs) * time.Second).String()
@@ -112,7 +219,7 @@ func buildReport(ctx context.Context, client *mongo.Client, dbName string) (Heal
 		report.Warnings = append(report.Warnings, "Oplog window is under 6 hours")
 	}
 
-	return report, nil
+	return report
 }
 
 
@@ -129,8 +236,18 @@ func RenderHealthTable(w io.Writer, r HealthReport) {
 
 	fmt.Fprintf(tw, "Role\t%s%s\033[0m\n", roleColor, r.Role)
 	fmt.Fprintf(tw, "Connections\t%s\n", r.Connections)
+	fmt.Fprintf(tw, "Active Connections\t%d\n", r.Active)
+	fmt.Fprintf(tw, "Total Created\t%d\n", r.Created)
+	if r.Utilization != "" {
+		fmt.Fprintf(tw, "Utilization\t%s\n", r.Utilization)
+	}
 	fmt.Fprintf(tw, "Oplog Window\t%s\n", r.OplogWindow)
 	fmt.Fprintf(tw, "Oplog Size\t%s\n", r.OplogSize)
+	if r.Since != "" {
+		fmt.Fprintf(tw, "Since\t%s\n", r.Since)
+		fmt.Fprintf(tw, "Window Since\t%s\n", r.SinceWindow)
+		fmt.Fprintf(tw, "Entries Since\t%d\n", r.SinceCount)
+	}
 
 	for node, lag := range r.Lag {
 		fmt.Fprintf(tw, "Lag (%s)\t%s\n", node, lag)
@@ -138,7 +255,7 @@ func RenderHealthTable(w io.Writer, r HealthReport) {
 	tw.Flush()
 
 	if len(r.Warnings) > 0 {
-		fmt.Fprintln(w, "\n\033[33m\033[1m⚠️  WARNINGS\033[0m")
+		fmt.Fprintf(w, "\n\033[33m\033[1m%s WARNINGS\033[0m\n", ui.Warn)
 		for _, warn := range r.Warnings {
 			fmt.Fprintf(w, "  \033[33m!\033[0m %s\n", warn)
 		}
@@ -153,19 +270,14 @@ func RenderMigrationTable(w io.Writer, status []migration.MigrationStatus) {
 
 	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', tabwriter.StripEscape)
 
-	const (
-		iconPending = " [ ] PENDING"
-		iconApplied = " \033[32m[✓] APPLIED\033[0m"
-	)
-
 	fmt.Fprintln(tw, "\033[1mSTATE\tVERSION\tAPPLIED AT\tDESCRIPTION\033[0m")
 
 	for _, s := range status {
-		state := iconPending
+		state := " " + ui.Pending.String() + " PENDING"
 		appliedAt := "-"
 
 		if s.Applied {
-			state = iconApplied
+			state = " \033[32m" + ui.Applied.String() + " APPLIED\033[0m"
 			if s.AppliedAt != nil {
 				appliedAt = s.AppliedAt.Format("2006-01-02 15:04")
 			}
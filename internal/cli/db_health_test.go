@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestApplySinceWindow(t *testing.T) {
+	oldest := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		since      time.Time
+		wantWarn   bool
+		wantWindow string
+	}{
+		{name: "Within retained window", since: oldest.Add(24 * time.Hour), wantWindow: "24h0m0s"},
+		{name: "Exactly the oldest entry", since: oldest, wantWindow: "48h0m0s"},
+		{name: "Before the oldest entry", since: oldest.Add(-time.Hour), wantWarn: true, wantWindow: "49h0m0s"},
+		{name: "After the newest entry", since: newest.Add(time.Hour), wantWindow: "0s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := HealthReport{}
+			applySinceWindow(&report, tt.since, oldest, newest, 7)
+
+			warned := false
+			for _, w := range report.Warnings {
+				if strings.Contains(w, "no longer in the oplog") {
+					warned = true
+				}
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("warning fired = %v, want %v (warnings: %v)", warned, tt.wantWarn, report.Warnings)
+			}
+			if report.SinceWindow != tt.wantWindow {
+				t.Errorf("SinceWindow = %q, want %q", report.SinceWindow, tt.wantWindow)
+			}
+			if report.SinceCount != 7 {
+				t.Errorf("SinceCount = %d, want 7", report.SinceCount)
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 1, 12, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseSince("6h", now)
+	if err != nil || !got.Equal(now.Add(-6*time.Hour)) {
+		t.Errorf("parseSince(6h) = %v, %v", got, err)
+	}
+	got, err = parseSince("2024-01-10", now)
+	if err != nil || !got.Equal(time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("parseSince(2024-01-10) = %v, %v", got, err)
+	}
+	if got, err := parseSince("", now); got != nil || err != nil {
+		t.Errorf("parseSince(\"\") = %v, %v, want nil, nil", got, err)
+	}
+	if _, err := parseSince("yesterday", now); err == nil {
+		t.Error("parseSince(yesterday) should fail")
+	}
+}
+
+func TestReportFromStatusPoolMetrics(t *testing.T) {
+	status := func(current, available int32) bson.M {
+		return bson.M{
+			"connections": bson.M{"current": current, "available": available, "totalCreated": int64(1234), "active": int32(7)},
+			"oplog":       bson.M{"windowSeconds": 86400.0},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		current, avail  int32
+		wantUtilization string
+		wantWarn        bool
+	}{
+		{name: "Plenty of headroom", current: 10, avail: 90, wantUtilization: "10.0%"},
+		{name: "At the threshold", current: 80, avail: 20, wantUtilization: "80.0%"},
+		{name: "Above the threshold", current: 95, avail: 5, wantUtilization: "95.0%", wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := reportFromStatus(status(tt.current, tt.avail), "app")
+
+			if report.Created != 1234 || report.Active != 7 {
+				t.Errorf("Created, Active = %d, %d, want 1234, 7", report.Created, report.Active)
+			}
+			if report.Utilization != tt.wantUtilization {
+				t.Errorf("Utilization = %q, want %q", report.Utilization, tt.wantUtilization)
+			}
+
+			warned := false
+			for _, w := range report.Warnings {
+				if strings.Contains(w, "Connection utilization") {
+					warned = true
+				}
+			}
+			if warned != tt.wantWarn {
+				t.Errorf("warning fired = %v, want %v (warnings: %v)", warned, tt.wantWarn, report.Warnings)
+			}
+		})
+	}
+}
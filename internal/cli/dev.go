@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newDevCmd() *cobra.Command {
+	var (
+		path     string
+		run      string
+		interval time.Duration
+		debounce time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Watch the migrations directory and apply migrations as they are added",
+		Long: "Polls the migrations directory for added and changed .go files. Once it has been quiet " +
+			"for --debounce, every new migration is logged and --run is executed. Go migrations are " +
+			"registered at compile time, so --run has to rebuild the binary that imports them and " +
+			"apply them, for example `go run ./cmd/migrate up`. Files that do not parse are reported " +
+			"and skipped. Meant for local development only. Since --run applies migrations, dev is " +
+			"refused like up in production without --confirm-production, with --read-from-secondary " +
+			"and outside the maintenance window.",
+		Annotations: map[string]string{annotationOffline: "true", annotationMutating: "true"},
+		Example: `  mt dev
+  mt dev --path migrations --run "go run ./cmd/migrate up"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if path == "" {
+				cfg, err := getConfig(cmd.Context())
+				if err != nil {
+					return err
+				}
+				path = cfg.MigrationsPath
+			}
+			if path == "" {
+				return fmt.Errorf("no migrations path configured; set MIGRATIONS_PATH or pass --path")
+			}
+			args := strings.Fields(run)
+			if len(args) == 0 {
+				return fmt.Errorf("--run must name the command that applies the migrations")
+			}
+
+			w := &devWatcher{
+				dir:      path,
+				interval: interval,
+				debounce: debounce,
+				out:      cmd.OutOrStdout(),
+				apply: func(ctx context.Context, _ []migration.DiscoveredMigration) error {
+					c := exec.CommandContext(ctx, args[0], args[1:]...)
+					c.Stdout, c.Stderr = cmd.OutOrStdout(), cmd.ErrOrStderr()
+					return c.Run()
+				},
+			}
+			if err := w.init(); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Watching %s for new migrations. Press Ctrl-C to stop.\n", path)
+			return w.run(cmd.Context())
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Directory to watch (defaults to MIGRATIONS_PATH)")
+	cmd.Flags().StringVar(&run, "run", "go run . up", "Command that rebuilds and applies the migrations")
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often to check the directory")
+	cmd.Flags().DurationVar(&debounce, "debounce", 500*time.Millisecond,
+		"How long the directory must be unchanged before new migrations are applied")
+	return cmd
+}
+
+// devWatcher polls dir and calls apply with the migrations that appeared since the last
+// call, once no file has changed for debounce. Migrations present at init are not applied.
+type devWatcher struct {
+	dir      string
+	interval time.Duration
+	debounce time.Duration
+	out      io.Writer
+	apply    func(ctx context.Context, added []migration.DiscoveredMigration) error
+
+	stamps map[string]fileStamp
+	known  map[string]bool
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// init records the files and migrations already in dir.
+func (w *devWatcher) init() error {
+	stamps, err := scanGoFiles(w.dir)
+	if err != nil {
+		return err
+	}
+	w.stamps, w.known = stamps, make(map[string]bool)
+	for _, m := range w.discover(stamps) {
+		w.known[m.Version] = true
+	}
+	return nil
+}
+
+// run polls until ctx is done. A failed apply is reported and retried after the next change.
+func (w *devWatcher) run(ctx context.Context) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	var changed bool
+	var lastChange time.Time
+	for {
+		var now time.Time
+		select {
+		case <-ctx.Done():
+			return nil
+		case now = <-ticker.C:
+		}
+
+		current, err := scanGoFiles(w.dir)
+		if err != nil {
+			return err
+		}
+		if !maps.Equal(current, w.stamps) {
+			w.stamps, changed, lastChange = current, true, now
+			continue
+		}
+		if !changed || now.Sub(lastChange) < w.debounce {
+			continue
+		}
+		changed = false
+
+		var added []migration.DiscoveredMigration
+		for _, m := range w.discover(w.stamps) {
+			if !w.known[m.Version] {
+				added = append(added, m)
+			}
+		}
+		if len(added) == 0 {
+			continue
+		}
+		slices.SortFunc(added, func(a, b migration.DiscoveredMigration) int { return strings.Compare(a.Version, b.Version) })
+		for _, m := range added {
+			fmt.Fprintf(w.out, "%s New migration %s (%s)\n", ui.Created, m.Version, filepath.Base(m.File))
+		}
+		if err := w.apply(ctx, added); err != nil {
+			fmt.Fprintf(w.out, "%s Applying new migrations failed: %v\n", ui.Fail, err)
+			continue
+		}
+		for _, m := range added {
+			w.known[m.Version] = true
+		}
+		fmt.Fprintf(w.out, "%s Applied %d new migration(s).\n", ui.Done, len(added))
+	}
+}
+
+// discover parses each file on its own so one that does not parse, typically because it
+// is still being written, is reported and skipped instead of stopping the watch.
+func (w *devWatcher) discover(stamps map[string]fileStamp) []migration.DiscoveredMigration {
+	var found []migration.DiscoveredMigration
+	for path := range stamps {
+		inFile, err := migration.DiscoverFile(path)
+		if err != nil {
+			fmt.Fprintf(w.out, "%s Skipping %s: %v\n", ui.Warn, filepath.Base(path), err)
+			continue
+		}
+		found = append(found, inFile...)
+	}
+	return found
+}
+
+// scanGoFiles stamps the non-test .go files in dir, the files DiscoverMigrations reads.
+func scanGoFiles(dir string) (map[string]fileStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	stamps := make(map[string]fileStamp, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		stamps[filepath.Join(dir, name)] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	return stamps, nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+// syncBuffer lets the test read what the watcher goroutine writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func writeMigrationFile(t *testing.T, dir, version string) {
+	t.Helper()
+	src := fmt.Sprintf("package migrations\n\ntype M%[1]s struct{}\n\n"+
+		"func (m *M%[1]s) Version() string { return %[1]q }\n", version)
+	if err := os.WriteFile(filepath.Join(dir, version+".go"), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevWatcherAppliesAddedMigrations(t *testing.T) {
+	dir := t.TempDir()
+	writeMigrationFile(t, dir, "20240101_000001")
+
+	applied := make(chan []string, 4)
+	var out syncBuffer
+	w := &devWatcher{
+		dir:      dir,
+		interval: 5 * time.Millisecond,
+		debounce: 20 * time.Millisecond,
+		out:      &out,
+		apply: func(_ context.Context, added []migration.DiscoveredMigration) error {
+			versions := make([]string, len(added))
+			for i, m := range added {
+				versions[i] = m.Version
+			}
+			applied <- versions
+			return nil
+		},
+	}
+
+	if err := w.init(); err != nil {
+		t.Fatalf("init() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.run(ctx) }()
+
+	next := func() []string {
+		t.Helper()
+		select {
+		case versions := <-applied:
+			return versions
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no migrations applied; output:\n%s", out.String())
+			return nil
+		}
+	}
+
+	writeMigrationFile(t, dir, "20240102_000001")
+	if got := next(); strings.Join(got, ",") != "20240102_000001" {
+		t.Errorf("applied %v, want only the added migration", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.go"), []byte("package migrations\n\nfunc ("), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeMigrationFile(t, dir, "20240103_000001")
+	if got := next(); strings.Join(got, ",") != "20240103_000001" {
+		t.Errorf("applied %v, want only the migration that parses", got)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("run() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Skipping broken.go") {
+		t.Errorf("expected a warning for the file that does not parse:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "New migration 20240103_000001") {
+		t.Errorf("expected each new migration to be logged:\n%s", out.String())
+	}
+}
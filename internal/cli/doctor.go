@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newDoctorCmd() *cobra.Command {
+	var path string
+
+	cmd := &cobra.Command{
+		Use:         "doctor",
+		Short:       "Find migration files on disk that are not registered",
+		Annotations: map[string]string{annotationOffline: "true"},
+		Long: "Scans the migrations directory for types with a Version method and compares them with " +
+			"the registered migrations. A migration that exists on disk but is not registered usually " +
+			"means its package is not imported by the binary.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if path == "" {
+				cfg, err := getConfig(cmd.Context())
+				if err != nil {
+					return err
+				}
+				path = cfg.MigrationsPath
+			}
+			if path == "" {
+				return fmt.Errorf("no migrations path configured; set MIGRATIONS_PATH or pass --path")
+			}
+
+			missing, err := diagnoseMigrations(cmd.OutOrStdout(), path, migration.RegisteredMigrations())
+			if err != nil {
+				return err
+			}
+			if missing > 0 {
+				return fmt.Errorf("%d migration(s) in %s are not registered", missing, path)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&path, "path", "", "Directory to scan (defaults to MIGRATIONS_PATH)")
+	return cmd
+}
+
+// diagnoseMigrations reports every migration found in dir that is missing from registered
+// and returns how many there were.
+func diagnoseMigrations(out io.Writer, dir string, registered map[string]migration.Migration) (int, error) {
+	found, err := migration.DiscoverMigrations(dir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan %s: %w", dir, err)
+	}
+
+	missing := 0
+	for _, d := range found {
+		if _, ok := registered[d.Version]; ok {
+			continue
+		}
+		missing++
+		fmt.Fprintf(out, "%s %s (%s in %s) is not registered; is its package imported with a blank import?\n",
+			ui.Warn, d.Version, d.Type, d.File)
+	}
+
+	if missing == 0 {
+		fmt.Fprintf(out, "%s %d migration file(s) found, all registered.\n", ui.OK, len(found))
+	}
+	return missing, nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type doctorMigration struct{ version string }
+
+func (m doctorMigration) Version() string                             { return m.version }
+func (m doctorMigration) Description() string                         { return "doctor" }
+func (m doctorMigration) Up(context.Context, *mongo.Database) error   { return nil }
+func (m doctorMigration) Down(context.Context, *mongo.Database) error { return nil }
+
+func TestDiagnoseMigrations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"20240101_000001_registered.go": "package migrations\n\ntype Registered struct{}\n\n" +
+			"func (m *Registered) Version() string { return \"20240101_000001\" }\n",
+		"20240101_000002_forgotten.go": "package migrations\n\ntype Forgotten struct{}\n\n" +
+			"func (m *Forgotten) Version() string { return \"20240101_000002\" }\n",
+		"helpers_test.go": "package migrations\n\ntype T struct{}\n\n" +
+			"func (T) Version() string { return \"ignored\" }\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registered := map[string]migration.Migration{"20240101_000001": doctorMigration{version: "20240101_000001"}}
+
+	var out bytes.Buffer
+	missing, err := diagnoseMigrations(&out, dir, registered)
+	if err != nil {
+		t.Fatalf("diagnoseMigrations: %v", err)
+	}
+	if missing != 1 {
+		t.Fatalf("missing = %d, want 1\n%s", missing, out.String())
+	}
+	if !strings.Contains(out.String(), "20240101_000002 (Forgotten") {
+		t.Errorf("output does not name the unregistered migration:\n%s", out.String())
+	}
+	if strings.Contains(out.String(), "20240101_000001 ") || strings.Contains(out.String(), "ignored") {
+		t.Errorf("output reports a registered or test-only migration:\n%s", out.String())
+	}
+}
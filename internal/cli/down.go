@@ -1,35 +1,67 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"strings"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
 func newDownCmd() *cobra.Command {
 	var (
-		target  string
-		confirm bool
-		dryRun  bool
+		target      string
+		confirm     bool
+		dryRun      bool
+		interactive bool
+		selected    []string
+		confirmDB   string
+		ignoreFloor bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "down",
-		Short: "Roll back migrations",
-		Long:  "Roll back applied migrations in reverse order. Use --target to stop before a specific version.",
+		Use:         "down",
+		Short:       "Roll back migrations",
+		Annotations: map[string]string{annotationMutating: "true", annotationPreview: "dry-run"},
+		Long:        "Roll back applied migrations in reverse order. Use --target to stop before a specific version.",
 		Example: `  mt down --target 20240101_001
-  mt down --yes  # Rollback ALL migrations without prompting`,
+  mt down --confirm orders  # Rollback ALL migrations in the orders database without prompting`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			engine, err := getEngine(cmd.Context())
+			if ignoreFloor {
+				engine, err = engineWith(cmd, func(cfg *config.Config) {
+					if cfg.FloorVersion != "" {
+						zap.S().Warnw("Ignoring the floor version", "floor", cfg.FloorVersion)
+						cfg.FloorVersion = ""
+					}
+				})
+			}
 			if err != nil {
 				return err
 			}
-			plan, err := engine.Plan(cmd.Context(), migration.DirectionDown, target)
+			if len(selected) > 0 && target != "" {
+				return fmt.Errorf("--select cannot be combined with --target")
+			}
+			var filter migration.MigrationFilter
+			if len(selected) > 0 {
+				filter = migration.VersionFilter(selected...)
+			}
+			plan, err := engine.PlanFiltered(cmd.Context(), migration.DirectionDown, target, filter)
 			if err != nil {
 				return err
 			}
+			floor, err := engine.FloorStop(cmd.Context(), target, filter)
+			if err != nil {
+				return err
+			}
+			if floor != "" {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Stopping above floor version %s (--ignore-floor rolls back past it).\n",
+					floor)
+			}
 
 			if dryRun {
 				renderPlan(cmd.OutOrStdout(), "down", plan)
@@ -40,18 +72,51 @@ func newDownCmd() *cobra.Command {
 				return nil
 			}
 
-			msg := "WARNING: You are about to roll back ALL migrations. Continue? [y/N]: "
-			if target != "" {
-				msg = fmt.Sprintf("WARNING: Rolling back migrations down to version %s. Continue? [y/N]: ", target)
+			// Rolling back everything needs the database name, with or without --interactive.
+			rollsBackAll := target == "" && len(selected) == 0
+			if rollsBackAll {
+				cfg, err := getConfig(cmd.Context())
+				if err != nil {
+					return err
+				}
+				if err := confirmDatabase(cmd, cfg.Database, confirmDB, confirm, "roll back ALL migrations"); err != nil {
+					return err
+				}
 			}
 
-			if !confirm && !promptConfirmation(cmd, msg) {
-				fmt.Fprintln(cmd.OutOrStdout(), "Operation cancelled.")
+			if interactive {
+				rollback := func(ctx context.Context, version string) error { return engine.Down(ctx, version) }
+				if len(selected) > 0 {
+					rollback = func(ctx context.Context, version string) error {
+						return engine.DownSelected(ctx, []string{version})
+					}
+				}
+				rolledBack, err := rollbackInteractively(cmd, plan, rollback)
+				if err != nil {
+					return fmt.Errorf("%s: %w", ErrFailedToDown, err)
+				}
+				zap.S().Infow("Interactive rollback finished", "rolled_back", rolledBack, "planned", len(plan))
 				return nil
 			}
 
-			zap.S().Infow("Starting migration rollback", "target", target)
-			if err := engine.Down(cmd.Context(), target); err != nil {
+			if !rollsBackAll {
+				msg := fmt.Sprintf("WARNING: Rolling back migrations down to version %s. Continue? [y/N]: ", target)
+				if len(selected) > 0 {
+					msg = fmt.Sprintf("WARNING: Rolling back %s out of sequence. Continue? [y/N]: ", strings.Join(plan, ", "))
+				}
+				if !confirm && !promptConfirmation(cmd, msg) {
+					fmt.Fprintln(cmd.OutOrStdout(), "Operation cancelled.")
+					return nil
+				}
+			}
+
+			zap.S().Infow("Starting migration rollback", "target", target, "selected", selected)
+			if len(selected) > 0 {
+				err = engine.DownSelected(cmd.Context(), selected)
+			} else {
+				err = engine.Down(cmd.Context(), target)
+			}
+			if err != nil {
 				return fmt.Errorf("%s: %w", ErrFailedToDown, err)
 			}
 
@@ -61,8 +126,38 @@ func newDownCmd() *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&target, "target", "t", "", "Version to roll back to (exclusive)")
-	cmd.Flags().BoolVarP(&confirm, "yes", "y", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVarP(&confirm, "yes", "y", false,
+		"Skip the confirmation prompt; rolling back all migrations still needs --confirm <db>")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print planned rollbacks without executing")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false,
+		"Confirm each rollback individually; answering no stops the remaining rollbacks")
+	cmd.Flags().StringSliceVar(&selected, "select", nil,
+		"Roll back only these applied versions, newest first, leaving newer ones applied")
+	cmd.Flags().BoolVar(&ignoreFloor, "ignore-floor", false,
+		"Allow rolling back MIGRATIONS_FLOOR_VERSION and older migrations")
+	cmd.Flags().StringVar(&confirmDB, "confirm", "",
+		"Database name, required verbatim to roll back all migrations without the typed prompt")
 
 	return cmd
 }
+
+// rollbackInteractively asks before each version in plan and rolls it back on yes. The
+// first no stops the batch. Every rollback is a separate engine run, so the migrations
+// rolled back before stopping are fully recorded and the rest stay applied.
+func rollbackInteractively(
+	cmd *cobra.Command, plan []string, rollback func(context.Context, string) error,
+) (int, error) {
+	out := cmd.OutOrStdout()
+	for i, version := range plan {
+		msg := fmt.Sprintf("Roll back %s (%d/%d)? [y/N]: ", version, i+1, len(plan))
+		if !promptConfirmation(cmd, msg) {
+			fmt.Fprintf(out, "Stopped before %s; %d of %d migrations rolled back.\n", version, i, len(plan))
+			return i, nil
+		}
+		if err := rollback(cmd.Context(), version); err != nil {
+			return i, err
+		}
+		fmt.Fprintf(out, "%s Rolled back %s\n", ui.RolledBack, version)
+	}
+	return len(plan), nil
+}
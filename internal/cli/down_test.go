@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRollbackInteractively(t *testing.T) {
+	plan := []string{"20240103_001", "20240102_001", "20240101_001"}
+
+	tests := []struct {
+		name    string
+		input   string
+		fail    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "Stops at the first no", input: "y\nn\n", want: []string{"20240103_001"}},
+		{name: "All confirmed", input: "y\nyes\nY\n", want: plan},
+		{name: "Input ends", input: "y\n", want: []string{"20240103_001"}},
+		{name: "Rollback error stops the batch", input: "y\ny\ny\n", fail: "20240102_001", wantErr: true,
+			want: []string{"20240103_001", "20240102_001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran []string
+			rollback := func(_ context.Context, version string) error {
+				ran = append(ran, version)
+				if version == tt.fail {
+					return errors.New("boom")
+				}
+				return nil
+			}
+
+			var out bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetOut(&out)
+			cmd.SetContext(context.Background())
+
+			_, err := rollbackInteractively(cmd, plan, rollback)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rollbackInteractively() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(ran, tt.want) {
+				t.Errorf("rolled back %v, want %v", ran, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmDatabase(t *testing.T) {
+	tests := []struct {
+		name      string
+		token     string
+		input     string
+		assumeYes bool
+		wantErr   bool
+	}{
+		{name: "Exact token proceeds", token: "orders"},
+		{name: "Mismatched token aborts", token: "order", wantErr: true},
+		{name: "Token is case sensitive", token: "Orders", wantErr: true},
+		{name: "Mismatched token aborts despite yes", token: "orders_dev", assumeYes: true, wantErr: true},
+		{name: "Yes alone does not confirm", assumeYes: true, input: "orders\n", wantErr: true},
+		{name: "Typed name proceeds", input: "orders\n"},
+		{name: "Typed y aborts", input: "y\n", wantErr: true},
+		{name: "No input aborts", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			cmd := &cobra.Command{}
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetOut(&out)
+
+			err := confirmDatabase(cmd, "orders", tt.token, tt.assumeYes, "roll back ALL migrations")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("confirmDatabase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrDatabaseNotConfirmed) {
+				t.Errorf("error = %v, want ErrDatabaseNotConfirmed", err)
+			}
+		})
+	}
+}
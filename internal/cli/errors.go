@@ -1,5 +1,11 @@
 package cli
 
+import (
+	"errors"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
 type ErrorCli string
 
 func (e ErrorCli) Error() string {
@@ -17,4 +23,43 @@ const (
 	ErrFailedToDown        = ErrorCli("failed to run down migration")
 	ErrFailedToForce       = ErrorCli("failed to force migration")
 	ErrInvalidForceVersion = ErrorCli("invalid force version")
+
+	ErrProductionNotConfirmed = ErrorCli("refusing to modify a production database without confirmation")
+	ErrDirtyState             = ErrorCli("applied migrations do not match their records")
+	ErrChecksumDrift          = ErrorCli("applied migration checksums drifted from the code")
+	ErrDatabaseNotConfirmed   = ErrorCli("confirmation does not match the database name")
+	ErrSecondaryReadOnly      = ErrorCli("refusing to run a mutating command while reading from a secondary")
+	ErrUnknownMCPClient       = ErrorCli("unknown MCP client")
+	ErrPermissionDenied       = ErrorCli("the configured user is missing permissions")
+	ErrInvalidChecksumArg     = ErrorCli("invalid --expect-checksum value")
+
+	ErrOutsideMaintenanceWindow = ErrorCli("refusing to modify the database outside the maintenance window")
+	ErrSelfTestFailed           = ErrorCli("self-test failed")
+	ErrFailedToCompact          = ErrorCli("failed to compact migration history")
+
+	ErrOplogOnMongos = ErrorCli("connected to a mongos, which has no oplog; " +
+		"connect to a shard member directly or pass --shard-uri")
+	ErrOplogNotFound = ErrorCli("oplog collection not found (requires a replica set member)")
 )
+
+// Hint suggests what to do about errors the user can act on, or returns "" otherwise.
+func Hint(err error) string {
+	switch {
+	case errors.Is(err, migration.ErrLockHeld):
+		return "Another run holds the migration lock. Wait for it to finish, or run `unlock` if it crashed."
+	case errors.Is(err, migration.ErrChecksumMismatch):
+		return "An applied migration changed after it ran. Restore its code, " +
+			"or compare `status --strict-checksum` with `opslog` to find it."
+	case errors.Is(err, migration.ErrUnexpectedChecksum):
+		return "This binary's migrations differ from the reviewed ones. Rebuild from the reviewed commit, " +
+			"or compare with `catalog --output json`."
+	case errors.Is(err, migration.ErrMigrationNotFound):
+		return "No registered migration has that version; `catalog` lists the registered versions."
+	case errors.Is(err, migration.ErrOrphanedRecords):
+		return "Restore the deleted migration file (`doctor` finds files missing an import), " +
+			"or drop the stale records with `admin compact`."
+	case errors.Is(err, migration.ErrBelowFloor):
+		return "MIGRATIONS_FLOOR_VERSION keeps that version applied; pass --ignore-floor to roll back past it."
+	}
+	return ""
+}
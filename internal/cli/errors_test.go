@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "lock held", err: fmt.Errorf("%w: %w", migration.ErrFailedToLock, migration.ErrLockHeld), want: "unlock"},
+		{
+			name: "checksum mismatch",
+			err:  fmt.Errorf("%w for 20240101_001: expected a, got b", migration.ErrChecksumMismatch),
+			want: "--strict-checksum",
+		},
+		{
+			name: "unexpected checksum",
+			err:  fmt.Errorf("%w for 20240101_001: expected a, got b", migration.ErrUnexpectedChecksum),
+			want: "reviewed commit",
+		},
+		{name: "not found", err: fmt.Errorf("%w: 20240101_999", migration.ErrMigrationNotFound), want: "catalog"},
+		{name: "orphaned", err: fmt.Errorf("%w: 20240101_999", migration.ErrOrphanedRecords), want: "doctor"},
+		{name: "below floor", err: fmt.Errorf("%w 20240101_001", migration.ErrBelowFloor), want: "--ignore-floor"},
+		{name: "other", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hint(tt.err)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("Hint() = %q, want none", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("Hint() = %q, want it to mention %q", got, tt.want)
+			}
+		})
+	}
+}
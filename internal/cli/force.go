@@ -1,22 +1,48 @@
 package cli
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
 	"strings"
+	"time"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
 func newForceCmd() *cobra.Command {
-	var assumeYes bool
+	var (
+		assumeYes bool
+		fromFile  string
+		dryRun    bool
+	)
 
 	cmd := &cobra.Command{
-		Use:   "force [version]",
-		Short: "Force mark a migration as applied without running it",
-		Args:  cobra.ExactArgs(1),
+		Use:         "force [version]",
+		Short:       "Force mark a migration as applied without running it",
+		Annotations: map[string]string{annotationMutating: "true", annotationPreview: "dry-run"},
+		Example: `  mt force 20240101_001
+  mt force --from-file versions.txt --dry-run`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if fromFile != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromFile != "" {
+				return runForceFromFile(cmd, fromFile, assumeYes, dryRun)
+			}
+
 			version := args[0]
+			if dryRun {
+				renderPlan(cmd.OutOrStdout(), "force", []string{version})
+				return nil
+			}
 
 			if !assumeYes && !confirmForce(cmd, version) {
 				zap.S().Info("Operation cancelled")
@@ -28,19 +54,94 @@ func newForceCmd() *cobra.Command {
 				return err
 			}
 
-			if err := engine.Force(cmd.Context(), version); err != nil {
+			res, err := engine.ForceWithResult(cmd.Context(), version)
+			if err != nil {
 				return fmt.Errorf("%s: %w", ErrFailedToForce, err)
 			}
 
-			zap.S().Infow("Migration force marked successfully", "version", version)
+			fmt.Fprintln(cmd.OutOrStdout(), forceOutcome(res))
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Confirm without prompting")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read newline-separated versions to force from a file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print versions that would be forced without writing")
 	return cmd
 }
 
+func runForceFromFile(cmd *cobra.Command, path string, assumeYes, dryRun bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToForce, err)
+	}
+	defer f.Close()
+
+	versions, err := readVersionList(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToForce, err)
+	}
+
+	out := cmd.OutOrStdout()
+	if dryRun || len(versions) == 0 {
+		renderPlan(out, "force", versions)
+		return nil
+	}
+
+	if !assumeYes && !confirmForce(cmd, fmt.Sprintf("%d versions from %s", len(versions), path)) {
+		zap.S().Info("Operation cancelled")
+		return nil
+	}
+
+	engine, err := getEngine(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	results, batchErr := engine.ForceBatch(cmd.Context(), versions)
+
+	failed := 0
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+			fmt.Fprintf(out, "%s %s: %v\n", ui.Fail, res.Version, res.Err)
+			continue
+		}
+		fmt.Fprintf(out, "%s %s\n", ui.OK, forceOutcome(res))
+	}
+
+	if batchErr != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToForce, batchErr)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%s: %d of %d versions failed", ErrFailedToForce, failed, len(results))
+	}
+	return nil
+}
+
+// forceOutcome says whether force created the record or found one already there.
+func forceOutcome(res migration.ForceResult) string {
+	if res.AlreadyApplied {
+		return fmt.Sprintf("%s: marked (was already applied at %s)",
+			res.Version, res.PreviousAppliedAt.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s: newly marked", res.Version)
+}
+
+// readVersionList returns one version per non-empty line, ignoring lines starting with #.
+func readVersionList(r io.Reader) ([]string, error) {
+	var versions []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		versions = append(versions, line)
+	}
+	return versions, scanner.Err()
+}
+
 func confirmForce(cmd *cobra.Command, version string) bool {
 	fmt.Fprintf(cmd.OutOrStdout(), "WARNING: Force marking %s will NOT execute migration logic.\n", version)
 	fmt.Fprint(cmd.OutOrStdout(), "Confirm action? (y/N): ")
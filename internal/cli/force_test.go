@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestReadVersionList(t *testing.T) {
+	input := `# recovered from backup
+20240101_001
+
+  20240102_001  
+unknown_version
+# trailing comment
+`
+	got, err := readVersionList(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readVersionList() error = %v", err)
+	}
+
+	want := []string{"20240101_001", "20240102_001", "unknown_version"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestForceOutcome(t *testing.T) {
+	fresh := forceOutcome(migration.ForceResult{Version: "20240101_001"})
+	if fresh != "20240101_001: newly marked" {
+		t.Errorf("forceOutcome() = %q", fresh)
+	}
+
+	applied := forceOutcome(migration.ForceResult{
+		Version:           "20240101_001",
+		AlreadyApplied:    true,
+		PreviousAppliedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	if applied != "20240101_001: marked (was already applied at 2024-01-02T03:04:05Z)" {
+		t.Errorf("forceOutcome() = %q", applied)
+	}
+}
@@ -29,16 +29,27 @@ func NewMCPCmd() *cobra.Command {
 	}
 	mcpCmd.Flags().BoolVar(&withExamples, "with-examples", false, "Register example migrations on startup")
 
-	mcpCmd.AddCommand(&cobra.Command{
+	mcpCmd.AddCommand(newMCPConfigCmd())
+
+	return mcpCmd
+}
+
+func newMCPConfigCmd() *cobra.Command {
+	var client string
+	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Generate MCP configuration JSON",
+		Long: "Prints the MCP server entry for a client's configuration file. The env block uses " +
+			"MONGO_URL, MONGO_DATABASE and MIGRATIONS_COLLECTION from the current environment.",
 		Annotations: map[string]string{
 			annotationOffline: "true",
 		},
-		RunE: runMCPConfig,
-	})
-
-	return mcpCmd
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runMCPConfig(cmd, client)
+		},
+	}
+	cmd.Flags().StringVar(&client, "client", mcpClientClaude, "MCP client to configure: claude, cursor or goose")
+	return cmd
 }
 
 func runMCP(cmd *cobra.Command, withExamples bool) error {
@@ -79,7 +90,15 @@ func runMCP(cmd *cobra.Command, withExamples bool) error {
 	return nil
 }
 
-func runMCPConfig(cmd *cobra.Command, _ []string) error {
+const (
+	mcpClientClaude = "claude"
+	mcpClientCursor = "cursor"
+	mcpClientGoose  = "goose"
+
+	mcpServerName = "mt"
+)
+
+func runMCPConfig(cmd *cobra.Command, client string) error {
 	exePath, err := os.Executable()
 	if err != nil {
 		return fmt.Errorf("could not determine path: %w", err)
@@ -91,18 +110,15 @@ func runMCPConfig(cmd *cobra.Command, _ []string) error {
 		}
 		return fallback
 	}
+	env := map[string]string{
+		"MONGO_URL":             getEnv("MONGO_URL", "mongodb://localhost:27017"),
+		"MONGO_DATABASE":        getEnv("MONGO_DATABASE", "your_database"),
+		"MIGRATIONS_COLLECTION": getEnv("MIGRATIONS_COLLECTION", "schema_migrations"),
+	}
 
-	config := map[string]any{
-		"mcpServers": map[string]any{
-			"mt": map[string]any{
-				"command": exePath,
-				"args":    []string{"mcp"},
-				"env": map[string]string{
-					"MONGO_URI":      getEnv("MONGO_URI", "mongodb://localhost:27017"),
-					"MONGO_DATABASE": getEnv("MONGO_DATABASE", "your_database"),
-				},
-			},
-		},
+	config, err := mcpClientConfig(client, exePath, env)
+	if err != nil {
+		return err
 	}
 
 	enc := jsonutil.NewEncoder(cmd.OutOrStdout())
@@ -110,6 +126,40 @@ func runMCPConfig(cmd *cobra.Command, _ []string) error {
 	return enc.Encode(config)
 }
 
+// mcpClientConfig shapes the server entry the way each client's configuration file
+// expects it. Claude Desktop and Cursor share mcpServers; Goose lists extensions.
+func mcpClientConfig(client, command string, env map[string]string) (map[string]any, error) {
+	args := []string{"mcp"}
+	switch strings.ToLower(client) {
+	case mcpClientClaude:
+		return map[string]any{
+			"mcpServers": map[string]any{
+				mcpServerName: map[string]any{"command": command, "args": args, "env": env},
+			},
+		}, nil
+	case mcpClientCursor:
+		return map[string]any{
+			"mcpServers": map[string]any{
+				mcpServerName: map[string]any{"type": "stdio", "command": command, "args": args, "env": env},
+			},
+		}, nil
+	case mcpClientGoose:
+		return map[string]any{
+			"extensions": map[string]any{
+				mcpServerName: map[string]any{
+					"name":    mcpServerName,
+					"type":    "stdio",
+					"cmd":     command,
+					"args":    args,
+					"envs":    env,
+					"enabled": true,
+				},
+			},
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %q (want claude, cursor or goose)", ErrUnknownMCPClient, client)
+}
+
 func isClosingError(err error) bool {
 	return errors.Is(err, io.EOF) ||
 		errors.Is(err, io.ErrClosedPipe) ||
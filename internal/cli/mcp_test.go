@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+)
+
+func TestMCPConfigClients(t *testing.T) {
+	t.Setenv("MONGO_URL", "mongodb://db.example.com:27017")
+	t.Setenv("MONGO_DATABASE", "orders")
+	t.Setenv("MIGRATIONS_COLLECTION", "")
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		client     string
+		root       string
+		commandKey string
+		envKey     string
+	}{
+		{client: "claude", root: "mcpServers", commandKey: "command", envKey: "env"},
+		{client: "cursor", root: "mcpServers", commandKey: "command", envKey: "env"},
+		{client: "goose", root: "extensions", commandKey: "cmd", envKey: "envs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.client, func(t *testing.T) {
+			cmd := newMCPConfigCmd()
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"--client", tt.client})
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("mcp config error = %v", err)
+			}
+
+			var got map[string]map[string]struct {
+				Command string            `json:"command"`
+				Cmd     string            `json:"cmd"`
+				Args    []string          `json:"args"`
+				Env     map[string]string `json:"env"`
+				Envs    map[string]string `json:"envs"`
+			}
+			if err := jsonutil.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("config is not valid JSON: %v\n%s", err, out.String())
+			}
+			server, ok := got[tt.root][mcpServerName]
+			if !ok {
+				t.Fatalf("missing %s.%s in %s", tt.root, mcpServerName, out.String())
+			}
+
+			command, env := server.Command, server.Env
+			if tt.commandKey == "cmd" {
+				command, env = server.Cmd, server.Envs
+			}
+			if command != exe {
+				t.Errorf("%s = %q, want %q", tt.commandKey, command, exe)
+			}
+			if len(server.Args) != 1 || server.Args[0] != "mcp" {
+				t.Errorf("args = %v, want [mcp]", server.Args)
+			}
+			want := map[string]string{
+				"MONGO_URL":             "mongodb://db.example.com:27017",
+				"MONGO_DATABASE":        "orders",
+				"MIGRATIONS_COLLECTION": "schema_migrations",
+			}
+			for k, v := range want {
+				if env[k] != v {
+					t.Errorf("%s[%s] = %q, want %q", tt.envKey, k, env[k], v)
+				}
+			}
+			if _, ok := env["MONGO_URI"]; ok {
+				t.Errorf("%s still contains MONGO_URI, which config.Load does not read", tt.envKey)
+			}
+		})
+	}
+}
+
+func TestMCPConfigUnknownClient(t *testing.T) {
+	if _, err := mcpClientConfig("vim", "/bin/mt", nil); !errors.Is(err, ErrUnknownMCPClient) {
+		t.Errorf("mcpClientConfig() error = %v, want %v", err, ErrUnknownMCPClient)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"text/tabwriter"
 	"time"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/dbconn"
 	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
 	"github.com/spf13/cobra"
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -37,6 +38,7 @@ type oplogConfig struct {
 	follow     bool
 	fullDoc    bool
 	resumeFile string
+	shardURI   string
 }
 
 type oplogEntry struct {
@@ -91,16 +93,17 @@ func NewOplogCmd() *cobra.Command {
 		Use:   "oplog",
 		Short: "Query MongoDB oplog entries",
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			s, err := getServices(cmd.Context())
-			if err != nil || s.MongoClient == nil {
-				return fmt.Errorf("mongo client unavailable")
+			client, done, err := oplogClient(cmd.Context(), cfg.shardURI)
+			if err != nil {
+				return err
 			}
-			return runOplog(cmd.Context(), cmd.OutOrStdout(), s.MongoClient, cfg)
+			defer done()
+			return runOplog(cmd.Context(), cmd.OutOrStdout(), client, cfg)
 		},
 	}
 
 	f := cmd.Flags()
-	f.StringVarP(&cfg.output, "output", "o", "table", "Output format (table, json)")
+	f.StringVarP(&cfg.output, "output", "o", "table", "Output format (table, json, jsonl)")
 	f.StringVar(&cfg.namespace, "namespace", "", "Filter by exact namespace (db.collection)")
 	f.StringVar(&cfg.regex, "regex", "", "Filter by namespace regex")
 	f.StringVar(&cfg.ops, "ops", "", "Filter by op codes/names (i,u,d or insert,update)")
@@ -111,6 +114,9 @@ func NewOplogCmd() *cobra.Command {
 	f.BoolVar(&cfg.follow, "follow", false, "Tail entries in real-time")
 	f.BoolVar(&cfg.fullDoc, "full-document", false, "Include full document on updates")
 	f.StringVar(&cfg.resumeFile, "resume-file", "", "File to store/read the resume token for persistent tailing")
+	f.StringVar(&cfg.shardURI, "shard-uri", "", "Read the oplog of this shard instead of the configured connection")
+
+	cmd.AddCommand(newOplogExportCmd())
 	return cmd
 }
 
@@ -123,30 +129,7 @@ func runOplog(ctx context.Context, w io.Writer, client *mongo.Client, cfg oplogC
 	}
 
 	render := func(entries []oplogEntry) error {
-		if strings.ToLower(cfg.output) == "json" {
-			out := make([]oplogOutput, len(entries))
-			for i, e := range entries {
-				out[i] = e.ToOutput()
-			}
-			enc := jsonutil.NewEncoder(w)
-			enc.SetIndent("", "  ")
-			return enc.Encode(out)
-		}
-
-		tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
-		if len(entries) > 0 {
-			fmt.Fprintln(tw, "TIME\tOPERATION\tNS\tOBJECT ID")
-		}
-		for _, e := range entries {
-			o := e.ToOutput()
-			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
-				o.Timestamp.Format("2006-01-02 15:04:05"),
-				o.Operation,
-				o.Namespace,
-				o.ObjectID,
-			)
-		}
-		return tw.Flush()
+		return renderOplogEntries(w, cfg.output, entries)
 	}
 
 	if cfg.follow {
@@ -165,6 +148,56 @@ func runOplog(ctx context.Context, w io.Writer, client *mongo.Client, cfg oplogC
 	return render(entries)
 }
 
+func renderOplogEntries(w io.Writer, format string, entries []oplogEntry) error {
+	switch strings.ToLower(format) {
+	case "json":
+		out := make([]oplogOutput, len(entries))
+		for i, e := range entries {
+			out[i] = e.ToOutput()
+		}
+		enc := jsonutil.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	case "jsonl":
+		return renderOplogJSONL(w, entries)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	if len(entries) > 0 {
+		fmt.Fprintln(tw, "TIME\tOPERATION\tNS\tOBJECT ID")
+	}
+	for _, e := range entries {
+		o := e.ToOutput()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			o.Timestamp.Format("2006-01-02 15:04:05"),
+			o.Operation,
+			o.Namespace,
+			o.ObjectID,
+		)
+	}
+	return tw.Flush()
+}
+
+// renderOplogJSONL writes one compact JSON object per line. Each line goes out in a single
+// Write and is flushed right away, so a downstream processor sees complete events as they arrive.
+func renderOplogJSONL(w io.Writer, entries []oplogEntry) error {
+	for _, e := range entries {
+		line, err := jsonutil.Marshal(e.ToOutput())
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		if f, ok := w.(interface{ Flush() error }); ok {
+			if err := f.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func buildFilter(cfg oplogConfig) (bson.D, error) {
 	filter := bson.D{}
 	add := func(k string, v interface{}) { filter = append(filter, bson.E{Key: k, Value: v}) }
@@ -216,7 +249,7 @@ func buildFilter(cfg oplogConfig) (bson.D, error) {
 }
 
 func fetchOplog(ctx context.Context, client *mongo.Client, filter bson.D, limit int64) ([]oplogEntry, error) {
-	coll, err := oplogCollection(client)
+	coll, err := oplogCollection(ctx, client)
 	if err != nil {
 		return nil, err
 	}
@@ -423,19 +456,65 @@ func parseTime(v string) (bson.Timestamp, error) {
 	return bson.Timestamp{}, fmt.Errorf("invalid time: %s", v)
 }
 
-func oplogCollection(client *mongo.Client) (*mongo.Collection, error) {
+// oplogClient returns the configured client, or a separate connection to shardURI when
+// set, since a mongos cannot serve the oplog. done releases the separate connection.
+func oplogClient(ctx context.Context, shardURI string) (*mongo.Client, func(), error) {
+	s, err := getServices(ctx)
+	if err != nil || s.MongoClient == nil {
+		return nil, nil, fmt.Errorf("mongo client unavailable")
+	}
+	if shardURI == "" {
+		return s.MongoClient, func() {}, nil
+	}
+
+	client, err := dbconn.ConnectWithRetry(ctx,
+		dbconn.WithAppName(options.Client().ApplyURI(shardURI)), dbconn.PolicyFromConfig(s.Config))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to shard: %w", err)
+	}
+	return client, func() { _ = client.Disconnect(context.Background()) }, nil
+}
+
+func oplogCollection(ctx context.Context, client *mongo.Client) (*mongo.Collection, error) {
+	var hello bson.M
+	if err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return nil, fmt.Errorf("failed to inspect server: %w", err)
+	}
+	if isMongos(hello) {
+		return nil, ErrOplogOnMongos
+	}
+
 	localDB := client.Database("local")
-	names, err := localDB.ListCollectionNames(context.Background(), bson.D{})
+	names, err := localDB.ListCollectionNames(ctx, bson.D{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to list local collections: %w", err)
 	}
+	name, err := oplogCollectionName(hello, names)
+	if err != nil {
+		return nil, err
+	}
+	return localDB.Collection(name), nil
+}
+
+// isMongos reports whether a hello reply came from a mongos router.
+func isMongos(hello bson.M) bool {
+	msg, _ := hello["msg"].(string)
+	return msg == "isdbgrid"
+}
+
+// oplogCollectionName picks the oplog from the local database's collections and explains
+// why there is none, which is otherwise an opaque error from the first query.
+func oplogCollectionName(hello bson.M, names []string) (string, error) {
+	if isMongos(hello) {
+		return "", ErrOplogOnMongos
+	}
 	for _, name := range names {
-		if name == "oplog.rs" {
-			return localDB.Collection("oplog.rs"), nil
-		}
-		if name == "oplog.$main" {
-			return localDB.Collection("oplog.$main"), nil
+		if name == "oplog.rs" || name == "oplog.$main" {
+			return name, nil
 		}
 	}
-	return nil, fmt.Errorf("oplog collection not found (requires replica set)")
+	if _, ok := hello["setName"]; !ok {
+		return "", fmt.Errorf("%w: server is a standalone, not a replica set member", ErrOplogNotFound)
+	}
+	return "", ErrOplogNotFound
 }
@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type flushRecorder struct {
+	bytes.Buffer
+	lines   []string
+	flushes int
+}
+
+func (f *flushRecorder) Flush() error {
+	f.flushes++
+	f.lines = append(f.lines, f.String())
+	return nil
+}
+
+func sampleOplogEntries() []oplogEntry {
+	wall := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []oplogEntry{
+		{
+			TS: bson.Timestamp{T: uint32(wall.Unix())}, Op: "i", NS: "app.users", Wall: &wall,
+			O: bson.M{"_id": "u1", "bio": "line one\nline two", "tags": bson.A{"a", "b"}},
+		},
+		{
+			TS: bson.Timestamp{T: uint32(wall.Unix())}, Op: "u", NS: "app.users",
+			O: bson.M{"$set": bson.M{"profile": bson.M{"age": 42}}}, O2: bson.M{"_id": "u1"},
+		},
+		{TS: bson.Timestamp{T: uint32(wall.Unix())}, Op: "d", NS: "app.sessions", O: bson.M{"_id": "s9"}},
+	}
+}
+
+func TestRenderOplogJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	entries := sampleOplogEntries()
+	if err := renderOplogEntries(&buf, "jsonl", entries); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != len(entries) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(entries), buf.String())
+	}
+	for i, line := range lines {
+		var got oplogOutput
+		if err := jsonutil.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("line %d is not a JSON object: %v\n%s", i, err, line)
+		}
+		want := entries[i].ToOutput()
+		if got.Operation != want.Operation || got.Namespace != want.Namespace || got.ObjectID != want.ObjectID {
+			t.Errorf("line %d = %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestRenderOplogJSONLFlushesEachEntry(t *testing.T) {
+	w := &flushRecorder{}
+	if err := renderOplogEntries(w, "jsonl", sampleOplogEntries()); err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if w.flushes != 3 {
+		t.Fatalf("flushes = %d, want 3", w.flushes)
+	}
+	for i, snapshot := range w.lines {
+		if n := strings.Count(snapshot, "\n"); n != i+1 {
+			t.Errorf("after flush %d output has %d complete lines, want %d", i+1, n, i+1)
+		}
+	}
+}
+
+func TestWriteOplogDumpRoundTrip(t *testing.T) {
+	entries := sampleOplogEntries()
+	docs := make([]any, len(entries))
+	for i := range entries {
+		docs[i] = entries[i]
+	}
+	cur, err := mongo.NewCursorFromDocuments(docs, nil, nil)
+	if err != nil {
+		t.Fatalf("cursor: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := writeOplogDump(context.Background(), cur, &buf)
+	if err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if n != int64(len(entries)) {
+		t.Fatalf("wrote %d entries, want %d", n, len(entries))
+	}
+
+	var got []oplogEntry
+	for data := buf.Bytes(); len(data) > 0; {
+		size := int(binary.LittleEndian.Uint32(data))
+		var e oplogEntry
+		if err := bson.Unmarshal(data[:size], &e); err != nil {
+			t.Fatalf("entry %d does not decode: %v", len(got), err)
+		}
+		got = append(got, e)
+		data = data[size:]
+	}
+
+	if len(got) != len(entries) {
+		t.Fatalf("decoded %d entries, want %d", len(got), len(entries))
+	}
+	for i := range entries {
+		if g, w := got[i].ToOutput(), entries[i].ToOutput(); g.Operation != w.Operation ||
+			g.Namespace != w.Namespace || g.ObjectID != w.ObjectID || !g.Timestamp.Equal(w.Timestamp) {
+			t.Errorf("entry %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestOplogCollectionName(t *testing.T) {
+	tests := []struct {
+		name    string
+		hello   bson.M
+		names   []string
+		want    string
+		wantErr error
+	}{
+		{name: "Replica set member", hello: bson.M{"setName": "rs0"}, names: []string{"startup_log", "oplog.rs"},
+			want: "oplog.rs"},
+		{name: "Mongos", hello: bson.M{"msg": "isdbgrid"}, wantErr: ErrOplogOnMongos},
+		{name: "Standalone", hello: bson.M{}, names: []string{"startup_log"}, wantErr: ErrOplogNotFound},
+		{name: "Member without oplog", hello: bson.M{"setName": "rs0"}, wantErr: ErrOplogNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oplogCollectionName(tt.hello, tt.names)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("oplogCollectionName() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("oplogCollectionName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	_, err := oplogCollectionName(bson.M{"msg": "isdbgrid"}, nil)
+	if !strings.Contains(err.Error(), "--shard-uri") {
+		t.Errorf("mongos error does not point at --shard-uri: %v", err)
+	}
+}
@@ -2,26 +2,35 @@ package cli
 
 import (
 	"fmt"
-	"io"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
-	"text/tabwriter"
 	"time"
 
-	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
 	"github.com/spf13/cobra"
 )
 
+// outputWide is the table with every record field, the same as --wide.
+const outputWide = "wide"
+
+// shortChecksumLen is how much of a checksum the compact table shows.
+const shortChecksumLen = 12
+
 func newOpslogCmd() *cobra.Command {
 	var (
-		output  string
-		search  string
-		version string
-		regex   string
-		from    string
-		to      string
-		limit   int
+		output     string
+		search     string
+		version    string
+		fromVer    string
+		regex      string
+		from       string
+		to         string
+		limit      int
+		noChecksum bool
+		wide       bool
 	)
 
 	cmd := &cobra.Command{
@@ -34,43 +43,47 @@ func newOpslogCmd() *cobra.Command {
 				return err
 			}
 
-			records, err := engine.ListApplied(cmd.Context())
+			options, err := buildOpslogFilter(search, version, regex, from, to)
 			if err != nil {
-				return fmt.Errorf("failed to read opslog: %w", err)
+				return err
 			}
 
-			options, err := buildOpslogFilter(search, version, regex, from, to)
+			// The time range is applied by the query; the other filters run on the result.
+			records, err := engine.ListAppliedBetween(cmd.Context(), options.from, options.to)
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to read opslog: %w", err)
 			}
 			records = filterOpslog(records, options)
+			records = atOrAfterVersion(records, recordVersion, fromVer, engine.CompareVersions)
 			if limit > 0 && len(records) > limit {
 				records = records[:limit]
 			}
 
-			out := cmd.OutOrStdout()
-			switch strings.ToLower(output) {
-			case "json":
-				return renderOpslogJSON(out, records)
-			case "table", "":
-				renderOpslogTable(out, records)
-				return nil
-			default:
-				return fmt.Errorf("unsupported output format: %s", output)
+			if strings.EqualFold(output, outputWide) {
+				output, wide = render.FormatTable, true
 			}
+			cols := opslogColumns{noChecksum: noChecksum, wide: wide}
+			return render.Write(cmd.OutOrStdout(), output, opslogList(records, cols))
 		},
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format (table, json)")
+	cmd.Flags().StringVarP(&output, "output", "o", render.FormatTable,
+		render.FlagUsage+"; wide is the table with every record field")
 	cmd.Flags().StringVar(&search, "search", "", "Filter by version or description substring")
 	cmd.Flags().StringVar(&version, "version", "", "Filter by exact migration version")
+	cmd.Flags().StringVar(&fromVer, "from-version", "", "Only versions at or after this one, in engine version order")
 	cmd.Flags().StringVar(&regex, "regex", "", "Filter by regex against version or description")
-	cmd.Flags().StringVar(&from, "from", "", "Filter applied at or after time (RFC3339 or YYYY-MM-DD)")
-	cmd.Flags().StringVar(&to, "to", "", "Filter applied at or before time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&from, "from", "", "Only records applied at or after time (RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().StringVar(&to, "to", "", "Only records applied at or before time (RFC3339 or YYYY-MM-DD)")
 	cmd.Flags().IntVar(&limit, "limit", 0, "Limit number of results")
+	cmd.Flags().BoolVar(&noChecksum, "no-checksum", false, "Omit checksums from the output")
+	cmd.Flags().BoolVar(&wide, "wide", false,
+		"Show full checksums and add duration and metadata (author, ticket, ...) columns to the table")
 	return cmd
 }
 
+// opslogFilter selects records. from and to go into the query; the rest are matched by
+// filterOpslog.
 type opslogFilter struct {
 	search  string
 	version string
@@ -114,12 +127,6 @@ func filterOpslog(records []migration.MigrationRecord, filter opslogFilter) []mi
 		if filter.version != "" && rec.Version != filter.version {
 			continue
 		}
-		if filter.from != nil && rec.AppliedAt.Before(*filter.from) {
-			continue
-		}
-		if filter.to != nil && rec.AppliedAt.After(*filter.to) {
-			continue
-		}
 		if filter.regex != nil && !filter.regex.MatchString(rec.Version+" "+rec.Description) {
 			continue
 		}
@@ -135,6 +142,8 @@ func filterOpslog(records []migration.MigrationRecord, filter opslogFilter) []mi
 	return filtered
 }
 
+func recordVersion(rec migration.MigrationRecord) string { return rec.Version }
+
 func parseOpslogTime(value string) (time.Time, error) {
 	if ts, err := time.Parse(time.RFC3339, value); err == nil {
 		return ts, nil
@@ -145,24 +154,89 @@ func parseOpslogTime(value string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid time: %s (use RFC3339 or YYYY-MM-DD)", value)
 }
 
-func renderOpslogJSON(w io.Writer, records []migration.MigrationRecord) error {
-	encoder := jsonutil.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(records)
+// opslogRecordJSON uses the json keys of migration.MigrationRecord, except that the
+// checksum is omitted when empty so it can be dropped from the output.
+type opslogRecordJSON struct {
+	Version     string            `json:"version"`
+	Description string            `json:"description"`
+	AppliedAt   time.Time         `json:"applied_at"`
+	Checksum    string            `json:"checksum,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	DurationMS  int64             `json:"duration_ms,omitempty"`
+}
+
+// opslogColumns selects the optional table columns. The compact table shortens checksums;
+// wide shows them in full and adds duration and metadata. Structured output always
+// carries every field and drops the checksum only with noChecksum.
+type opslogColumns struct {
+	noChecksum bool
+	wide       bool
 }
 
-func renderOpslogTable(w io.Writer, records []migration.MigrationRecord) {
-	if len(records) == 0 {
-		fmt.Fprintln(w, "No applied migrations found.")
-		return
+func opslogList(records []migration.MigrationRecord, cols opslogColumns) render.List {
+	list := render.List{
+		Columns: []string{"APPLIED AT", "VERSION", "DESCRIPTION"},
+		Empty:   "No applied migrations found.",
+	}
+	if !cols.noChecksum {
+		list.Columns = append(list.Columns, "CHECKSUM")
+	}
+	if cols.wide {
+		list.Columns = append(list.Columns, "DURATION", "METADATA")
 	}
 
-	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(tw, "APPLIED AT\tVERSION\tDESCRIPTION\tCHECKSUM")
-	fmt.Fprintln(tw, "----------\t-------\t-----------\t--------")
-	for _, rec := range records {
-		appliedAt := rec.AppliedAt.Format("2006-01-02 15:04")
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", appliedAt, rec.Version, rec.Description, rec.Checksum)
+	items := make([]opslogRecordJSON, len(records))
+	for i, rec := range records {
+		items[i] = opslogRecordJSON{
+			Version:     rec.Version,
+			Description: rec.Description,
+			AppliedAt:   rec.AppliedAt,
+			Checksum:    rec.Checksum,
+			Metadata:    rec.Metadata,
+			DurationMS:  rec.DurationMS,
+		}
+		row := []string{rec.AppliedAt.Format("2006-01-02 15:04"), rec.Version, rec.Description}
+		switch {
+		case cols.noChecksum:
+			items[i].Checksum = ""
+		case cols.wide:
+			row = append(row, rec.Checksum)
+		default:
+			row = append(row, shortChecksum(rec.Checksum))
+		}
+		if cols.wide {
+			row = append(row, formatDurationMS(rec.DurationMS), formatMetadata(rec.Metadata))
+		}
+		list.Rows = append(list.Rows, row)
+	}
+	list.Items = items
+	return list
+}
+
+func shortChecksum(sum string) string {
+	if len(sum) <= shortChecksumLen {
+		return sum
+	}
+	return sum[:shortChecksumLen]
+}
+
+// formatDurationMS renders a record's duration, or "-" for records written before it
+// was tracked.
+func formatDurationMS(ms int64) string {
+	if ms <= 0 {
+		return "-"
+	}
+	return (time.Duration(ms) * time.Millisecond).String()
+}
+
+// formatMetadata renders metadata as sorted key=value pairs, or "-" when there is none.
+func formatMetadata(md map[string]string) string {
+	if len(md) == 0 {
+		return "-"
+	}
+	pairs := make([]string, 0, len(md))
+	for _, k := range slices.Sorted(maps.Keys(md)) {
+		pairs = append(pairs, k+"="+md[k])
 	}
-	tw.Flush()
+	return strings.Join(pairs, ", ")
 }
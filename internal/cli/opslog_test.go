@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+)
+
+func sampleOpslogRecords() []migration.MigrationRecord {
+	return []migration.MigrationRecord{
+		{
+			Version:     "20240103_001",
+			Description: "third",
+			AppliedAt:   time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC),
+			Checksum:    "cccc",
+		},
+		{
+			Version:     "20240101_001",
+			Description: "first",
+			AppliedAt:   time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			Checksum:    "aaaa",
+		},
+	}
+}
+
+func TestBuildOpslogFilterTimeRange(t *testing.T) {
+	day := func(d int) *time.Time {
+		ts := time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+		return &ts
+	}
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom *time.Time
+		wantTo   *time.Time
+	}{
+		{name: "No bounds"},
+		{name: "From only", from: "2024-01-02", wantFrom: day(2)},
+		{name: "To only", to: "2024-01-02", wantTo: day(2)},
+		{name: "RFC3339 window", from: "2024-01-01T00:00:00Z", to: "2024-01-03T00:00:00Z",
+			wantFrom: day(1), wantTo: day(3)},
+	}
+
+	equal := func(a, b *time.Time) bool { return (a == nil && b == nil) || (a != nil && b != nil && a.Equal(*b)) }
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := buildOpslogFilter("", "", "", tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("buildOpslogFilter() error = %v", err)
+			}
+			if !equal(filter.from, tt.wantFrom) || !equal(filter.to, tt.wantTo) {
+				t.Errorf("range = %v..%v, want %v..%v", filter.from, filter.to, tt.wantFrom, tt.wantTo)
+			}
+			// The range is left to the query, so filtering keeps every record.
+			if got := filterOpslog(sampleOpslogRecords(), filter); len(got) != 2 {
+				t.Errorf("filterOpslog() kept %d records, want 2", len(got))
+			}
+		})
+	}
+
+	if _, err := buildOpslogFilter("", "", "", "yesterday", ""); err == nil {
+		t.Error("expected error for invalid time")
+	}
+}
+
+func TestRenderOpslogJSONChecksum(t *testing.T) {
+	var withSum, withoutSum bytes.Buffer
+	if err := render.Write(&withSum, render.FormatJSON, opslogList(sampleOpslogRecords(), opslogColumns{})); err != nil {
+		t.Fatalf("render.Write() error = %v", err)
+	}
+	noSum := opslogList(sampleOpslogRecords(), opslogColumns{noChecksum: true})
+	if err := render.Write(&withoutSum, render.FormatJSON, noSum); err != nil {
+		t.Fatalf("render.Write() error = %v", err)
+	}
+
+	if !strings.Contains(withSum.String(), `"checksum"`) {
+		t.Errorf("expected checksum in default output: %s", withSum.String())
+	}
+	if strings.Contains(withoutSum.String(), `"checksum"`) {
+		t.Errorf("expected checksum to be omitted: %s", withoutSum.String())
+	}
+	if !strings.Contains(withoutSum.String(), `"version": "20240101_001"`) {
+		t.Errorf("expected records in output: %s", withoutSum.String())
+	}
+}
+
+func TestOpslogListMetadata(t *testing.T) {
+	records := sampleOpslogRecords()
+	records[0].Metadata = map[string]string{"ticket": "OPS-42", "author": "jane"}
+
+	list := opslogList(records, opslogColumns{wide: true})
+	if got := list.Columns[len(list.Columns)-1]; got != "METADATA" {
+		t.Fatalf("last column = %q, want METADATA", got)
+	}
+	if got := list.Rows[0][len(list.Rows[0])-1]; got != "author=jane, ticket=OPS-42" {
+		t.Errorf("metadata cell = %q", got)
+	}
+	if got := list.Rows[1][len(list.Rows[1])-1]; got != "-" {
+		t.Errorf("metadata cell without metadata = %q, want -", got)
+	}
+
+	var buf bytes.Buffer
+	if err := render.Write(&buf, render.FormatJSON, opslogList(records, opslogColumns{})); err != nil {
+		t.Fatalf("render.Write() error = %v", err)
+	}
+	if strings.Count(buf.String(), `"metadata"`) != 1 {
+		t.Errorf("only the record with metadata should carry the field:\n%s", buf.String())
+	}
+	if narrow := opslogList(records, opslogColumns{}); len(narrow.Columns) != 4 {
+		t.Errorf("columns without --wide = %v", narrow.Columns)
+	}
+}
+
+func TestOpslogListWide(t *testing.T) {
+	records := sampleOpslogRecords()
+	records[0].Checksum = strings.Repeat("ab", 32)
+	records[0].DurationMS = 1500
+
+	compact := opslogList(records, opslogColumns{})
+	if got := compact.Rows[0][3]; got != records[0].Checksum[:shortChecksumLen] {
+		t.Errorf("compact checksum = %q, want it shortened", got)
+	}
+	if got := compact.Rows[1][3]; got != "aaaa" {
+		t.Errorf("short checksum = %q, want it unchanged", got)
+	}
+
+	wide := opslogList(records, opslogColumns{wide: true})
+	want := []string{"APPLIED AT", "VERSION", "DESCRIPTION", "CHECKSUM", "DURATION", "METADATA"}
+	if !slices.Equal(wide.Columns, want) {
+		t.Fatalf("wide columns = %v, want %v", wide.Columns, want)
+	}
+	if got := wide.Rows[0][3]; got != records[0].Checksum {
+		t.Errorf("wide checksum = %q, want the full checksum", got)
+	}
+	if got := wide.Rows[0][4]; got != "1.5s" {
+		t.Errorf("duration = %q, want 1.5s", got)
+	}
+	if got := wide.Rows[1][4]; got != "-" {
+		t.Errorf("duration without a measurement = %q, want -", got)
+	}
+}
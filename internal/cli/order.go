@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/spf13/cobra"
+)
+
+func newOrderCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "order",
+		Short: "List registered migrations in the order up runs them, without connecting to MongoDB",
+		Long: "Sorts the registered migrations with MIGRATIONS_VERSION_ORDER and groups them into the " +
+			"batches a run uses: with MIGRATIONS_MAX_PARALLEL above 1, consecutive Independent " +
+			"migrations share a batch and may run concurrently.",
+		Annotations: map[string]string{annotationOffline: "true"},
+		Example:     `  mt order --output json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			cfg, err := getConfig(cmd.Context())
+			if err != nil {
+				return err
+			}
+			return writeOrder(cmd.OutOrStdout(), format, cfg, migration.RegisteredMigrations())
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "output", "o", render.FormatTable, render.FlagUsage)
+	return cmd
+}
+
+func writeOrder(w io.Writer, format string, cfg *config.Config, ms map[string]migration.Migration) error {
+	compare, err := migration.ParseVersionOrder(cfg.VersionOrder)
+	if err != nil {
+		return err
+	}
+	// The engine only orders here; it is never given a database to run against.
+	engine := migration.NewEngine(nil, cfg.MigrationsCollection, ms,
+		migration.WithVersionComparator(compare),
+		migration.WithMaxParallel(cfg.MaxParallel),
+		migration.WithCausalConsistency(cfg.CausalConsistency),
+	)
+	entries := engine.ExecutionOrder()
+	list := render.List{
+		Columns: []string{"#", "VERSION", "DESCRIPTION", "BATCH"},
+		Items:   entries,
+		Empty:   "No migrations registered.",
+	}
+	for _, e := range entries {
+		list.Rows = append(list.Rows, []string{strconv.Itoa(e.Index), e.Version, e.Description, strconv.Itoa(e.Batch)})
+	}
+	return render.Write(w, format, list)
+}
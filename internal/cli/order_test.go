@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestWriteOrderJSON(t *testing.T) {
+	registry := map[string]migration.Migration{
+		"v10": doctorMigration{version: "v10"},
+		"v9":  doctorMigration{version: "v9"},
+		"v2":  doctorMigration{version: "v2"},
+	}
+
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{order: "lexical", want: []string{"v10", "v2", "v9"}},
+		{order: "numeric", want: []string{"v2", "v9", "v10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.order, func(t *testing.T) {
+			var out bytes.Buffer
+			cfg := &config.Config{VersionOrder: tt.order, MaxParallel: 1}
+			if err := writeOrder(&out, "json", cfg, registry); err != nil {
+				t.Fatalf("writeOrder() error = %v", err)
+			}
+			var got []migration.OrderEntry
+			if err := jsonutil.Unmarshal(out.Bytes(), &got); err != nil {
+				t.Fatalf("order is not valid JSON: %v\n%s", err, out.String())
+			}
+			var versions []string
+			for i, e := range got {
+				if e.Index != i+1 || e.Batch != i+1 {
+					t.Errorf("entry %d = %+v, want index and batch %d", i, e, i+1)
+				}
+				versions = append(versions, e.Version)
+			}
+			if !slices.Equal(versions, tt.want) {
+				t.Errorf("order = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+
+	err := writeOrder(&bytes.Buffer{}, "json", &config.Config{VersionOrder: "random"}, registry)
+	if err == nil {
+		t.Error("writeOrder() with an unknown version order should fail")
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/spf13/cobra"
+)
+
+func newPreflightCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "preflight", Short: "Check the connection before a run"}
+	cmd.AddCommand(newPermsCmd())
+	return cmd
+}
+
+func newPermsCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "perms",
+		Short: "Probe whether the configured user can create, index, write and drop collections",
+		Long: "Creates a temporary " + migration.PermissionProbePrefix + "* collection, builds an index, " +
+			"inserts and deletes a document and drops it again, reporting which steps were allowed.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			engine, err := getEngine(cmd.Context())
+			if err != nil {
+				return err
+			}
+			checks, err := engine.ProbePermissions(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := render.Write(cmd.OutOrStdout(), output, permissionList(checks)); err != nil {
+				return err
+			}
+			return deniedPermissions(checks)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", render.FormatTable, render.FlagUsage)
+	return cmd
+}
+
+func permissionList(checks []migration.PermissionCheck) render.List {
+	list := render.List{Columns: []string{"OPERATION", "ALLOWED", "ERROR"}, Items: checks}
+	for _, c := range checks {
+		allowed := "yes"
+		if !c.Allowed {
+			allowed = "no"
+		}
+		list.Rows = append(list.Rows, []string{c.Operation, allowed, c.Error})
+	}
+	return list
+}
+
+func deniedPermissions(checks []migration.PermissionCheck) error {
+	var denied []string
+	for _, c := range checks {
+		if !c.Allowed {
+			denied = append(denied, c.Operation)
+		}
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrPermissionDenied, denied)
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestDeniedPermissions(t *testing.T) {
+	allowed := []migration.PermissionCheck{{Operation: "insert", Allowed: true}}
+	if err := deniedPermissions(allowed); err != nil {
+		t.Errorf("deniedPermissions() error = %v, want nil", err)
+	}
+
+	denied := append(allowed, migration.PermissionCheck{Operation: "create index", Error: "not authorized"})
+	if err := deniedPermissions(denied); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("deniedPermissions() error = %v, want %v", err, ErrPermissionDenied)
+	}
+}
@@ -3,6 +3,10 @@ package cli
 import (
 	"fmt"
 	"io"
+	"strconv"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
 )
 
 func renderPlan(out io.Writer, direction string, plan []string) {
@@ -16,3 +20,55 @@ func renderPlan(out io.Writer, direction string, plan []string) {
 		fmt.Fprintf(out, "  %02d. %s\n", i+1, version)
 	}
 }
+
+func explainList(entries []migration.PlanEntry) render.List {
+	list := render.List{
+		Columns: []string{"VERSION", "RUN", "REASON", "DESCRIPTION"},
+		Items:   entries,
+		Empty:   "No migrations registered.",
+	}
+	for _, entry := range entries {
+		run := "no"
+		if entry.Run {
+			run = "yes"
+		}
+		list.Rows = append(list.Rows, []string{entry.Version, run, string(entry.Reason), entry.Description})
+	}
+	return list
+}
+
+func estimateList(cost migration.PlanCost) render.List {
+	list := render.List{
+		Columns: []string{"VERSION", "DOCUMENTS", "INDEXES", "NOTE"},
+		Items:   cost.Migrations,
+		Empty:   "No migrations to up.",
+	}
+	for _, entry := range cost.Migrations {
+		if entry.Estimate == nil {
+			list.Rows = append(list.Rows, []string{entry.Version, "unknown", "unknown", ""})
+			continue
+		}
+		list.Rows = append(list.Rows, []string{
+			entry.Version,
+			strconv.FormatInt(entry.Estimate.Documents, 10),
+			strconv.Itoa(entry.Estimate.Indexes),
+			entry.Estimate.Note,
+		})
+	}
+	return list
+}
+
+func renderEstimate(out io.Writer, cost migration.PlanCost) error {
+	if err := render.Write(out, render.FormatTable, estimateList(cost)); err != nil {
+		return err
+	}
+	if len(cost.Migrations) == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "\nTotal: %d documents, %d indexes", cost.Total.Documents, cost.Total.Indexes)
+	if cost.Unknown > 0 {
+		fmt.Fprintf(out, " (%d of %d migrations unknown)", cost.Unknown, len(cost.Migrations))
+	}
+	fmt.Fprintln(out)
+	return nil
+}
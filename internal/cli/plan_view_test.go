@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestRenderEstimate(t *testing.T) {
+	cost := migration.PlanCost{
+		Migrations: []migration.PlanEstimate{
+			{Version: "20240101_001", Estimate: &migration.CostEstimate{Documents: 1200, Indexes: 1}},
+			{Version: "20240102_001"},
+		},
+		Total:   migration.CostEstimate{Documents: 1200, Indexes: 1},
+		Unknown: 1,
+	}
+
+	var out bytes.Buffer
+	if err := renderEstimate(&out, cost); err != nil {
+		t.Fatalf("renderEstimate() error = %v", err)
+	}
+	for _, want := range []string{
+		"20240102_001   unknown",
+		"Total: 1200 documents, 1 indexes (1 of 2 migrations unknown)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output is missing %q:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.uber.org/zap"
+)
+
+const (
+	annotationMutating = "mutating"
+	// annotationPreview lists, comma-separated, the flags that turn a mutating command into a
+	// read-only preview, such as up --dry-run. The mutating guards skip a command run with one.
+	annotationPreview      = "preview-flags"
+	eventMaintenanceWindow = "maintenance_window"
+)
+
+func isMutating(cmd *cobra.Command) bool {
+	if cmd.Annotations[annotationMutating] != "true" {
+		return false
+	}
+	for _, name := range strings.Split(cmd.Annotations[annotationPreview], ",") {
+		if f := cmd.Flags().Lookup(name); f != nil && f.Changed && f.Value.String() == "true" {
+			return false
+		}
+	}
+	return true
+}
+
+// checkEnvironment blocks mutating commands against a production environment unless the
+// caller explicitly confirmed it. The --environment flag takes precedence over config.
+func checkEnvironment(cmd *cobra.Command, cfg *config.Config, env string, confirmed bool) error {
+	if env != "" {
+		cfg.Environment = env
+	}
+	if !isMutating(cmd) || !cfg.IsProduction() {
+		return nil
+	}
+
+	out := cmd.ErrOrStderr()
+	renderProductionBanner(out, cmd.Name(), cfg)
+	if !confirmed {
+		return fmt.Errorf("%w: re-run with --confirm-production", ErrProductionNotConfirmed)
+	}
+	fmt.Fprintln(out, "Production run confirmed.")
+	return nil
+}
+
+// checkReadFromSecondary refuses mutating commands when the client reads from a
+// secondary. The engine is read-only in that mode too; this fails before any work starts.
+func checkReadFromSecondary(cmd *cobra.Command, cfg *config.Config) error {
+	if !cfg.ReadFromSecondary || !isMutating(cmd) {
+		return nil
+	}
+	return fmt.Errorf("%w: %q writes to the primary; drop --read-from-secondary", ErrSecondaryReadOnly, cmd.Name())
+}
+
+// checkMaintenanceWindow refuses mutating commands outside the configured maintenance
+// window unless override is set. clock, normally serverClock, is only read once a window
+// applies. Every decision is logged, and audited when the engine has an audit collection.
+func checkMaintenanceWindow(cmd *cobra.Command, s *Services, override bool, clock func() time.Time) error {
+	if !isMutating(cmd) {
+		return nil
+	}
+	window, err := s.Config.ParseMaintenanceWindow()
+	if err != nil || window == nil {
+		return err
+	}
+	now := clock()
+
+	at := now.In(window.Location()).Format(time.RFC3339)
+	outcome, reason := migration.AuditAllowed, fmt.Sprintf("%s at %s is inside %s", cmd.Name(), at, window)
+	if !window.Contains(now) {
+		outcome, reason = migration.AuditRefused, fmt.Sprintf("%s at %s is outside %s", cmd.Name(), at, window)
+		if override {
+			outcome, reason = migration.AuditOverridden, reason+"; overridden with --override-window"
+		}
+	}
+	zap.S().Infow("Maintenance window check", "command", cmd.Name(), "outcome", outcome, "window", window.String())
+	if s.Engine != nil {
+		s.Engine.AuditPolicy(eventMaintenanceWindow, outcome, reason)
+	}
+	if outcome == migration.AuditRefused {
+		return fmt.Errorf("%w: %s; pass --override-window to run anyway", ErrOutsideMaintenanceWindow, reason)
+	}
+	return nil
+}
+
+// serverClock reads the time from the server's hello reply, so the maintenance window
+// follows the cluster's clock rather than that of the machine running the tool. Without a
+// client, or when the server does not report it, the local clock is used and a warning
+// is logged.
+func serverClock(ctx context.Context, client *mongo.Client) func() time.Time {
+	return func() time.Time {
+		if client == nil {
+			return time.Now()
+		}
+		var hello struct {
+			LocalTime time.Time `bson:"localTime"`
+		}
+		err := client.Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+		if err != nil || hello.LocalTime.IsZero() {
+			zap.S().Warnw("Could not read the server time; using the local clock for the maintenance window",
+				"error", err)
+			return time.Now()
+		}
+		return hello.LocalTime
+	}
+}
+
+func renderProductionBanner(w io.Writer, command string, cfg *config.Config) {
+	host := cfg.Host()
+	if host == "" {
+		host = "unknown"
+	}
+	fmt.Fprintf(w, "%s PRODUCTION: %q will modify database %q on host %s\n", ui.Warn, command, cfg.Database, host)
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/drewjocham/mongo-migration-tool/internal/schema"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func listCommandFixtures() map[string]render.List {
+	applied := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return map[string]render.List{
+		"status": statusList([]migration.MigrationStatus{
+			{Version: "20240101_001", Description: "first", Applied: true, AppliedAt: &applied},
+			{Version: "20240102_001", Description: "second, with comma"},
+		}),
+		"opslog": opslogList(sampleOpslogRecords(), opslogColumns{wide: true}),
+		"explain": explainList([]migration.PlanEntry{
+			{Version: "20240101_001", Description: "first", Reason: migration.ReasonApplied},
+			{Version: "20240102_001", Description: "second", Run: true, Reason: migration.ReasonPending},
+		}),
+		"schema": indexList([]schema.IndexSpec{
+			{Collection: "users", Name: "email_1", Keys: bson.D{{Key: "email", Value: 1}}, Unique: true},
+			{Collection: "orders", Name: "created_-1", Keys: bson.D{{Key: "created", Value: -1}}},
+		}),
+	}
+}
+
+func TestListCommandsHonorFormats(t *testing.T) {
+	for name, list := range listCommandFixtures() {
+		t.Run(name, func(t *testing.T) {
+			t.Run("table", func(t *testing.T) {
+				out := renderList(t, render.FormatTable, list)
+				if !strings.Contains(out, list.Columns[0]) || !strings.Contains(out, list.Rows[0][1]) {
+					t.Errorf("table is missing header or rows:\n%s", out)
+				}
+			})
+
+			t.Run("json", func(t *testing.T) {
+				var items []map[string]any
+				if err := jsonutil.Unmarshal([]byte(renderList(t, render.FormatJSON, list)), &items); err != nil {
+					t.Fatalf("invalid json: %v", err)
+				}
+				if len(items) != len(list.Rows) {
+					t.Errorf("got %d items, want %d", len(items), len(list.Rows))
+				}
+			})
+
+			t.Run("jsonl", func(t *testing.T) {
+				lines := strings.Split(strings.TrimSuffix(renderList(t, render.FormatJSONL, list), "\n"), "\n")
+				if len(lines) != len(list.Rows) {
+					t.Fatalf("got %d lines, want %d", len(lines), len(list.Rows))
+				}
+				for _, line := range lines {
+					var item map[string]any
+					if err := jsonutil.Unmarshal([]byte(line), &item); err != nil {
+						t.Errorf("invalid json line %q: %v", line, err)
+					}
+				}
+			})
+
+			t.Run("csv", func(t *testing.T) {
+				records, err := csv.NewReader(strings.NewReader(renderList(t, render.FormatCSV, list))).ReadAll()
+				if err != nil {
+					t.Fatalf("invalid csv: %v", err)
+				}
+				if len(records) != len(list.Rows)+1 {
+					t.Fatalf("got %d records, want header plus %d rows", len(records), len(list.Rows))
+				}
+				for _, record := range records {
+					for _, cell := range record {
+						if strings.Contains(cell, "\033[") {
+							t.Errorf("csv cell contains terminal escapes: %q", cell)
+						}
+					}
+				}
+			})
+
+			t.Run("yaml", func(t *testing.T) {
+				var items []map[string]any
+				if err := yaml.Unmarshal([]byte(renderList(t, render.FormatYAML, list)), &items); err != nil {
+					t.Fatalf("invalid yaml: %v", err)
+				}
+				if len(items) != len(list.Rows) {
+					t.Errorf("got %d items, want %d", len(items), len(list.Rows))
+				}
+			})
+		})
+	}
+}
+
+func renderList(t *testing.T, format string, list render.List) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := render.Write(&buf, format, list); err != nil {
+		t.Fatalf("render %s: %v", format, err)
+	}
+	return buf.String()
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+func newResumeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:         "resume",
+		Short:       "Continue an interrupted run after verifying the applied history",
+		Annotations: map[string]string{annotationMutating: "true"},
+		Long: "Validates the checksums of all applied migrations and, if they are clean, applies the " +
+			"remaining pending migrations like up. A mismatching record usually means a crashed run left " +
+			"partial state behind; resume refuses to continue until it is repaired.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			engine, err := getEngine(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			if err := engine.Validate(cmd.Context()); err != nil {
+				return fmt.Errorf("%w: %w\nrestore the migration code or fix its record before resuming",
+					ErrDirtyState, err)
+			}
+
+			plan, err := engine.Plan(cmd.Context(), migration.DirectionUp, "")
+			if err != nil {
+				return err
+			}
+			if len(plan) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "Nothing to resume; database is up to date.")
+				return nil
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Resuming with %d pending migration(s).\n", len(plan))
+			if err := engine.Up(cmd.Context(), ""); err != nil {
+				return fmt.Errorf("%s: %w", ErrFailedToRun, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Database is up to date!\n", ui.Done)
+			return nil
+		},
+	}
+	return cmd
+}
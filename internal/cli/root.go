@@ -2,33 +2,50 @@ package cli
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"github.com/drewjocham/mongo-migration-tool/internal/config"
 	logging "github.com/drewjocham/mongo-migration-tool/internal/log"
 	"io"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/dbconn"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 	"go.uber.org/zap"
 )
 
 const (
 	annotationOffline = "offline"
-	maxPingRetries    = 5
-	pingRetryDelay    = 1 * time.Second
-	pingTimeout       = 2 * time.Second
+	// annotationNoConfig marks commands that run before any configuration exists, so
+	// loading it is skipped entirely.
+	annotationNoConfig = "no-config"
 )
 
 var (
-	configFile string
-	debugMode  bool
-	logFile    string
-	showConfig bool
+	configFile        string
+	debugMode         bool
+	logFile           string
+	showConfig        bool
+	environment       string
+	confirmProduction bool
+	createDB          bool
+	waitTimeout       time.Duration
+	allowUnset        bool
+	databaseName      string
+	readFromSecondary bool
+	noTransaction     bool
+	asciiOutput       bool
+	iconSet           string
+	overrideWindow    bool
+	failOnOrphaned    bool
+	limitConcurrency  int
+	maxOpsPerSec      int
 
 	appVersion, commit, date = "dev", "none", "unknown"
 	ErrShowConfigDisplayed   = errors.New("configuration displayed")
@@ -41,7 +58,35 @@ type Services struct {
 }
 
 func Execute() error {
-	return newRootCmd().Execute()
+	dbconn.Version = appVersion
+	ctx, stop := notifyInterrupt(context.Background(), os.Stderr)
+	defer stop()
+	return newRootCmd().ExecuteContext(ctx)
+}
+
+// notifyInterrupt cancels the returned context on the first SIGINT or SIGTERM so a running
+// command stops after the current migration and releases its lock. Signal handling is then
+// reset, so a second Ctrl-C terminates the process immediately.
+func notifyInterrupt(parent context.Context, w io.Writer) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case sig := <-sigs:
+			signal.Stop(sigs)
+			fmt.Fprintf(w, "\n%s received: interrupted, stopping after current migration "+
+				"(press Ctrl-C again to exit immediately)\n", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(sigs)
+		cancel()
+	}
 }
 
 func newRootCmd() *cobra.Command {
@@ -55,11 +100,37 @@ func newRootCmd() *cobra.Command {
 			if _, err := logging.New(debugMode, logFile); err != nil {
 				return err
 			}
+			if err := applyIcons(iconFlag()); err != nil {
+				return err
+			}
+			if cmd.Annotations[annotationNoConfig] == "true" {
+				return nil
+			}
 
 			s, err := bootstrap(cmd.Context(), configFile, showConfig, cmd.OutOrStdout(), isOffline(cmd))
 			if err != nil {
 				return err
 			}
+			if s != nil && s.Config != nil {
+				if err := checkEnvironment(cmd, s.Config, environment, confirmProduction); err != nil {
+					teardown(s)
+					return err
+				}
+				if err := checkReadFromSecondary(cmd, s.Config); err != nil {
+					teardown(s)
+					return err
+				}
+				if err := checkMaintenanceWindow(cmd, s, overrideWindow, serverClock(cmd.Context(), s.MongoClient)); err != nil {
+					teardown(s)
+					return err
+				}
+			}
+			if createDB && s != nil && s.Engine != nil {
+				if err := ensureDatabase(cmd.Context(), s); err != nil {
+					teardown(s)
+					return err
+				}
+			}
 			if s != nil {
 				ctx := context.WithValue(cmd.Context(), ctxServicesKey, s)
 				if s.Config != nil {
@@ -84,15 +155,38 @@ func newRootCmd() *cobra.Command {
 	p.StringVarP(&configFile, "config", "c", "", "Path to config file")
 	p.BoolVar(&debugMode, "debug", false, "Enable debug logging")
 	p.StringVar(&logFile, "log-file", "", "Path to write logs to a file")
+	p.BoolVar(&asciiOutput, "ascii", false, "Print [OK]/[WARN]-style tags instead of icons (same as --icons ascii)")
+	p.StringVar(&iconSet, "icons", "",
+		"Icon set for status and result lines: unicode, ascii or emoji (overrides MMT_ICONS)")
 	p.BoolVar(&showConfig, "show-config", false, "Print effective configuration and exit")
+	p.StringVar(&environment, "environment", "", "Deployment environment (overrides MIGRATIONS_ENVIRONMENT)")
+	p.BoolVar(&confirmProduction, "confirm-production", false, "Allow mutating commands in production")
+	p.BoolVar(&overrideWindow, "override-window", false,
+		"Allow mutating commands outside MIGRATIONS_MAINTENANCE_WINDOW (the override is audited)")
+	p.BoolVar(&createDB, "create-db", false, "Create the database and migrations collection if missing")
+	p.DurationVar(&waitTimeout, "wait", 0, "Keep retrying the initial connection for up to this long (e.g. 30s)")
+	p.BoolVar(&allowUnset, "allow-unset", false, "Leave ${VAR} placeholders in config literal when VAR is unset")
+	p.StringVarP(&databaseName, "database", "d", "", "Database to migrate (overrides MONGO_DATABASE)")
+	p.BoolVar(&noTransaction, "no-transaction", false,
+		"Run migrations without a transaction (overrides MIGRATIONS_NO_TRANSACTION)")
+	p.IntVar(&limitConcurrency, "limit-concurrency", 0,
+		"Max BulkWriter batches in flight at once (overrides MIGRATIONS_BULK_CONCURRENCY)")
+	p.IntVar(&maxOpsPerSec, "max-ops-per-sec", 0,
+		"Cap BulkWriter and DeleteInBatches writes per second (overrides MIGRATIONS_MAX_OPS_PER_SEC)")
+	p.BoolVar(&failOnOrphaned, "fail-on-orphaned", false,
+		"Refuse up and down while a record has no registered migration (overrides MIGRATIONS_FAIL_ON_ORPHANED)")
+	p.BoolVar(&readFromSecondary, "read-from-secondary", false,
+		"Read from a secondary and refuse mutating commands (overrides MONGO_READ_FROM_SECONDARY)")
 
 	cmd.AddCommand(
-		newUpCmd(), newDownCmd(), newForceCmd(), newUnlockCmd(),
+		newUpCmd(), newDownCmd(), newResumeCmd(), newForceCmd(), newUnlockCmd(),
 		newStatusCmd(), newOpslogCmd(),
+		newExportCmd(), newImportCmd(), newAdminCmd(),
 		NewOplogCmd(),
 		NewDBCmd(),
-		newParseCmd(), newValidateCmd(),
-		newCreateCmd(), newSchemaCmd(), NewMCPCmd(),
+		newParseCmd(), newValidateCmd(), newCheckCmd(), newDoctorCmd(), newCatalogCmd(), newOrderCmd(), newPreflightCmd(),
+		newSelfTestCmd(), newChecksumGenCmd(), newDevCmd(),
+		newCreateCmd(), newSchemaCmd(), newConfigCmd(), NewMCPCmd(),
 		versionCmd,
 	)
 
@@ -104,7 +198,33 @@ func bootstrap(ctx context.Context, path string, show bool, out io.Writer, offli
 	if err != nil {
 		return nil, err
 	}
-
+	if waitTimeout > 0 {
+		cfg.ConnectWait = waitTimeout
+	}
+	if environment != "" {
+		cfg.Environment = environment
+	}
+	if readFromSecondary {
+		cfg.ReadFromSecondary = true
+	}
+	if noTransaction {
+		cfg.NoTransaction = true
+	}
+	if limitConcurrency > 0 {
+		cfg.BulkConcurrency = limitConcurrency
+	}
+	if maxOpsPerSec > 0 {
+		cfg.MaxOpsPerSec = maxOpsPerSec
+	}
+	if failOnOrphaned {
+		cfg.FailOnOrphaned = true
+	}
+	if flag := iconFlag(); flag != "" {
+		cfg.Icons = flag
+	}
+	if err := applyIcons(cfg.Icons); err != nil {
+		return nil, err
+	}
 	if show {
 		if err := renderConfig(out, cfg); err != nil {
 			return nil, err
@@ -125,72 +245,62 @@ func bootstrap(ctx context.Context, path string, show bool, out io.Writer, offli
 		return nil, err
 	}
 
+	engine, err := newEngine(client, cfg, out)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
 	return &Services{
 		Config:      cfg,
 		MongoClient: client,
-		Engine: migration.NewEngine(client.Database(cfg.Database), cfg.MigrationsCollection,
-			migration.RegisteredMigrations()),
+		Engine:      engine,
 	}, nil
 }
 
-func dial(ctx context.Context, cfg *config.Config) (*mongo.Client, error) {
-	opts := options.Client().
-		ApplyURI(cfg.GetConnectionString()).
-		SetMaxPoolSize(uint64(cfg.MaxPoolSize)).
-		SetMinPoolSize(uint64(cfg.MinPoolSize))
-
-	if cfg.SSLEnabled {
-		opts.SetTLSConfig(&tls.Config{InsecureSkipVerify: cfg.SSLInsecure})
-	}
-
-	client, err := mongo.Connect(opts)
+// newEngine builds the engine for cfg on client with the registered migrations, reporting
+// progress to out.
+func newEngine(
+	client *mongo.Client, cfg *config.Config, out io.Writer, extra ...migration.EngineOption,
+) (*migration.Engine, error) {
+	opts, err := migration.OptionsFromConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
-
-	if err := ping(ctx, client, maxPingRetries); err != nil {
-		_ = client.Disconnect(ctx)
-		return nil, err
-	}
-
-	return client, nil
+	opts = append(opts, migration.WithProgress(out))
+	return migration.NewEngine(client.Database(cfg.Database), cfg.MigrationsCollection,
+		migration.RegisteredMigrations(), append(opts, extra...)...), nil
 }
 
-func ping(ctx context.Context, client *mongo.Client, retries int) error {
-	for i := 1; i <= retries; i++ {
-		pCtx, cancel := context.WithTimeout(ctx, pingTimeout)
-		err := client.Ping(pCtx, nil)
-		cancel()
-
-		if err == nil {
-			return nil
-		}
-
-		zap.S().Warnf("Attempt %d/%d failed: %v", i, retries, err)
-
-		if i == retries {
-			return fmt.Errorf("unreachable: %w", err)
-		}
+func dial(ctx context.Context, cfg *config.Config) (*mongo.Client, error) {
+	return dbconn.ConnectWithRetry(ctx, dbconn.ClientOptions(cfg), dbconn.PolicyFromConfig(cfg))
+}
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(pingRetryDelay):
-		}
+func ensureDatabase(ctx context.Context, s *Services) error {
+	created, err := s.Engine.EnsureMigrationsCollection(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create database %s: %w", s.Config.Database, err)
+	}
+	if created {
+		zap.S().Infow("Created migrations collection", "database", s.Config.Database,
+			"collection", s.Config.MigrationsCollection)
+		return nil
 	}
+	zap.S().Debugw("Migrations collection already exists", "database", s.Config.Database,
+		"collection", s.Config.MigrationsCollection)
 	return nil
 }
 
 func loadConfig(path string) (*config.Config, error) {
+	opts := config.Options{AllowUnset: allowUnset, Database: databaseName}
 	if path != "" {
-		return config.Load(path)
+		return config.LoadWith(opts, path)
 	}
-	return config.Load(".env", ".env.local")
+	return config.LoadWith(opts, ".env", ".env.local")
 }
 
 func validateRegistry() error {
 	if len(migration.RegisteredMigrations()) == 0 {
-		return errors.New("no migrations registered")
+		return errors.New("no migrations registered; run `doctor` to find unimported migration files")
 	}
 	return nil
 }
@@ -215,3 +325,25 @@ func teardown(s *Services) {
 	}
 	_ = zap.L().Sync()
 }
+
+// iconFlag returns the icon set picked on the command line; --ascii is short for --icons ascii.
+func iconFlag() string {
+	if iconSet == "" && asciiOutput {
+		return ui.IconsASCII.String()
+	}
+	return iconSet
+}
+
+// applyIcons switches the ui icon set to name. An empty name keeps the current set, which
+// starts out from MMT_ICONS or MMT_ASCII in the process environment.
+func applyIcons(name string) error {
+	if name == "" {
+		return nil
+	}
+	set, err := ui.ParseIconSet(name)
+	if err != nil {
+		return err
+	}
+	ui.SetIcons(set)
+	return nil
+}
@@ -1,28 +1,33 @@
 package cli
 
 import (
-	"fmt"
-	"io"
-	"strings"
-	"text/tabwriter"
-
-	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
 	"github.com/drewjocham/mongo-migration-tool/internal/schema"
 	"github.com/spf13/cobra"
 )
 
+type schemaFilterFlags struct {
+	collections []string
+	pattern     string
+}
+
 func newSchemaCmd() *cobra.Command {
+	flags := &schemaFilterFlags{}
 	cmd := &cobra.Command{
 		Use:         "schema",
 		Short:       "Schema utilities",
 		Annotations: map[string]string{annotationOffline: "true"},
 	}
 
-	cmd.AddCommand(newSchemaIndexesCmd())
+	p := cmd.PersistentFlags()
+	p.StringArrayVar(&flags.collections, "collection", nil, "Only include this collection (repeatable)")
+	p.StringVar(&flags.pattern, "pattern", "", "Only include collections matching this regex")
+
+	cmd.AddCommand(newSchemaIndexesCmd(flags))
 	return cmd
 }
 
-func newSchemaIndexesCmd() *cobra.Command {
+func newSchemaIndexesCmd(flags *schemaFilterFlags) *cobra.Command {
 	var output string
 
 	cmd := &cobra.Command{
@@ -30,39 +35,27 @@ func newSchemaIndexesCmd() *cobra.Command {
 		Short:       "List expected indexes registered in code",
 		Annotations: map[string]string{annotationOffline: "true"},
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			switch strings.ToLower(output) {
-			case "json":
-				return renderIndexesJSON(cmd.OutOrStdout())
-			case "table", "":
-				renderIndexesTable(cmd.OutOrStdout())
-				return nil
-			default:
-				return fmt.Errorf("unsupported output format: %s", output)
+			filter, err := schema.NewCollectionFilter(flags.collections, flags.pattern)
+			if err != nil {
+				return err
 			}
+			indexes := filter.FilterIndexes(schema.Indexes())
+
+			return render.Write(cmd.OutOrStdout(), output, indexList(indexes))
 		},
 	}
 
-	cmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table or json")
+	cmd.Flags().StringVarP(&output, "output", "o", render.FormatTable, render.FlagUsage)
 	return cmd
 }
 
-func renderIndexesJSON(w io.Writer) error {
-	encoder := jsonutil.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(schema.Indexes())
-}
-
-func renderIndexesTable(w io.Writer) {
-	indexes := schema.Indexes()
-	if len(indexes) == 0 {
-		fmt.Fprintln(w, "No index specifications registered.")
-		return
+func indexList(indexes []schema.IndexSpec) render.List {
+	list := render.List{
+		Columns: []string{"COLLECTION", "INDEX", "KEYS", "UNIQUE", "SPARSE", "PARTIAL FILTER"},
+		Items:   indexes,
+		Empty:   "No index specifications registered.",
 	}
 
-	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(tw, "COLLECTION\tINDEX\tKEYS\tUNIQUE\tSPARSE\tPARTIAL FILTER")
-	fmt.Fprintln(tw, "----------\t-----\t----\t------\t------\t--------------")
-
 	for _, spec := range indexes {
 		unique := "no"
 		if spec.Unique {
@@ -77,17 +70,14 @@ func renderIndexesTable(w io.Writer) {
 			partial = "-"
 		}
 
-		fmt.Fprintf(
-			tw,
-			"%s\t%s\t%s\t%s\t%s\t%s\n",
+		list.Rows = append(list.Rows, []string{
 			spec.Collection,
 			spec.Name,
 			spec.KeyString(),
 			unique,
 			sparse,
 			partial,
-		)
+		})
 	}
-
-	tw.Flush()
+	return list
 }
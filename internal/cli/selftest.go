@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/spf13/cobra"
+)
+
+func newSelfTestCmd() *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:   "selftest",
+		Short: "Apply and roll back a built-in no-op migration to check the tool works end to end",
+		Long: "Runs up and down for a built-in no-op migration against a temporary <collection>" +
+			migration.SelfTestSuffix + " collection, checks its record appears and disappears, and drops " +
+			"the collection. Your migrations collection and registered migrations are not touched.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			engine, err := getEngine(cmd.Context())
+			if err != nil {
+				return err
+			}
+			steps, err := engine.SelfTest(cmd.Context())
+			if err != nil {
+				return err
+			}
+			if err := render.Write(cmd.OutOrStdout(), output, selfTestList(steps)); err != nil {
+				return err
+			}
+			return failedSelfTest(steps)
+		},
+	}
+	cmd.Flags().StringVarP(&output, "output", "o", render.FormatTable, render.FlagUsage)
+	return cmd
+}
+
+func selfTestList(steps []migration.SelfTestStep) render.List {
+	list := render.List{Columns: []string{"STEP", "PASSED", "ERROR"}, Items: steps}
+	for _, s := range steps {
+		passed := "yes"
+		if !s.Passed {
+			passed = "no"
+		}
+		list.Rows = append(list.Rows, []string{s.Step, passed, s.Error})
+	}
+	return list
+}
+
+func failedSelfTest(steps []migration.SelfTestStep) error {
+	var failed []string
+	for _, s := range steps {
+		if !s.Passed {
+			failed = append(failed, s.Step)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %v", ErrSelfTestFailed, failed)
+}
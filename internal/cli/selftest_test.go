@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
+func TestFailedSelfTest(t *testing.T) {
+	passed := []migration.SelfTestStep{{Step: "apply", Passed: true}}
+	if err := failedSelfTest(passed); err != nil {
+		t.Errorf("failedSelfTest() error = %v, want nil", err)
+	}
+
+	failed := append(passed, migration.SelfTestStep{Step: "record written", Error: "not found"})
+	if err := failedSelfTest(failed); !errors.Is(err, ErrSelfTestFailed) {
+		t.Errorf("failedSelfTest() error = %v, want %v", err, ErrSelfTestFailed)
+	}
+}
@@ -3,84 +3,225 @@ package cli
 import (
 	"fmt"
 	"io"
+	"os"
 	"strings"
-	"text/tabwriter"
+	"text/template"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 func newStatusCmd() *cobra.Command {
-	var format string
+	var (
+		format       string
+		summary      bool
+		tmplString   string
+		templateFile string
+		strict       bool
+		fromVersion  string
+		snapshot     string
+		diff         string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show migration status",
+		Example: `  mt status -o template --template-string '{{range .}}{{appliedIcon .}} {{.Version}}\n{{end}}'
+  mt status --template-file status.tmpl
+  mt status --strict-checksum  # CI guard: fail on checksum drift
+  mt status --snapshot before.json  # before a deploy
+  mt status --diff before.json      # after it: what was applied, rolled back or changed`,
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			var tmpl *template.Template
+			if format == formatTemplate || tmplString != "" || templateFile != "" {
+				t, err := parseStatusTemplate(tmplString, templateFile)
+				if err != nil {
+					return err
+				}
+				tmpl = t
+			}
+
+			if summary && fromVersion != "" {
+				return fmt.Errorf("--from-version cannot be combined with --summary")
+			}
+			if diff != "" && (summary || tmpl != nil || fromVersion != "") {
+				return fmt.Errorf("--diff cannot be combined with --summary, --from-version or a template")
+			}
+
 			engine, err := getEngine(cmd.Context())
 			if err != nil {
 				return err
 			}
 
-			status, err := engine.GetStatus(cmd.Context())
-			if err != nil {
-				return fmt.Errorf("%s: %w", ErrFailedToGetStatus, err)
+			if diff != "" || snapshot != "" {
+				if err := writeSnapshotOrDiff(cmd, engine, format, snapshot, diff); err != nil {
+					return err
+				}
 			}
-
-			out := cmd.OutOrStdout()
-
-			switch strings.ToLower(format) {
-			case "json":
-				return renderJSON(out, status)
-			case "table":
-				renderTable(out, status)
-				return nil
-			default:
-				return fmt.Errorf("unsupported output format: %s", format)
+			if diff == "" {
+				if err := writeStatus(cmd, engine, format, summary, tmpl, fromVersion); err != nil {
+					return err
+				}
+			}
+			if strict {
+				if err := engine.Validate(cmd.Context()); err != nil {
+					return fmt.Errorf("%w: %w", ErrChecksumDrift, err)
+				}
 			}
+			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "output", "o", "table", "Output format (table, json)")
+	cmd.Flags().StringVarP(&format, "output", "o", render.FormatTable,
+		strings.TrimSuffix(render.FlagUsage, ")")+", "+formatTemplate+")")
+	cmd.Flags().StringVar(&tmplString, "template-string", "",
+		`Go template executed against the status list; \n and \t are unescaped (implies -o template)`)
+	cmd.Flags().StringVar(&templateFile, "template-file", "", "File holding the Go template (implies -o template)")
+	cmd.Flags().BoolVar(&summary, "summary", false,
+		"Print one line like applied=12 pending=3 dirty=false (table output) or the same fields as JSON")
+	cmd.Flags().StringVar(&fromVersion, "from-version", "",
+		"Only show versions at or after this one, in engine version order")
+	cmd.Flags().StringVar(&snapshot, "snapshot", "",
+		"Also save the full status to this file, in the same JSON as -o json")
+	cmd.Flags().StringVar(&diff, "diff", "",
+		"Show what changed since this saved snapshot instead of the status: newly applied, "+
+			"newly pending and checksum changes")
+	cmd.Flags().BoolVar(&strict, "strict-checksum", false,
+		"Exit non-zero after printing when an applied migration's checksum no longer matches its code")
 	return cmd
 }
 
-func renderJSON(w io.Writer, status []migration.MigrationStatus) error {
-	encoder := jsonutil.NewEncoder(w)
-	encoder.SetIndent("", "  ")
-	return encoder.Encode(status)
+func writeStatus(
+	cmd *cobra.Command, engine *migration.Engine, format string, summary bool, tmpl *template.Template,
+	fromVersion string,
+) error {
+	if summary {
+		s, err := engine.Summary(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("%s: %w", ErrFailedToGetStatus, err)
+		}
+		return renderSummary(cmd.OutOrStdout(), format, s)
+	}
+
+	status, err := engine.GetStatus(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToGetStatus, err)
+	}
+	status = atOrAfterVersion(status, statusVersion, fromVersion, engine.CompareVersions)
+
+	if tmpl != nil {
+		return tmpl.Execute(cmd.OutOrStdout(), status)
+	}
+	return render.Write(cmd.OutOrStdout(), format, statusList(status))
+}
+
+// writeSnapshotOrDiff saves the full status to snapshot and prints the changes since the
+// diff snapshot, for whichever of the two is set. The diff is read before the snapshot is
+// written, so both may name the same file.
+func writeSnapshotOrDiff(cmd *cobra.Command, engine *migration.Engine, format, snapshot, diff string) error {
+	var old []migration.MigrationStatus
+	if diff != "" {
+		var err error
+		if old, err = loadStatusSnapshot(diff); err != nil {
+			return err
+		}
+	}
+
+	status, err := engine.GetStatus(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrFailedToGetStatus, err)
+	}
+	if snapshot != "" {
+		if err := saveStatusSnapshot(snapshot, status); err != nil {
+			return err
+		}
+	}
+	if diff == "" {
+		return nil
+	}
+	return render.Write(cmd.OutOrStdout(), format, statusDiffList(diffStatus(old, status)))
+}
+
+const formatTemplate = "template"
+
+// statusTemplateFuncs are available to --template-string and --template-file.
+var statusTemplateFuncs = template.FuncMap{
+	"appliedIcon": func(s migration.MigrationStatus) string {
+		if s.Applied {
+			return ui.Mark.String()
+		}
+		return " "
+	},
+	"appliedAt": func(s migration.MigrationStatus) string {
+		if s.AppliedAt == nil {
+			return "-"
+		}
+		return s.AppliedAt.Format("2006-01-02 15:04")
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
 }
 
-func renderTable(w io.Writer, status []migration.MigrationStatus) {
-	if len(status) == 0 {
-		fmt.Fprintln(w, "No migrations found.")
-		return
+// parseStatusTemplate parses exactly one of text and path. Shells keep \n and \t literal
+// inside single quotes, so they are unescaped in text.
+func parseStatusTemplate(text, path string) (*template.Template, error) {
+	switch {
+	case text != "" && path != "":
+		return nil, fmt.Errorf("use --template-string or --template-file, not both")
+	case path != "":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+	case text != "":
+		text = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(text)
+	default:
+		return nil, fmt.Errorf("-o template needs --template-string or --template-file")
 	}
 
-	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	tmpl, err := template.New("status").Funcs(statusTemplateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status template: %w", err)
+	}
+	return tmpl, nil
+}
 
-	const (
-		iconPending = "  [ ]"
-		iconApplied = "  \033[32m[✓]\033[0m"
-	)
+// renderSummary prints the key=value line for table output and an object otherwise.
+func renderSummary(w io.Writer, format string, s migration.StatusSummary) error {
+	if format == "" || format == render.FormatTable {
+		_, err := fmt.Fprintln(w, s.String())
+		return err
+	}
+	if format == render.FormatJSON || format == render.FormatJSONL {
+		return jsonutil.NewEncoder(w).Encode(s)
+	}
+	return fmt.Errorf("%w: --summary supports table, json and jsonl, not %s", render.ErrUnsupportedFormat, format)
+}
 
-	fmt.Fprintln(tw, "STATE\tVERSION\tAPPLIED AT\tDESCRIPTION")
-	fmt.Fprintln(tw, "-----\t-------\t----------\t-----------")
+func statusVersion(s migration.MigrationStatus) string { return s.Version }
 
+func statusList(status []migration.MigrationStatus) render.List {
+	list := render.List{
+		Columns: []string{"STATE", "VERSION", "APPLIED AT", "DESCRIPTION"},
+		Items:   status,
+		Empty:   "No migrations found.",
+	}
 	for _, s := range status {
-		state := iconPending
+		state := "  " + ui.Pending.String()
 		appliedAt := "-"
 
 		if s.Applied {
-			state = iconApplied
+			state = "  \033[32m" + ui.Applied.String() + "\033[0m"
 			if s.AppliedAt != nil {
 				appliedAt = s.AppliedAt.Format("2006-01-02 15:04")
 			}
 		}
 
-		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", state, s.Version, appliedAt, s.Description)
+		list.Rows = append(list.Rows, []string{state, s.Version, appliedAt, s.Description})
 	}
-
-	tw.Flush()
+	return list
 }
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+)
+
+const (
+	changeApplied  = "applied"
+	changePending  = "pending"
+	changeChecksum = "checksum"
+)
+
+// statusChange is one difference between a saved status snapshot and the current status.
+type statusChange struct {
+	Version     string `json:"version"`
+	Change      string `json:"change"`
+	Description string `json:"description"`
+}
+
+// saveStatusSnapshot writes status as `status -o json` prints it, so either can be passed
+// to --diff later.
+func saveStatusSnapshot(path string, status []migration.MigrationStatus) error {
+	var buf bytes.Buffer
+	if err := render.Write(&buf, render.FormatJSON, statusList(status)); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write status snapshot: %w", err)
+	}
+	return nil
+}
+
+func loadStatusSnapshot(path string) ([]migration.MigrationStatus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status snapshot: %w", err)
+	}
+	var status []migration.MigrationStatus
+	if err := jsonutil.Unmarshal(data, &status); err != nil {
+		return nil, fmt.Errorf("invalid status snapshot %s: %w", path, err)
+	}
+	return status, nil
+}
+
+// diffStatus lists, in current order, the versions applied or pending since old was taken
+// and the applied versions whose recorded checksum changed. A version missing from old
+// counts as a change when it is applied now; a pending one counts as newly pending.
+func diffStatus(old, current []migration.MigrationStatus) []statusChange {
+	before := make(map[string]migration.MigrationStatus, len(old))
+	for _, s := range old {
+		before[s.Version] = s
+	}
+
+	changes := []statusChange{}
+	for _, s := range current {
+		prev, known := before[s.Version]
+		change := ""
+		switch {
+		case s.Applied && (!known || !prev.Applied):
+			change = changeApplied
+		case !s.Applied && (!known || prev.Applied):
+			change = changePending
+		case s.Applied && prev.Checksum != "" && s.Checksum != prev.Checksum:
+			change = changeChecksum
+		}
+		if change != "" {
+			changes = append(changes, statusChange{Version: s.Version, Change: change, Description: s.Description})
+		}
+	}
+	return changes
+}
+
+func statusDiffList(changes []statusChange) render.List {
+	list := render.List{
+		Columns: []string{"CHANGE", "VERSION", "DESCRIPTION"},
+		Items:   changes,
+		Empty:   "No changes since the snapshot.",
+	}
+	for _, c := range changes {
+		list.Rows = append(list.Rows, []string{c.Change, c.Version, c.Description})
+	}
+	return list
+}
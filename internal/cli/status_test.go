@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+)
+
+func TestRenderSummary(t *testing.T) {
+	summary := migration.StatusSummary{Applied: 12, Pending: 3, Dirty: true}
+
+	tests := []struct {
+		format  string
+		want    string
+		wantErr error
+	}{
+		{format: "", want: "applied=12 pending=3 dirty=true\n"},
+		{format: render.FormatTable, want: "applied=12 pending=3 dirty=true\n"},
+		{format: render.FormatJSON, want: "{\"applied\":12,\"pending\":3,\"dirty\":true}\n"},
+		{format: render.FormatCSV, wantErr: render.ErrUnsupportedFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := renderSummary(&buf, tt.format, summary)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("renderSummary() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("renderSummary() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusTemplate(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	status := []migration.MigrationStatus{
+		{Version: "20240101_001", Description: "add users", Applied: true, AppliedAt: &appliedAt},
+		{Version: "20240102_001", Description: "add orders"},
+	}
+
+	text := `{{range .}}{{appliedIcon .}} {{.Version}} {{appliedAt .}} {{upper .Description}}\n{{end}}`
+	tmpl, err := parseStatusTemplate(text, "")
+	if err != nil {
+		t.Fatalf("parseStatusTemplate() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, status); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	want := "✓ 20240101_001 2024-01-02 03:04 ADD USERS\n  20240102_001 - ADD ORDERS\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	path := filepath.Join(t.TempDir(), "status.tmpl")
+	if err := os.WriteFile(path, []byte("{{len .}} migrations\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err = parseStatusTemplate("", path)
+	if err != nil {
+		t.Fatalf("parseStatusTemplate(file) error = %v", err)
+	}
+	buf.Reset()
+	if err := tmpl.Execute(&buf, status); err != nil || buf.String() != "2 migrations\n" {
+		t.Errorf("file template = %q, %v", buf.String(), err)
+	}
+
+	for name, args := range map[string][2]string{
+		"Parse error":  {"{{range .}}", ""},
+		"Unknown func": {"{{nope .}}", ""},
+		"Both sources": {"{{.}}", path},
+		"No template":  {"", ""},
+	} {
+		if _, err := parseStatusTemplate(args[0], args[1]); err == nil {
+			t.Errorf("%s: parseStatusTemplate(%q, %q) should fail", name, args[0], args[1])
+		}
+	}
+}
+
+func TestStatusListIcons(t *testing.T) {
+	t.Cleanup(func() { ui.SetASCII(false) })
+
+	status := []migration.MigrationStatus{
+		{Version: "20240101_001", Description: "add users", Applied: true},
+		{Version: "20240102_001", Description: "add orders"},
+	}
+	tests := []struct {
+		icons            string
+		applied, pending string
+	}{
+		{icons: "unicode", applied: "[✓]", pending: "[ ]"},
+		{icons: "ascii", applied: "[APPLIED]", pending: "[PENDING]"},
+		{icons: "emoji", applied: "✅", pending: "⏳"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.icons, func(t *testing.T) {
+			if err := applyIcons(tt.icons); err != nil {
+				t.Fatalf("applyIcons(%q) error = %v", tt.icons, err)
+			}
+			rows := statusList(status).Rows
+			if got := rows[0][0]; got != "  \033[32m"+tt.applied+"\033[0m" {
+				t.Errorf("applied state = %q, want %q", got, tt.applied)
+			}
+			if got := rows[1][0]; got != "  "+tt.pending {
+				t.Errorf("pending state = %q, want %q", got, tt.pending)
+			}
+		})
+	}
+
+	if err := applyIcons("fancy"); err == nil {
+		t.Error("applyIcons(\"fancy\") succeeded")
+	}
+}
+
+func TestAtOrAfterVersion(t *testing.T) {
+	status := []migration.MigrationStatus{
+		{Version: "2_seed"}, {Version: "9_backfill"}, {Version: "10_index"}, {Version: "11_cleanup"},
+	}
+
+	tests := []struct {
+		name    string
+		compare migration.VersionComparator
+		from    string
+		want    []string
+	}{
+		{name: "Lexical", compare: migration.CompareLexical, from: "9_backfill", want: []string{"9_backfill"}},
+		{name: "Lexical orders 10 before 9", compare: migration.CompareLexical, from: "10_index",
+			want: []string{"2_seed", "9_backfill", "10_index", "11_cleanup"}},
+		{name: "Numeric", compare: migration.CompareNumeric, from: "9_backfill",
+			want: []string{"9_backfill", "10_index", "11_cleanup"}},
+		{name: "Numeric from an unregistered version", compare: migration.CompareNumeric, from: "10",
+			want: []string{"10_index", "11_cleanup"}},
+		{name: "Empty keeps everything", compare: migration.CompareNumeric,
+			want: []string{"2_seed", "9_backfill", "10_index", "11_cleanup"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, s := range atOrAfterVersion(status, statusVersion, tt.from, tt.compare) {
+				got = append(got, s.Version)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatusSnapshotDiff(t *testing.T) {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	before := []migration.MigrationStatus{
+		{Version: "20240101_001", Description: "first", Applied: true, AppliedAt: &at, Checksum: "aaa"},
+		{Version: "20240102_001", Description: "second"},
+	}
+	path := filepath.Join(t.TempDir(), "before.json")
+	if err := saveStatusSnapshot(path, before); err != nil {
+		t.Fatalf("saveStatusSnapshot: %v", err)
+	}
+	old, err := loadStatusSnapshot(path)
+	if err != nil {
+		t.Fatalf("loadStatusSnapshot: %v", err)
+	}
+
+	after := slices.Clone(before)
+	after[1].Applied, after[1].AppliedAt, after[1].Checksum = true, &at, "bbb"
+	got := diffStatus(old, after)
+	want := []statusChange{{Version: "20240102_001", Change: changeApplied, Description: "second"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("diffStatus() = %+v, want %+v", got, want)
+	}
+
+	t.Run("Rollback, checksum change and new version", func(t *testing.T) {
+		current := []migration.MigrationStatus{
+			{Version: "20240101_001", Description: "first", Applied: true, AppliedAt: &at, Checksum: "ccc"},
+			{Version: "20240102_001", Description: "second"},
+			{Version: "20240103_001", Description: "third"},
+		}
+		got := diffStatus(after, current)
+		want := []statusChange{
+			{Version: "20240101_001", Change: changeChecksum, Description: "first"},
+			{Version: "20240102_001", Change: changePending, Description: "second"},
+			{Version: "20240103_001", Change: changePending, Description: "third"},
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("diffStatus() = %+v, want %+v", got, want)
+		}
+	})
+
+	if _, err := loadStatusSnapshot(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected an error for a missing snapshot")
+	}
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"sync"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/spf13/cobra"
+)
+
+// timingRecorder collects the phase timings the engine reports during a run, for
+// up --timings.
+type timingRecorder struct {
+	mu      sync.Mutex
+	timings []migration.PhaseTiming
+}
+
+// option returns the engine option feeding r, or nil when r is nil so NewEngine skips it.
+func (r *timingRecorder) option() migration.EngineOption {
+	if r == nil {
+		return nil
+	}
+	return migration.WithTimings(r.record)
+}
+
+func (r *timingRecorder) record(t migration.PhaseTiming) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.timings = append(r.timings, t)
+}
+
+func (r *timingRecorder) recorded() []migration.PhaseTiming {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]migration.PhaseTiming(nil), r.timings...)
+}
+
+// writeTimings prints the timings r recorded during the run; r is nil without --timings.
+func writeTimings(cmd *cobra.Command, format string, r *timingRecorder) error {
+	if r == nil {
+		return nil
+	}
+	return render.Write(cmd.OutOrStdout(), format, timingsList(r.recorded()))
+}
+
+type timingEntry struct {
+	Phase      string              `json:"phase"`
+	Version    string              `json:"version,omitempty"`
+	Direction  migration.Direction `json:"direction,omitempty"`
+	DurationMS float64             `json:"duration_ms"`
+	Failed     bool                `json:"failed,omitempty"`
+}
+
+// timingsList renders one row per phase in the order they finished, followed by the total.
+func timingsList(timings []migration.PhaseTiming) render.List {
+	list := render.List{Columns: []string{"PHASE", "VERSION", "DURATION", "FAILED"}, Empty: "No timings recorded."}
+	entries := make([]timingEntry, 0, len(timings))
+	var total time.Duration
+	for _, t := range timings {
+		entries = append(entries, timingEntry{
+			Phase:      t.Phase,
+			Version:    t.Version,
+			Direction:  t.Direction,
+			DurationMS: float64(t.Duration) / float64(time.Millisecond),
+			Failed:     t.Failed,
+		})
+		failed := ""
+		if t.Failed {
+			failed = "yes"
+		}
+		list.Rows = append(list.Rows, []string{t.Phase, t.Version, formatTiming(t.Duration), failed})
+		total += t.Duration
+	}
+	if len(list.Rows) > 0 {
+		list.Rows = append(list.Rows, []string{"total", "", formatTiming(total), ""})
+	}
+	list.Items = entries
+	return list
+}
+
+func formatTiming(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+)
+
+func TestTimingsList(t *testing.T) {
+	if (*timingRecorder)(nil).option() != nil {
+		t.Error("option() on a nil recorder should be nil so NewEngine skips it")
+	}
+
+	r := &timingRecorder{}
+
+	r.record(migration.PhaseTiming{Phase: migration.PhaseLock, Duration: 400 * time.Microsecond})
+	r.record(migration.PhaseTiming{Phase: migration.PhaseStatus, Duration: 3 * time.Millisecond})
+	r.record(migration.PhaseTiming{
+		Phase: migration.PhaseMigration, Version: "20240101_001", Direction: migration.DirectionUp,
+		Duration: 1200 * time.Millisecond,
+	})
+	r.record(migration.PhaseTiming{
+		Phase: migration.PhaseMigration, Version: "20240102_001", Direction: migration.DirectionUp,
+		Duration: 50 * time.Millisecond, Failed: true,
+	})
+	list := timingsList(r.recorded())
+
+	want := [][]string{
+		{"lock", "", "400µs", ""},
+		{"status", "", "3ms", ""},
+		{"migration", "20240101_001", "1.2s", ""},
+		{"migration", "20240102_001", "50ms", "yes"},
+		{"total", "", "1.253s", ""},
+	}
+	if len(list.Rows) != len(want) {
+		t.Fatalf("rows = %q, want %q", list.Rows, want)
+	}
+	for i := range want {
+		if strings.Join(list.Rows[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, list.Rows[i], want[i])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := render.Write(&buf, render.FormatJSON, list); err != nil {
+		t.Fatal(err)
+	}
+	var entries []timingEntry
+	if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+		t.Fatalf("JSON output %q: %v", buf.String(), err)
+	}
+	if len(entries) != 4 || entries[2].Version != "20240101_001" || entries[2].DurationMS != 1200 ||
+		!entries[3].Failed {
+		t.Errorf("entries = %+v", entries)
+	}
+}
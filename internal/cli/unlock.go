@@ -3,6 +3,7 @@ package cli
 import (
 	"fmt"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -10,9 +11,10 @@ func newUnlockCmd() *cobra.Command {
 	var confirm bool
 
 	cmd := &cobra.Command{
-		Use:   "unlock",
-		Short: "Release a stuck migration lock",
-		Long:  "Forcefully removes the distributed migration lock document so a new migration run can proceed.",
+		Use:         "unlock",
+		Short:       "Release a stuck migration lock",
+		Long:        "Forcefully removes the distributed migration lock document so a new migration run can proceed.",
+		Annotations: map[string]string{annotationMutating: "true"},
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			if !confirm && !promptConfirmation(cmd, "WARNING: This will release the migration lock and should "+
 				"only be used if no other instances are running. Continue? [y/N]: ") {
@@ -29,7 +31,7 @@ func newUnlockCmd() *cobra.Command {
 				return fmt.Errorf("failed to release migration lock: %w", err)
 			}
 
-			fmt.Fprintln(cmd.OutOrStdout(), "✅ Migration lock released.")
+			fmt.Fprintf(cmd.OutOrStdout(), "%s Migration lock released.\n", ui.OK)
 			return nil
 		},
 	}
@@ -2,28 +2,89 @@ package cli
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/render"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 )
 
 func newUpCmd() *cobra.Command {
 	var (
-		target string
-		dryRun bool
+		target   string
+		dryRun   bool
+		explain  bool
+		estimate bool
+		tags     []string
+		selected []string
+		expected []string
+		output   string
+		repair   bool
+		timed    bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "up",
-		Short: "Run pending migrations",
+		Use:         "up",
+		Short:       "Run pending migrations",
+		Annotations: map[string]string{annotationMutating: "true", annotationPreview: "dry-run,explain,estimate"},
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			var timings *timingRecorder
+			if timed {
+				timings = &timingRecorder{}
+			}
 			engine, err := getEngine(cmd.Context())
+			if repair || timed {
+				engine, err = engineWith(cmd, func(cfg *config.Config) {
+					cfg.RepairDescriptions = cfg.RepairDescriptions || repair
+				}, timings.option())
+			}
+			if err != nil {
+				return err
+			}
+
+			if len(tags) > 0 && target != "" {
+				return fmt.Errorf("--tags cannot be combined with --target")
+			}
+			if len(selected) > 0 && (target != "" || len(tags) > 0) {
+				return fmt.Errorf("--select cannot be combined with --target or --tags")
+			}
+			if explain {
+				if len(tags) > 0 || len(selected) > 0 {
+					return fmt.Errorf("--explain cannot be combined with --tags or --select")
+				}
+				entries, err := engine.ExplainPlan(cmd.Context(), migration.DirectionUp, target)
+				if err != nil {
+					return err
+				}
+				return render.Write(cmd.OutOrStdout(), render.FormatTable, explainList(entries))
+			}
+			if estimate {
+				if len(tags) > 0 || len(selected) > 0 {
+					return fmt.Errorf("--estimate cannot be combined with --tags or --select")
+				}
+				cost, err := engine.EstimatePlan(cmd.Context(), target)
+				if err != nil {
+					return err
+				}
+				return renderEstimate(cmd.OutOrStdout(), cost)
+			}
+
+			checksums, err := parseExpectedChecksums(expected)
 			if err != nil {
 				return err
 			}
+			if err := engine.VerifyChecksums(checksums); err != nil {
+				return err
+			}
 
-			plan, err := engine.Plan(cmd.Context(), migration.DirectionUp, target)
+			filter := migration.TagFilter(tags...)
+			if len(selected) > 0 {
+				filter = migration.VersionFilter(selected...)
+			}
+			plan, err := engine.PlanFiltered(cmd.Context(), migration.DirectionUp, target, filter)
 			if err != nil {
 				return err
 			}
@@ -31,28 +92,87 @@ func newUpCmd() *cobra.Command {
 				renderPlan(cmd.OutOrStdout(), "up", plan)
 				return nil
 			}
-			if len(plan) == 0 {
-				fmt.Fprintln(cmd.OutOrStdout(), "Database is already up to date.")
-				return nil
+			// An empty plan still runs, so drift and orphaned records are reported and
+			// --auto-repair-descriptions applies.
+			if len(plan) > 0 || len(selected) > 0 {
+				logIntent(target, tags)
 			}
 
-			logIntent(target)
-
-			if err := engine.Up(cmd.Context(), target); err != nil {
+			var res migration.RunResult
+			if len(selected) > 0 {
+				res, err = engine.UpSelectedWithResult(cmd.Context(), selected)
+			} else {
+				res, err = engine.UpWithResult(cmd.Context(), target, filter)
+			}
+			if err != nil {
+				if n := len(res.Executed); n > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Applied %d migration(s) before the failure: %s\n",
+						n, strings.Join(res.Executed, ", "))
+				}
+				_ = writeTimings(cmd, output, timings) // the run error is the one to report
 				return fmt.Errorf("%s: %w", ErrFailedToRun, err)
 			}
 
-			fmt.Fprintln(cmd.OutOrStdout(), "✨ Database is up to date!")
-			return nil
+			fmt.Fprintln(cmd.OutOrStdout(), upSummary(res))
+			return writeTimings(cmd, output, timings)
 		},
 	}
 
 	cmd.Flags().StringVar(&target, "target", "", "Target version to migrate up to")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print planned migrations without executing")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Show every migration with whether it would run and why")
+	cmd.Flags().BoolVar(&estimate, "estimate", false,
+		"Show the estimated cost of each pending migration without running it")
+	cmd.Flags().BoolVar(&repair, "auto-repair-descriptions", false,
+		"Update stored checksums of applied migrations whose description is the only change "+
+			"(overrides MIGRATIONS_AUTO_REPAIR_DESCRIPTIONS)")
+	cmd.Flags().StringSliceVar(&selected, "select", nil,
+		"Run only these pending versions, in order, even if earlier ones are pending (e.g. v1,v3)")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Only run pending migrations with any of these tags (e.g. data,index)")
+	cmd.Flags().BoolVar(&timed, "timings", false,
+		"Print how long the lock, the status read and each migration took")
+	cmd.Flags().StringVarP(&output, "output", "o", render.FormatTable,
+		"Format of the --timings report ("+strings.Join(render.Formats, ", ")+")")
+	cmd.Flags().StringArrayVar(&expected, "expect-checksum", nil,
+		"Abort unless version's checksum matches, as listed by `catalog --output json` (version=hash, repeatable)")
 	return cmd
 }
 
-func logIntent(target string) {
+// upSummary tells a run that had nothing pending apart from one that applied migrations,
+// and mentions those left pending because they are scoped to other environments.
+func upSummary(res migration.RunResult) string {
+	if res.Pending == 0 {
+		return "Already up to date (0 pending)."
+	}
+	msg := fmt.Sprintf("%s Applied %d migration(s).", ui.Done, len(res.Executed))
+	if n := len(res.Skipped); n > 0 {
+		msg += fmt.Sprintf(" %d left pending for other environments.", n)
+	}
+	return msg
+}
+
+// parseExpectedChecksums turns repeated version=hash flag values into a map.
+func parseExpectedChecksums(values []string) (map[string]string, error) {
+	checksums := make(map[string]string, len(values))
+	for _, v := range values {
+		version, hash, ok := strings.Cut(v, "=")
+		version, hash = strings.TrimSpace(version), strings.TrimSpace(hash)
+		if !ok || version == "" || hash == "" {
+			return nil, fmt.Errorf("%w %q: want version=hash", ErrInvalidChecksumArg, v)
+		}
+		if prev, dup := checksums[version]; dup && prev != hash {
+			return nil, fmt.Errorf("%w %q: %s already expects %s", ErrInvalidChecksumArg, v, version, prev)
+		}
+		checksums[version] = hash
+	}
+	return checksums, nil
+}
+
+func logIntent(target string, tags []string) {
+	if len(tags) > 0 {
+		zap.S().Infow("Running pending migrations matching tags", "tags", tags)
+		return
+	}
 	if target != "" {
 		zap.S().Infow("Running migrations up to target", "target", target)
 		return
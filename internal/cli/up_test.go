@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+)
+
+func TestParseExpectedChecksums(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "None", want: map[string]string{}},
+		{
+			name:   "Several",
+			values: []string{"20240101_001=abc", " 20240102_001 = def "},
+			want:   map[string]string{"20240101_001": "abc", "20240102_001": "def"},
+		},
+		{name: "Repeated with the same hash", values: []string{"v1=abc", "v1=abc"}, want: map[string]string{"v1": "abc"}},
+		{name: "Missing separator", values: []string{"20240101_001"}, wantErr: true},
+		{name: "Missing hash", values: []string{"20240101_001="}, wantErr: true},
+		{name: "Missing version", values: []string{"=abc"}, wantErr: true},
+		{name: "Conflicting hashes", values: []string{"v1=abc", "v1=def"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseExpectedChecksums(tt.values)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidChecksumArg) {
+					t.Fatalf("parseExpectedChecksums(%q) error = %v, want %v", tt.values, err, ErrInvalidChecksumArg)
+				}
+				return
+			}
+			if err != nil || !maps.Equal(got, tt.want) {
+				t.Fatalf("parseExpectedChecksums(%q) = %v, %v; want %v", tt.values, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpSummary(t *testing.T) {
+	ui.SetASCII(true)
+	t.Cleanup(func() { ui.SetASCII(false) })
+
+	tests := []struct {
+		name string
+		res  migration.RunResult
+		want string
+	}{
+		{name: "Nothing pending", want: "Already up to date (0 pending)."},
+		{
+			name: "Some pending",
+			res:  migration.RunResult{Pending: 3, Executed: []string{"v1", "v2", "v3"}},
+			want: "[DONE] Applied 3 migration(s).",
+		},
+		{
+			name: "Some skipped",
+			res:  migration.RunResult{Pending: 3, Executed: []string{"v1"}, Skipped: []string{"v2", "v3"}},
+			want: "[DONE] Applied 1 migration(s). 2 left pending for other environments.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.res.Direction = migration.DirectionUp
+			if got := upSummary(tt.res); got != tt.want {
+				t.Errorf("upSummary(%+v) = %q, want %q", tt.res, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,18 @@
 package cli
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
+	"io"
 	"strings"
 )
 
 func promptConfirmation(cmd *cobra.Command, message string) bool {
 	fmt.Fprint(cmd.OutOrStdout(), message)
 
-	reader := bufio.NewReader(cmd.InOrStdin())
-	input, err := reader.ReadString('\n')
+	input, err := readLine(cmd.InOrStdin())
 	if err != nil {
 		zap.S().Errorw("Failed to read confirmation", "error", err)
 		return false
@@ -22,3 +22,61 @@ func promptConfirmation(cmd *cobra.Command, message string) bool {
 	return response == "y" || response == "yes"
 }
 
+// readLine reads up to and including the next newline one byte at a time, so repeated
+// prompts on the same input do not lose answers to a read-ahead buffer.
+func readLine(r io.Reader) (string, error) {
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			line = append(line, b[0])
+			if b[0] == '\n' {
+				return string(line), nil
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) && len(line) > 0 {
+				return string(line), nil
+			}
+			return string(line), err
+		}
+	}
+}
+
+// confirmDatabase guards operations that touch every migration. The user must repeat the
+// database name exactly, either through token (the --confirm flag) or at the prompt.
+// assumeYes means the run is non-interactive, so only the token can confirm it.
+func confirmDatabase(cmd *cobra.Command, database, token string, assumeYes bool, action string) error {
+	if token == "" && assumeYes {
+		return fmt.Errorf("%w: --yes does not confirm this; pass --confirm %s", ErrDatabaseNotConfirmed, database)
+	}
+	if token == "" {
+		fmt.Fprintf(cmd.OutOrStdout(), "WARNING: You are about to %s in %q. Type the database name to continue: ",
+			action, database)
+		input, err := readLine(cmd.InOrStdin())
+		if err != nil {
+			zap.S().Errorw("Failed to read confirmation", "error", err)
+		}
+		token = strings.TrimSpace(input)
+	}
+	if database == "" || token != database {
+		return fmt.Errorf("%w: got %q", ErrDatabaseNotConfirmed, token)
+	}
+	return nil
+}
+
+// atOrAfterVersion keeps the items whose version orders at or after from under compare,
+// normally the engine's CompareVersions. An empty from keeps everything.
+func atOrAfterVersion[T any](items []T, version func(T) string, from string, compare func(a, b string) int) []T {
+	if from == "" {
+		return items
+	}
+	kept := make([]T, 0, len(items))
+	for _, item := range items {
+		if compare(version(item), from) >= 0 {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
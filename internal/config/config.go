@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"reflect"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 	"github.com/joho/godotenv"
@@ -12,9 +15,32 @@ import (
 
 type Config struct {
 	MongoURL             string `env:"MONGO_URL" envDefault:"mongodb://localhost:27017"`
-	Database             string `env:"MONGO_DATABASE,required"`
+	Database             string `env:"MONGO_DATABASE"`
 	MigrationsPath       string `env:"MIGRATIONS_PATH" envDefault:"./migrations"`
 	MigrationsCollection string `env:"MIGRATIONS_COLLECTION" envDefault:"schema_migrations"`
+	VersionFormat        string `env:"MIGRATIONS_VERSION_FORMAT" envDefault:"timestamp"`
+	VersionOrder         string `env:"MIGRATIONS_VERSION_ORDER" envDefault:"lexical"`
+	Environment          string `env:"MIGRATIONS_ENVIRONMENT"`
+	CausalConsistency    bool   `env:"MIGRATIONS_CAUSAL_CONSISTENCY" envDefault:"false"`
+	MaxParallel          int    `env:"MIGRATIONS_MAX_PARALLEL" envDefault:"1"`
+	BulkConcurrency      int    `env:"MIGRATIONS_BULK_CONCURRENCY" envDefault:"4"`
+	MaxOpsPerSec         int    `env:"MIGRATIONS_MAX_OPS_PER_SEC"`
+	RecordWriteConcern   string `env:"MIGRATIONS_WRITE_CONCERN" envDefault:"majority"`
+	RecordReadConcern    string `env:"MIGRATIONS_READ_CONCERN"`
+	ReadOnly             bool   `env:"MIGRATIONS_READ_ONLY" envDefault:"false"`
+	ReadFromSecondary    bool   `env:"MONGO_READ_FROM_SECONDARY" envDefault:"false"`
+	NoTransaction        bool   `env:"MIGRATIONS_NO_TRANSACTION" envDefault:"false"`
+	ExpectedDatabase     string `env:"EXPECTED_DATABASE"`
+	AuditCollection      string `env:"MIGRATIONS_AUDIT_COLLECTION"`
+	ForbidDrops          bool   `env:"MIGRATIONS_FORBID_DROPS" envDefault:"false"`
+	RequireReversible    bool   `env:"MIGRATIONS_REQUIRE_REVERSIBLE" envDefault:"false"`
+	FailOnOrphaned       bool   `env:"MIGRATIONS_FAIL_ON_ORPHANED" envDefault:"false"`
+	RepairDescriptions   bool   `env:"MIGRATIONS_AUTO_REPAIR_DESCRIPTIONS" envDefault:"false"`
+	StrictChecksums      bool   `env:"MIGRATIONS_STRICT_CHECKSUMS" envDefault:"false"`
+	FloorVersion         string `env:"MIGRATIONS_FLOOR_VERSION"`
+	MaintenanceWindow    string `env:"MIGRATIONS_MAINTENANCE_WINDOW"`
+	MaintenanceTimezone  string `env:"MIGRATIONS_MAINTENANCE_TZ" envDefault:"UTC"`
+	Icons                string `env:"MMT_ICONS"`
 	Username             string `env:"MONGO_USERNAME"`
 	Password             string `env:"MONGO_PASSWORD"`
 	MongoAuthSource      string `env:"MONGO_AUTH_SOURCE" envDefault:"admin"`
@@ -24,12 +50,36 @@ type Config struct {
 	MinPoolSize          int    `env:"MONGO_MIN_POOL_SIZE" envDefault:"1"`
 	Timeout              int    `env:"MONGO_TIMEOUT" envDefault:"60"`
 
+	ConnectRetries    int           `env:"MONGO_CONNECT_RETRIES" envDefault:"5"`
+	ConnectRetryDelay time.Duration `env:"MONGO_CONNECT_RETRY_DELAY" envDefault:"1s"`
+	ConnectWait       time.Duration `env:"MONGO_CONNECT_WAIT"`
+	StaleLockTimeout  time.Duration `env:"MIGRATIONS_STALE_LOCK_TIMEOUT"`
+	MCPHealthInterval time.Duration `env:"MCP_HEALTH_INTERVAL"`
+
+	Preflight []string `env:"MIGRATIONS_PREFLIGHT" envSeparator:","`
+	// ConnectionOptions are added to the MONGO_URL query string, e.g. retryWrites=true,w=majority.
+	ConnectionOptions map[string]string `env:"MONGO_CONNECTION_OPTIONS" envSeparator:"," envKeyValSeparator:"="`
+
 	GoogleDocsEnabled     bool   `env:"GOOGLE_DOCS_ENABLED" envDefault:"false"`
 	GoogleCredentialsPath string `env:"GOOGLE_CREDENTIALS_PATH"`
 	GoogleCredentialsJSON string `env:"GOOGLE_CREDENTIALS_JSON"`
 }
 
+// Options tune how Load resolves configuration values.
+type Options struct {
+	// AllowUnset leaves ${VAR} placeholders literal when VAR is not set instead of failing.
+	AllowUnset bool
+	// Database, when set, replaces MONGO_DATABASE.
+	Database string
+}
+
+var placeholderPattern = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
 func Load(envFiles ...string) (*Config, error) {
+	return LoadWith(Options{}, envFiles...)
+}
+
+func LoadWith(opts Options, envFiles ...string) (*Config, error) {
 	for _, file := range envFiles {
 		if _, err := os.Stat(file); err == nil {
 			_ = godotenv.Load(file)
@@ -41,6 +91,13 @@ func Load(envFiles ...string) (*Config, error) {
 		return nil, fmt.Errorf("env parse error: %w", err)
 	}
 
+	if err := cfg.expandPlaceholders(opts.AllowUnset); err != nil {
+		return nil, err
+	}
+	if opts.Database != "" {
+		cfg.Database = opts.Database
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
@@ -48,6 +105,72 @@ func Load(envFiles ...string) (*Config, error) {
 	return cfg, nil
 }
 
+// expandPlaceholders replaces ${VAR} with the value of VAR in every string field, every
+// element of a []string field and every value of a map[string]string field.
+// "$$" yields a literal "$"; a bare $VAR is left untouched so secrets containing "$" survive.
+func (c *Config) expandPlaceholders(allowUnset bool) error {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() {
+			continue
+		}
+		if err := expandField(field, allowUnset); err != nil {
+			return fmt.Errorf("%s: %w", t.Field(i).Name, err)
+		}
+	}
+	return nil
+}
+
+func expandField(field reflect.Value, allowUnset bool) error {
+	switch {
+	case field.Kind() == reflect.String:
+		expanded, err := expandValue(field.String(), allowUnset)
+		if err != nil {
+			return err
+		}
+		field.SetString(expanded)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		for j := 0; j < field.Len(); j++ {
+			if err := expandField(field.Index(j), allowUnset); err != nil {
+				return err
+			}
+		}
+	case field.Kind() == reflect.Map && field.Type().Elem().Kind() == reflect.String:
+		iter := field.MapRange()
+		for iter.Next() {
+			expanded, err := expandValue(iter.Value().String(), allowUnset)
+			if err != nil {
+				return fmt.Errorf("%v: %w", iter.Key(), err)
+			}
+			field.SetMapIndex(iter.Key(), reflect.ValueOf(expanded).Convert(field.Type().Elem()))
+		}
+	}
+	return nil
+}
+
+func expandValue(value string, allowUnset bool) (string, error) {
+	var missing []string
+	out := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		if match == "$$" {
+			return "$"
+		}
+		name := match[2 : len(match)-1]
+		if val, ok := os.LookupEnv(name); ok {
+			return val
+		}
+		if !allowUnset {
+			missing = append(missing, name)
+		}
+		return match
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("referenced environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return out, nil
+}
+
 func (c *Config) GetConnectionString() string {
 	u, err := url.Parse(c.MongoURL)
 	if err != nil {
@@ -72,14 +195,53 @@ func (c *Config) GetConnectionString() string {
 		q.Set("ssl", "true")
 	}
 
+	for key, value := range c.ConnectionOptions {
+		if !hasParam(q, key) {
+			q.Set(key, value)
+		}
+	}
+
+	// The driver rejects a query without a path, as in mongodb://host:27017?w=1.
+	if u.Path == "" && len(q) > 0 {
+		u.Path = "/"
+	}
 	u.RawQuery = q.Encode()
 	return u.String()
 }
 
+// hasParam reports whether q already sets key. Connection string options are
+// case-insensitive, so appname and appName are the same option.
+func hasParam(q url.Values, key string) bool {
+	for k := range q {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsProduction reports whether the configured environment is production.
+func (c *Config) IsProduction() bool {
+	env := strings.ToLower(strings.TrimSpace(c.Environment))
+	return env == "production" || env == "prod"
+}
+
+// Host returns the host portion of MongoURL, or an empty string if it cannot be parsed.
+func (c *Config) Host() string {
+	u, err := url.Parse(c.MongoURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
 func (c *Config) Validate() error {
 	if c.Database == "" {
 		return fmt.Errorf("MONGO_DATABASE is required")
 	}
+	if _, err := c.ParseMaintenanceWindow(); err != nil {
+		return err
+	}
 	if c.GoogleDocsEnabled {
 		if c.GoogleCredentialsPath == "" && c.GoogleCredentialsJSON == "" {
 			return fmt.Errorf("google Docs enabled but credentials missing")
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -32,6 +36,82 @@ func TestLoadDefaults(t *testing.T) {
 	assert(t, cfg.MigrationsCollection, "schema_migrations", "Default MigrationsCollection")
 }
 
+func TestGetConnectionStringOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		options map[string]string
+		want    map[string]string
+	}{
+		{
+			name: "No options",
+			url:  "mongodb://db.example.com:27017/?replicaSet=rs0",
+			want: map[string]string{"replicaSet": "rs0"},
+		},
+		{
+			name:    "Options are merged",
+			url:     "mongodb://db.example.com:27017/?replicaSet=rs0",
+			options: map[string]string{"retryWrites": "true", "w": "majority", "appName": "nightly"},
+			want:    map[string]string{"replicaSet": "rs0", "retryWrites": "true", "w": "majority", "appName": "nightly"},
+		},
+		{
+			name:    "URL wins over options",
+			url:     "mongodb://db.example.com:27017/?w=1&retryWrites=false",
+			options: map[string]string{"w": "majority", "retryWrites": "true"},
+			want:    map[string]string{"w": "1", "retryWrites": "false"},
+		},
+		{
+			name:    "Keys compare without case",
+			url:     "mongodb://db.example.com:27017/?appname=legacy&RETRYWRITES=false",
+			options: map[string]string{"retryWrites": "true"},
+			want:    map[string]string{"appname": "legacy", "RETRYWRITES": "false"},
+		},
+		{
+			name:    "URL without a path",
+			url:     "mongodb://db.example.com:27017",
+			options: map[string]string{"w": "majority"},
+			want:    map[string]string{"w": "majority"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{MongoURL: tt.url, ConnectionOptions: tt.options}
+			u, err := url.Parse(cfg.GetConnectionString())
+			if err != nil {
+				t.Fatalf("GetConnectionString() is not a URL: %v", err)
+			}
+			if u.Path != "/" {
+				t.Errorf("path = %q, want /", u.Path)
+			}
+			got := map[string]string{}
+			for key, values := range u.Query() {
+				if len(values) != 1 {
+					t.Errorf("%s set %d times, want once", key, len(values))
+				}
+				got[key] = values[0]
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("query = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConnectionOptions(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "testdb")
+	t.Setenv("MONGO_CONNECTION_OPTIONS", "retryWrites=true,w=majority")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+	want := map[string]string{"retryWrites": "true", "w": "majority"}
+	if !reflect.DeepEqual(cfg.ConnectionOptions, want) {
+		t.Errorf("ConnectionOptions = %v, want %v", cfg.ConnectionOptions, want)
+	}
+}
+
 func TestValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -72,9 +152,145 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestLoadExpandsPlaceholders(t *testing.T) {
+	t.Setenv("SECRET_HOST", "db.internal:27017")
+	t.Setenv("DB_SUFFIX", "prod")
+	t.Setenv("MONGO_URL", "mongodb://${SECRET_HOST}/?appName=tool")
+	t.Setenv("MONGO_DATABASE", "orders_${DB_SUFFIX}")
+	t.Setenv("MONGO_PASSWORD", "pa$$word$plain")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	assert(t, cfg.MongoURL, "mongodb://db.internal:27017/?appName=tool", "MongoURL")
+	assert(t, cfg.Database, "orders_prod", "Database")
+	assert(t, cfg.Password, "pa$word$plain", "Password")
+}
+
+func TestLoadExpandsPlaceholdersInListsAndMaps(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "db")
+	t.Setenv("CHECK_DIR", "/opt/checks")
+	t.Setenv("WRITE_CONCERN", "majority")
+	t.Setenv("MIGRATIONS_PREFLIGHT", "${CHECK_DIR}/disk.sh,plain")
+	t.Setenv("MONGO_CONNECTION_OPTIONS", "w=${WRITE_CONCERN},retryWrites=true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() failed: %v", err)
+	}
+
+	if len(cfg.Preflight) != 2 {
+		t.Fatalf("Preflight = %q, want 2 entries", cfg.Preflight)
+	}
+	assert(t, cfg.Preflight[0], "/opt/checks/disk.sh", "Preflight[0]")
+	assert(t, cfg.Preflight[1], "plain", "Preflight[1]")
+	assert(t, cfg.ConnectionOptions["w"], "majority", "ConnectionOptions[w]")
+	assert(t, cfg.ConnectionOptions["retryWrites"], "true", "ConnectionOptions[retryWrites]")
+}
+
+func TestLoadMissingPlaceholderInMap(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "db")
+	t.Setenv("MONGO_CONNECTION_OPTIONS", "w=${UNSET_CONCERN_FOR_TEST}")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "UNSET_CONCERN_FOR_TEST") {
+		t.Fatalf("Load() error = %v, want error naming the missing variable", err)
+	}
+}
+
+func TestLoadMissingPlaceholder(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "db")
+	t.Setenv("MONGO_URL", "mongodb://${UNSET_HOST_FOR_TEST}/")
+
+	_, err := Load()
+	if err == nil || !strings.Contains(err.Error(), "UNSET_HOST_FOR_TEST") {
+		t.Fatalf("Load() error = %v, want error naming the missing variable", err)
+	}
+}
+
+func TestLoadAllowUnsetKeepsPlaceholder(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "db")
+	t.Setenv("MONGO_URL", "mongodb://${UNSET_HOST_FOR_TEST}/")
+
+	cfg, err := LoadWith(Options{AllowUnset: true})
+	if err != nil {
+		t.Fatalf("LoadWith() failed: %v", err)
+	}
+	assert(t, cfg.MongoURL, "mongodb://${UNSET_HOST_FOR_TEST}/", "MongoURL")
+}
+
+func TestLoadDatabaseOverride(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "from_env")
+
+	cfg, err := LoadWith(Options{Database: "from_flag"})
+	if err != nil {
+		t.Fatalf("LoadWith() failed: %v", err)
+	}
+	assert(t, cfg.Database, "from_flag", "Database")
+}
+
+func TestLoadDatabaseOverrideSatisfiesValidation(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "")
+
+	cfg, err := LoadWith(Options{Database: "from_flag"})
+	if err != nil {
+		t.Fatalf("LoadWith() failed: %v", err)
+	}
+	assert(t, cfg.Database, "from_flag", "Database")
+}
+
+func TestLoadDatabaseOverrideWithoutEnv(t *testing.T) {
+	t.Setenv("MONGO_DATABASE", "")
+	os.Unsetenv("MONGO_DATABASE")
+
+	cfg, err := LoadWith(Options{Database: "from_flag"})
+	if err != nil {
+		t.Fatalf("LoadWith() failed: %v", err)
+	}
+	assert(t, cfg.Database, "from_flag", "Database")
+
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "MONGO_DATABASE is required") {
+		t.Fatalf("Load() error = %v, want the missing database error", err)
+	}
+}
+
 func assert(t *testing.T, got, want, field string) {
 	t.Helper()
 	if got != want {
 		t.Errorf("%s: got %q, want %q", field, got, want)
 	}
 }
+
+func TestWriteTemplate(t *testing.T) {
+	var b strings.Builder
+	if err := WriteTemplate(&b); err != nil {
+		t.Fatalf("WriteTemplate() error = %v", err)
+	}
+	out := b.String()
+
+	typ := reflect.TypeOf(Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		key, _, _ := strings.Cut(typ.Field(i).Tag.Get("env"), ",")
+		if key == "" {
+			continue
+		}
+		if !strings.Contains(out, key+"=") {
+			t.Errorf("template is missing %s", key)
+		}
+		if fieldDocs[key] == "" {
+			t.Errorf("%s has no description", key)
+		}
+	}
+
+	for _, want := range []string{
+		"\nMONGO_DATABASE=\n",
+		"# MONGO_URL=mongodb://localhost:27017\n",
+		"# MONGO_MAX_POOL_SIZE=10\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("template is missing %q", want)
+		}
+	}
+}
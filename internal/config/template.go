@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// fieldDocs describes each environment variable for the generated .env template.
+var fieldDocs = map[string]string{
+	"MONGO_URL":                     "MongoDB connection string; credentials may also go in MONGO_USERNAME/MONGO_PASSWORD",
+	"MONGO_DATABASE":                "Database to migrate (required)",
+	"MIGRATIONS_PATH":               "Directory where `create` writes new migration files",
+	"MIGRATIONS_COLLECTION":         "Collection that records applied migrations",
+	"MIGRATIONS_VERSION_FORMAT":     "Version stamp for new migrations: timestamp, sequence or semver",
+	"MIGRATIONS_VERSION_ORDER":      "How versions are ordered: lexical, or numeric for unpadded numbers such as semver",
+	"MIGRATIONS_ENVIRONMENT":        "Deployment environment; filters scoped migrations; production needs confirmation",
+	"MIGRATIONS_CAUSAL_CONSISTENCY": "Run all migrations of a run in one causally consistent session",
+	"MIGRATIONS_MAX_PARALLEL":       "How many Independent migrations may run at once",
+	"MIGRATIONS_BULK_CONCURRENCY":   "How many BulkWriter batches may be in flight at once across a run",
+	"MIGRATIONS_MAX_OPS_PER_SEC":    "Cap BulkWriter and DeleteInBatches writes per second across a run; 0 for no cap",
+	"MIGRATIONS_WRITE_CONCERN":      "Write concern for migration records: majority, a number, or empty for the client's",
+	"MIGRATIONS_READ_CONCERN":       "Read concern for migration records, e.g. majority",
+	"MIGRATIONS_READ_ONLY":          "Refuse every command that writes migration records",
+	"MIGRATIONS_NO_TRANSACTION":     "Run migrations without a transaction instead of trying one first",
+	"MIGRATIONS_MAINTENANCE_WINDOW": "When mutating commands may run, e.g. Mon-Fri 22:00-02:00; empty allows any time",
+	"MIGRATIONS_MAINTENANCE_TZ":     "IANA time zone of the maintenance window, e.g. Europe/Berlin",
+	"MMT_ICONS":                     "Icon set for status and result lines: unicode (default), ascii or emoji",
+	"MIGRATIONS_FAIL_ON_ORPHANED":   "Refuse up and down while a record has no migration in the code",
+	"MIGRATIONS_STRICT_CHECKSUMS":   "Reject applied records whose checksum predates checksum-gen instead of warning",
+	"MIGRATIONS_FLOOR_VERSION":      "Never roll back this version or older ones without --ignore-floor",
+	"MONGO_CONNECTION_OPTIONS":      "Extra connection string options, e.g. retryWrites=true,w=majority; MONGO_URL wins",
+	"MONGO_READ_FROM_SECONDARY":     "Read from a secondary and refuse every command that writes; implies read-only",
+	"EXPECTED_DATABASE":             "Abort when MONGO_DATABASE resolves to anything else",
+	"MIGRATIONS_AUDIT_COLLECTION":   "Collection that records every migration attempt, including failures",
+	"MIGRATIONS_FORBID_DROPS":       "Refuse Drop through SafeDatabase during up",
+	"MIGRATIONS_REQUIRE_REVERSIBLE": "Refuse to apply migrations that declare they cannot be rolled back",
+	"MONGO_USERNAME":                "Username added to MONGO_URL when it has none",
+	"MONGO_PASSWORD":                "Password for MONGO_USERNAME",
+	"MONGO_AUTH_SOURCE":             "Authentication database",
+	"MONGO_SSL_ENABLED":             "Connect with TLS",
+	"MONGO_SSL_INSECURE":            "Skip TLS certificate verification (development only)",
+	"MONGO_MAX_POOL_SIZE":           "Maximum connections in the driver pool",
+	"MONGO_MIN_POOL_SIZE":           "Minimum connections kept open in the driver pool",
+	"MONGO_TIMEOUT":                 "Connection and server selection timeout in seconds",
+	"MONGO_CONNECT_RETRIES":         "Attempts for the initial connection",
+	"MONGO_CONNECT_RETRY_DELAY":     "Delay between connection attempts, e.g. 1s",
+	"MONGO_CONNECT_WAIT":            "Keep retrying the initial connection for up to this long, e.g. 30s",
+	"MIGRATIONS_STALE_LOCK_TIMEOUT": "Delete a migration lock older than this when acquiring it, e.g. 5m",
+	"MCP_HEALTH_INTERVAL":           "How often the MCP server pings MongoDB and reconnects if needed, e.g. 30s",
+	"MIGRATIONS_PREFLIGHT":          "Checks run before up, e.g. collection:users,index:users.email_1,documents:users",
+	"GOOGLE_DOCS_ENABLED":           "Enable the Google Docs integration",
+	"GOOGLE_CREDENTIALS_PATH":       "Path to a Google service account key file",
+	"GOOGLE_CREDENTIALS_JSON":       "Google service account key as inline JSON",
+
+	"MIGRATIONS_AUTO_REPAIR_DESCRIPTIONS": "Let up update stored checksums when only a migration's description changed",
+}
+
+// requiredVars are the variables Validate insists on. They are not tagged required for the
+// env parser because a flag such as --database may still supply them after parsing.
+var requiredVars = map[string]bool{"MONGO_DATABASE": true}
+
+// WriteTemplate writes a commented .env file listing every Config variable with its
+// default. Required variables are left uncommented so they stand out.
+func WriteTemplate(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# mongo-tool configuration. Uncomment and edit the values you need.\n")
+
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("env"), ",")
+		if key == "" {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n# %s\n", fieldDocs[key])
+		prefix := "# "
+		if requiredVars[key] {
+			prefix = ""
+		}
+		fmt.Fprintf(&b, "%s%s=%s\n", prefix, key, field.Tag.Get("envDefault"))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Window is a recurring maintenance window such as "22:00-02:00", "Sat,Sun 01:00-05:00"
+// or "Mon-Fri 22:00-02:00". Without days it opens every day. A window whose end is not
+// after its start runs past midnight, and its days name the day it opens.
+type Window struct {
+	spec       string
+	days       [7]bool
+	start, end time.Duration
+	loc        *time.Location
+}
+
+// ParseWindow parses spec in the IANA time zone tz; an empty tz means UTC.
+func ParseWindow(spec, tz string) (*Window, error) {
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance window time zone %q: %w", tz, err)
+	}
+	w := &Window{spec: strings.TrimSpace(spec), loc: loc}
+
+	fields := strings.Fields(w.spec)
+	switch len(fields) {
+	case 1:
+		for d := range w.days {
+			w.days[d] = true
+		}
+	case 2:
+		if err := w.parseDays(fields[0]); err != nil {
+			return nil, err
+		}
+		fields = fields[1:]
+	default:
+		return nil, fmt.Errorf("maintenance window %q: want [days] HH:MM-HH:MM", spec)
+	}
+
+	from, to, ok := strings.Cut(fields[0], "-")
+	if !ok {
+		return nil, fmt.Errorf("maintenance window %q: want a HH:MM-HH:MM time range", spec)
+	}
+	if w.start, err = parseClock(from); err != nil {
+		return nil, fmt.Errorf("maintenance window %q: %w", spec, err)
+	}
+	if w.end, err = parseClock(to); err != nil {
+		return nil, fmt.Errorf("maintenance window %q: %w", spec, err)
+	}
+	return w, nil
+}
+
+func (w *Window) parseDays(spec string) error {
+	for _, part := range strings.Split(strings.ToLower(spec), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := weekdays[from]
+		if !ok {
+			return fmt.Errorf("maintenance window %q: unknown day %q", w.spec, from)
+		}
+		last := first
+		if isRange {
+			if last, ok = weekdays[to]; !ok {
+				return fmt.Errorf("maintenance window %q: unknown day %q", w.spec, to)
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			w.days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, want HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Contains reports whether t falls inside the window, in the window's time zone. The
+// start is inclusive and the end exclusive.
+func (w *Window) Contains(t time.Time) bool {
+	t = t.In(w.loc)
+	clock := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return w.days[t.Weekday()] && clock >= w.start && clock < w.end
+	}
+	// Past midnight: the early hours belong to the window that opened the day before.
+	return (w.days[t.Weekday()] && clock >= w.start) || (w.days[(t.Weekday()+6)%7] && clock < w.end)
+}
+
+// Location returns the window's time zone.
+func (w *Window) Location() *time.Location {
+	return w.loc
+}
+
+func (w *Window) String() string {
+	return w.spec + " " + w.loc.String()
+}
+
+// ParseMaintenanceWindow parses MaintenanceWindow in MaintenanceTimezone, or returns nil
+// when no window is configured.
+func (c *Config) ParseMaintenanceWindow() (*Window, error) {
+	if strings.TrimSpace(c.MaintenanceWindow) == "" {
+		return nil, nil
+	}
+	return ParseWindow(c.MaintenanceWindow, c.MaintenanceTimezone)
+}
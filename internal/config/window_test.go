@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseWindow(t *testing.T) {
+	for _, spec := range []string{"", "22:00", "25:00-02:00", "Mon-Fri", "Funday 22:00-02:00", "Mon 22:00 02:00"} {
+		if _, err := ParseWindow(spec, "UTC"); err == nil {
+			t.Errorf("ParseWindow(%q) expected an error", spec)
+		}
+	}
+	if _, err := ParseWindow("22:00-02:00", "Nowhere/Special"); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	// 2024-01-10 is a Wednesday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 1, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		tz   string
+		t    time.Time
+		want bool
+	}{
+		{name: "Daily inside", spec: "01:00-05:00", t: at(10, 3, 0), want: true},
+		{name: "Daily start is inclusive", spec: "01:00-05:00", t: at(10, 1, 0), want: true},
+		{name: "Daily end is exclusive", spec: "01:00-05:00", t: at(10, 5, 0)},
+		{name: "Past midnight before it", spec: "22:00-02:00", t: at(10, 23, 0), want: true},
+		{name: "Past midnight after it", spec: "22:00-02:00", t: at(11, 1, 59), want: true},
+		{name: "Past midnight outside", spec: "22:00-02:00", t: at(10, 12, 0)},
+		{name: "Day range", spec: "Mon-Fri 09:00-17:00", t: at(10, 10, 0), want: true},
+		{name: "Day range excludes weekend", spec: "Mon-Fri 09:00-17:00", t: at(13, 10, 0)},
+		{name: "Wrapping day range", spec: "Fri-Mon 09:00-17:00", t: at(14, 10, 0), want: true},
+		{name: "Day list", spec: "sat,sun 01:00-05:00", t: at(10, 3, 0)},
+		{name: "Early hours belong to the opening day", spec: "Fri 22:00-02:00", t: at(13, 1, 0), want: true},
+		{name: "Opening day only", spec: "Fri 22:00-02:00", t: at(12, 1, 0)},
+		{name: "Time zone", spec: "01:00-05:00", tz: "Europe/Berlin", t: at(10, 1, 30), want: true},
+		{name: "Time zone outside", spec: "01:00-05:00", tz: "Europe/Berlin", t: at(10, 4, 30)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, err := ParseWindow(tt.spec, tt.tz)
+			if err != nil {
+				t.Fatalf("ParseWindow(%q): %v", tt.spec, err)
+			}
+			if got := w.Contains(tt.t); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package dbconn
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// Version is the tool version reported in the appName of every client built here, so DBAs
+// can spot the tool's connections in currentOp and the profiler. The CLI sets it from its
+// build variables before connecting.
+var Version = "dev"
+
+const appNamePrefix = "mongo-migration-tool/"
+
+const (
+	defaultAttempts    = 5
+	defaultDelay       = 1 * time.Second
+	defaultPingTimeout = 2 * time.Second
+)
+
+// RetryPolicy controls how often and how quickly a connection is retried.
+// When Wait is set, Attempts is ignored and retries continue until Wait has elapsed.
+type RetryPolicy struct {
+	Attempts    int
+	Delay       time.Duration
+	PingTimeout time.Duration
+	Wait        time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{Attempts: defaultAttempts, Delay: defaultDelay, PingTimeout: defaultPingTimeout}
+}
+
+// PolicyFromConfig builds a RetryPolicy from config, falling back to defaults for unset values.
+func PolicyFromConfig(cfg *config.Config) RetryPolicy {
+	return RetryPolicy{
+		Attempts: cfg.ConnectRetries,
+		Delay:    cfg.ConnectRetryDelay,
+		Wait:     cfg.ConnectWait,
+	}.withDefaults()
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.Attempts <= 0 {
+		p.Attempts = defaultAttempts
+	}
+	if p.Delay < 0 {
+		p.Delay = defaultDelay
+	}
+	if p.PingTimeout <= 0 {
+		p.PingTimeout = defaultPingTimeout
+	}
+	return p
+}
+
+// ClientOptions builds the driver options shared by every entrypoint.
+func ClientOptions(cfg *config.Config) *options.ClientOptions {
+	opts := options.Client().
+		ApplyURI(cfg.GetConnectionString()).
+		SetMaxPoolSize(uint64(cfg.MaxPoolSize)).
+		SetMinPoolSize(uint64(cfg.MinPoolSize))
+	WithAppName(opts)
+
+	if cfg.ReadFromSecondary {
+		opts.SetReadPreference(readpref.Secondary())
+	}
+	if cfg.SSLEnabled {
+		opts.SetTLSConfig(&tls.Config{InsecureSkipVerify: cfg.SSLInsecure})
+	}
+	return opts
+}
+
+// WithAppName sets the appName to mongo-migration-tool/<Version> unless the connection
+// string already chose one.
+func WithAppName(opts *options.ClientOptions) *options.ClientOptions {
+	if opts.AppName == nil {
+		opts.SetAppName(appNamePrefix + Version)
+	}
+	return opts
+}
+
+// ConnectWithRetry connects and pings until the server answers, the policy is exhausted,
+// or ctx is cancelled. The client is disconnected on failure.
+func ConnectWithRetry(ctx context.Context, opts *options.ClientOptions, policy RetryPolicy) (*mongo.Client, error) {
+	client, err := mongo.Connect(opts)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToConnect, err)
+	}
+
+	policy = policy.withDefaults()
+	err = Retry(ctx, policy, func(ctx context.Context) error {
+		pCtx, cancel := context.WithTimeout(ctx, policy.PingTimeout)
+		defer cancel()
+		return client.Ping(pCtx, nil)
+	})
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, err
+	}
+	return client, nil
+}
+
+// Retry calls op until it succeeds, logging a warning for every failed attempt.
+// It stops early when ctx is done and returns ctx.Err() in that case.
+func Retry(ctx context.Context, policy RetryPolicy, op func(context.Context) error) error {
+	policy = policy.withDefaults()
+	if policy.Wait > 0 {
+		return retryUntil(ctx, policy, op)
+	}
+
+	var err error
+	for attempt := 1; attempt <= policy.Attempts; attempt++ {
+		if err = op(ctx); err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		slog.Warn("MongoDB ping failed", "attempt", attempt, "max_attempts", policy.Attempts, "error", err)
+		if attempt == policy.Attempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(policy.Delay):
+		}
+	}
+	return fmt.Errorf("%w after %d attempts: %w", ErrUnreachable, policy.Attempts, err)
+}
+
+func retryUntil(ctx context.Context, policy RetryPolicy, op func(context.Context) error) error {
+	deadline := time.Now().Add(policy.Wait)
+	wCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	delay := policy.Delay
+	if delay <= 0 {
+		delay = defaultDelay
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(wCtx); err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		slog.Warn("Waiting for MongoDB", "attempt", attempt,
+			"remaining", time.Until(deadline).Round(time.Millisecond), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wCtx.Done():
+			return fmt.Errorf("%w after waiting %s: %w", ErrUnreachable, policy.Wait, err)
+		case <-time.After(delay):
+		}
+	}
+}
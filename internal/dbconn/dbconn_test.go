@@ -0,0 +1,148 @@
+package dbconn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+func TestRetrySucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), RetryPolicy{Attempts: 3, Delay: time.Millisecond}, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	calls := 0
+	pingErr := errors.New("connection refused")
+	err := Retry(context.Background(), RetryPolicy{Attempts: 2, Delay: time.Millisecond}, func(context.Context) error {
+		calls++
+		return pingErr
+	})
+	if !errors.Is(err, ErrUnreachable) || !errors.Is(err, pingErr) {
+		t.Fatalf("Retry() error = %v, want ErrUnreachable wrapping the last error", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestRetryHonorsCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+
+	start := time.Now()
+	err := Retry(ctx, RetryPolicy{Attempts: 10, Delay: time.Hour}, func(context.Context) error {
+		calls++
+		cancel()
+		return errors.New("refused")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Retry() error = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Retry should not wait for the delay after cancellation")
+	}
+}
+
+func TestRetryPolicyDefaults(t *testing.T) {
+	p := RetryPolicy{}.withDefaults()
+	if p.Attempts != defaultAttempts || p.PingTimeout != defaultPingTimeout {
+		t.Errorf("unexpected defaults: %+v", p)
+	}
+}
+
+func TestRetryWaitReturnsOnceReachable(t *testing.T) {
+	reachableAt := time.Now().Add(30 * time.Millisecond)
+	policy := RetryPolicy{Wait: 5 * time.Second, Delay: 5 * time.Millisecond}
+
+	start := time.Now()
+	err := Retry(context.Background(), policy, func(context.Context) error {
+		if time.Now().Before(reachableAt) {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Retry returned after %s, expected shortly after the server became reachable", elapsed)
+	}
+}
+
+func TestRetryWaitDeadline(t *testing.T) {
+	policy := RetryPolicy{Wait: 50 * time.Millisecond, Delay: 5 * time.Millisecond}
+
+	start := time.Now()
+	err := Retry(context.Background(), policy, func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	if !errors.Is(err, ErrUnreachable) {
+		t.Fatalf("Retry() error = %v, want ErrUnreachable", err)
+	}
+	if elapsed := time.Since(start); elapsed < policy.Wait || elapsed > time.Second {
+		t.Errorf("Retry gave up after %s, expected about %s", elapsed, policy.Wait)
+	}
+}
+
+func TestClientOptionsReadFromSecondary(t *testing.T) {
+	cfg := &config.Config{MongoURL: "mongodb://localhost:27017"}
+	if rp := ClientOptions(cfg).ReadPreference; rp != nil {
+		t.Errorf("ReadPreference = %v, want the driver default", rp.Mode())
+	}
+
+	cfg.ReadFromSecondary = true
+	rp := ClientOptions(cfg).ReadPreference
+	if rp == nil || rp.Mode() != readpref.SecondaryMode {
+		t.Fatalf("ReadPreference = %v, want secondary", rp)
+	}
+}
+
+func TestClientOptionsAppName(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+		want string
+	}{
+		{name: "Default", cfg: &config.Config{MongoURL: "mongodb://localhost:27017"}, want: "mongo-migration-tool/dev"},
+		{name: "Set in the URL", cfg: &config.Config{MongoURL: "mongodb://localhost:27017/?appName=nightly"},
+			want: "nightly"},
+		{name: "Set in the connection options", cfg: &config.Config{
+			MongoURL: "mongodb://localhost:27017", ConnectionOptions: map[string]string{"appName": "ci"},
+		}, want: "ci"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClientOptions(tt.cfg).AppName; got == nil || *got != tt.want {
+				t.Errorf("AppName = %v, want %q", got, tt.want)
+			}
+		})
+	}
+
+	prev := Version
+	Version = "1.4.0"
+	t.Cleanup(func() { Version = prev })
+	if got := WithAppName(options.Client()).AppName; got == nil || *got != "mongo-migration-tool/1.4.0" {
+		t.Errorf("AppName = %v, want the build version", got)
+	}
+}
@@ -0,0 +1,12 @@
+package dbconn
+
+type ErrorConn string
+
+func (e ErrorConn) Error() string {
+	return string(e)
+}
+
+const (
+	ErrFailedToConnect = ErrorConn("failed to connect to mongodb")
+	ErrUnreachable     = ErrorConn("mongodb unreachable")
+)
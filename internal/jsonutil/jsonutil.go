@@ -1,6 +1,7 @@
 package jsonutil
 
 import (
+	"bytes"
 	"io"
 
 	"github.com/bytedance/sonic"
@@ -43,6 +44,8 @@ func (e *Encoder) Encode(v any) error {
 	if err != nil {
 		return err
 	}
+	// The encoding/json fallback used on unsupported platforms already ends with a newline.
+	data = bytes.TrimRight(data, "\n")
 	_, err = e.w.Write(data)
 	if err != nil {
 		return err
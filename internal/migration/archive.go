@@ -0,0 +1,106 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	archiveInfix          = "_archived_"
+	codeNamespaceNotFound = 26
+)
+
+// Archiver is an optional interface for migrations whose collections hold audit or event
+// data that must be kept when the migration is rolled back. When ArchiveOnDown returns
+// true, the engine renames each of ArchiveCollections to ArchivedName before calling Down,
+// so Down should leave those collections alone instead of dropping them. If Down fails,
+// the collections are renamed back.
+type Archiver interface {
+	ArchiveOnDown() bool
+	ArchiveCollections() []string
+}
+
+// ArchivedName is the name coll is archived under when version is rolled back.
+func ArchivedName(coll, version string) string {
+	return coll + archiveInfix + version
+}
+
+// ArchiveCollection renames coll in db to ArchivedName(coll, version) and returns the new
+// name. A missing coll is not an error, so a rollback that is retried after archiving
+// succeeds; an existing archive collection is, and is never overwritten.
+func ArchiveCollection(ctx context.Context, db *mongo.Database, coll, version string) (string, error) {
+	target := ArchivedName(coll, version)
+	if _, err := renameCollection(ctx, db, coll, target); err != nil {
+		return "", fmt.Errorf("%w: %s to %s: %w", ErrFailedToArchive, coll, target, err)
+	}
+	return target, nil
+}
+
+// renameCollection renames from to to in db and reports whether it did. A missing from is
+// not an error.
+func renameCollection(ctx context.Context, db *mongo.Database, from, to string) (bool, error) {
+	cmd := bson.D{
+		{Key: "renameCollection", Value: db.Name() + "." + from},
+		{Key: "to", Value: db.Name() + "." + to},
+	}
+	err := db.Client().Database("admin").RunCommand(ctx, cmd).Err()
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && cmdErr.Code == codeNamespaceNotFound {
+		slog.Debug("Collection to rename does not exist", "collection", from, "to", to)
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// archiveAround runs down, the Down of m, with m's collections archived. renameCollection
+// is not allowed inside a transaction, so the collections are renamed before down starts
+// its transaction, and renamed back when down fails: the record of m is kept then, and
+// the next run must find the collections where m left them.
+func (e *Engine) archiveAround(ctx context.Context, m Migration, down func() error) error {
+	a, ok := m.(Archiver)
+	if !ok || !a.ArchiveOnDown() {
+		return down()
+	}
+	if err := e.checkFence(ctx); err != nil {
+		return err
+	}
+
+	var archived []string
+	err := func() error {
+		for _, coll := range a.ArchiveCollections() {
+			target := ArchivedName(coll, m.Version())
+			renamed, err := renameCollection(ctx, e.db, coll, target)
+			if err != nil {
+				return fmt.Errorf("%w: %s to %s: %w", ErrFailedToArchive, coll, target, err)
+			}
+			if renamed {
+				archived = append(archived, coll)
+				slog.Info("Archived collection", "version", m.Version(), "collection", coll, "archive", target)
+			}
+		}
+		return down()
+	}()
+	if err == nil {
+		return nil
+	}
+	return errors.Join(err, e.restoreArchived(context.WithoutCancel(ctx), m.Version(), archived))
+}
+
+// restoreArchived renames the archives of colls back after a failed Down of version.
+func (e *Engine) restoreArchived(ctx context.Context, version string, colls []string) error {
+	var errs []error
+	for _, coll := range colls {
+		archive := ArchivedName(coll, version)
+		if _, err := renameCollection(ctx, e.db, archive, coll); err != nil {
+			errs = append(errs, fmt.Errorf("%w: restore %s to %s: %w", ErrFailedToArchive, archive, coll, err))
+			continue
+		}
+		slog.Info("Restored archived collection after failed rollback", "version", version, "collection", coll)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	AuditSuccess      = "success"
+	AuditFailure      = "failure"
+	AuditSkipped      = "skipped"
+	AuditAllowed      = "allowed"
+	AuditRefused      = "refused"
+	AuditOverridden   = "overridden"
+	auditWriteTimeout = 5 * time.Second
+)
+
+// AuditEntry is appended to the audit collection for every executed migration, whether it
+// succeeded or not, and for every migration Up skipped (see WithEnvironment). Entries with
+// an Event record a policy decision instead, such as a maintenance window check.
+type AuditEntry struct {
+	Timestamp  time.Time `bson:"timestamp" json:"timestamp"`
+	Event      string    `bson:"event,omitempty" json:"event,omitempty"`
+	Direction  string    `bson:"direction" json:"direction"`
+	Version    string    `bson:"version" json:"version"`
+	Outcome    string    `bson:"outcome" json:"outcome"`
+	Error      string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMS int64     `bson:"duration_ms" json:"duration_ms"`
+	Reason     string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// audit records one execution attempt. The write uses its own context, so it is neither
+// part of the migration's transaction nor skipped when the run was cancelled, and a
+// failure to write is only logged.
+func (e *Engine) audit(version string, dir Direction, started time.Time, runErr error) {
+	if e.auditColl == "" || e.readOnly {
+		return
+	}
+	entry := AuditEntry{
+		Timestamp:  started.UTC(),
+		Direction:  dir.String(),
+		Version:    version,
+		Outcome:    AuditSuccess,
+		DurationMS: time.Since(started).Milliseconds(),
+	}
+	if runErr != nil {
+		entry.Outcome = AuditFailure
+		entry.Error = runErr.Error()
+	}
+	e.writeAudit(entry)
+}
+
+// auditSkip records that version was not executed, and why.
+func (e *Engine) auditSkip(version string, dir Direction, reason string) {
+	if e.auditColl == "" || e.readOnly {
+		return
+	}
+	e.writeAudit(AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Direction: dir.String(),
+		Version:   version,
+		Outcome:   AuditSkipped,
+		Reason:    reason,
+	})
+}
+
+// AuditPolicy records a policy decision taken outside the engine, such as whether a
+// command may run outside the maintenance window. outcome is AuditAllowed, AuditRefused
+// or AuditOverridden.
+func (e *Engine) AuditPolicy(event, outcome, reason string) {
+	if e.auditColl == "" || e.readOnly {
+		return
+	}
+	e.writeAudit(AuditEntry{
+		Timestamp: time.Now().UTC(),
+		Event:     event,
+		Outcome:   outcome,
+		Reason:    reason,
+	})
+}
+
+func (e *Engine) writeAudit(entry AuditEntry) {
+	ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+	defer cancel()
+	if _, err := e.db.Collection(e.auditColl).InsertOne(ctx, entry); err != nil {
+		slog.Warn("Failed to write migration audit entry",
+			"version", entry.Version, "direction", entry.Direction, "error", err)
+	}
+}
@@ -0,0 +1,143 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	defaultBatchSize = 500
+	deleteBatchYield = 10 * time.Millisecond
+)
+
+// BatchError reports a ForEachBatch failure together with the _id of the last document of
+// the last batch that succeeded, or nil when none did. Passing
+// bson.M{"_id": bson.M{"$gt": After}} as part of the filter resumes after that point.
+type BatchError struct {
+	After     any
+	Processed int64
+	Err       error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("batch failed after %d document(s) (last _id %v): %v", e.Processed, e.After, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ForEachBatch calls fn with the documents matching filter in _id order, batchSize at a
+// time. Each batch is a separate query that starts after the previous batch's last _id,
+// so no cursor stays open while fn runs and a failed run can be resumed from BatchError.
+// A batchSize of zero or less uses 500. Each finished batch is added to the migration's
+// ProgressReporter, if it has one. Reads are not rate limited; writes fn makes through
+// BulkWriter are, and other writes can wait on RateLimiterFromContext themselves.
+func ForEachBatch(
+	ctx context.Context, coll *mongo.Collection, filter any, batchSize int,
+	fn func(ctx context.Context, docs []bson.M) error,
+) error {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(int64(batchSize))
+
+	var after any
+	var processed int64
+	for {
+		page := filter
+		if after != nil {
+			next := bson.D{{Key: "_id", Value: bson.D{{Key: "$gt", Value: after}}}}
+			page = bson.D{{Key: "$and", Value: bson.A{filter, next}}}
+		}
+
+		var docs []bson.M
+		cur, err := coll.Find(ctx, page, opts)
+		if err == nil {
+			err = cur.All(ctx, &docs)
+		}
+		if err != nil {
+			return &BatchError{After: after, Processed: processed, Err: fmt.Errorf("failed to read batch: %w", err)}
+		}
+		if len(docs) == 0 {
+			return nil
+		}
+
+		if err := fn(ctx, docs); err != nil {
+			return &BatchError{After: after, Processed: processed, Err: err}
+		}
+		after = docs[len(docs)-1]["_id"]
+		processed += int64(len(docs))
+		ProgressFromContext(ctx).Add(int64(len(docs)))
+
+		if len(docs) < batchSize {
+			return nil
+		}
+	}
+}
+
+// DeleteInBatches deletes the documents matching filter batchSize at a time, in _id order,
+// and returns how many it deleted. Unlike a single DeleteMany, each batch is a short
+// operation and the helper pauses briefly between batches, so rolling back millions of
+// inserted documents does not hold up other writers. A batchSize of zero or less uses 500.
+// Each deleted batch is added to the migration's ProgressReporter, if it has one; seed it
+// with ProgressReporter.CountTotal before calling. Batches wait on the RateLimiter in ctx.
+func DeleteInBatches(ctx context.Context, coll *mongo.Collection, filter any, batchSize int) (int64, error) {
+	if filter == nil {
+		filter = bson.D{}
+	}
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(batchSize)).
+		SetProjection(bson.D{{Key: "_id", Value: 1}})
+
+	var deleted int64
+	for {
+		var docs []bson.M
+		cur, err := coll.Find(ctx, filter, opts)
+		if err == nil {
+			err = cur.All(ctx, &docs)
+		}
+		if err != nil {
+			return deleted, fmt.Errorf("failed to read batch after %d deletion(s): %w", deleted, err)
+		}
+		if len(docs) == 0 {
+			return deleted, nil
+		}
+
+		ids := make(bson.A, len(docs))
+		for i, doc := range docs {
+			ids[i] = doc["_id"]
+		}
+		if err := RateLimiterFromContext(ctx).Wait(ctx, len(docs)); err != nil {
+			return deleted, err
+		}
+		batch := bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}}
+		res, err := coll.DeleteMany(ctx, bson.D{{Key: "$and", Value: bson.A{filter, batch}}})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete batch after %d deletion(s): %w", deleted, err)
+		}
+		deleted += res.DeletedCount
+		ProgressFromContext(ctx).Add(res.DeletedCount)
+
+		if len(docs) < batchSize {
+			return deleted, nil
+		}
+		select {
+		case <-ctx.Done():
+			return deleted, ctx.Err()
+		case <-time.After(deleteBatchYield):
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package migration
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// BodyChecksumsFile is the file name checksum-gen writes into the migrations directory.
+const BodyChecksumsFile = "checksums.go"
+
+var bodyChecksums = make(map[string]string)
+
+// RegisterBodyChecksums adds hashes of migration source, keyed by version, for Checksum to
+// include. The checksums.go file written by WriteBodyChecksums calls it from init. Go cannot
+// inspect a function body at run time, so without these hashes a checksum only covers the
+// version and description, and an edited Up goes unnoticed.
+func RegisterBodyChecksums(sums map[string]string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	maps.Copy(bodyChecksums, sums)
+}
+
+func bodyChecksum(version string) string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return bodyChecksums[version]
+}
+
+// HashMigrationSources parses the non-test .go files in dir and hashes the Up and Down
+// methods of every type DiscoverMigrations finds there, keyed by version. The methods are
+// printed in canonical form without comments first, so reformatting or editing comments
+// does not change a hash, while any change to the code does. Generated files are skipped.
+func HashMigrationSources(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	versions := make(map[string]string)
+	methods := make(map[string][]*ast.FuncDecl)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if typ, version, ok := versionMethod(decl); ok {
+				if other, dup := versions[version]; dup {
+					return nil, fmt.Errorf("%w: %s is returned by both %s and %s", ErrVersionExists, version, other, typ)
+				}
+				versions[version] = typ
+				continue
+			}
+			if fn, typ, ok := bodyMethod(decl); ok {
+				methods[typ] = append(methods[typ], fn)
+			}
+		}
+	}
+
+	sums := make(map[string]string, len(versions))
+	for version, typ := range versions {
+		fns := methods[typ]
+		if len(fns) == 0 {
+			continue
+		}
+		slices.SortFunc(fns, func(a, b *ast.FuncDecl) int { return strings.Compare(a.Name.Name, b.Name.Name) })
+		h := sha256.New()
+		for _, fn := range fns {
+			// Doc comments are not part of the body; dropping them also keeps the printer
+			// from emitting them.
+			decl := *fn
+			decl.Doc = nil
+			if err := printer.Fprint(h, token.NewFileSet(), &decl); err != nil {
+				return nil, fmt.Errorf("hash %s.%s: %w", typ, fn.Name.Name, err)
+			}
+		}
+		sums[version] = fmt.Sprintf("%x", h.Sum(nil))
+	}
+	return sums, nil
+}
+
+// bodyMethod matches an Up or Down method with a body and returns its receiver type name.
+func bodyMethod(decl ast.Decl) (*ast.FuncDecl, string, bool) {
+	fn, isFunc := decl.(*ast.FuncDecl)
+	if !isFunc || fn.Recv == nil || fn.Body == nil || (fn.Name.Name != "Up" && fn.Name.Name != "Down") {
+		return nil, "", false
+	}
+	recv := fn.Recv.List[0].Type
+	if star, isStar := recv.(*ast.StarExpr); isStar {
+		recv = star.X
+	}
+	ident, isIdent := recv.(*ast.Ident)
+	if !isIdent {
+		return nil, "", false
+	}
+	return fn, ident.Name, true
+}
+
+var bodyChecksumsTemplate = template.Must(template.New("checksums").Parse(
+	`// Code generated by mongo-tool checksum-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import "github.com/drewjocham/mongo-migration-tool/internal/migration"
+
+func init() {
+	migration.RegisterBodyChecksums(map[string]string{
+{{- range .Entries}}
+		{{printf "%q" .Version}}: {{printf "%q" .Sum}},
+{{- end}}
+	})
+}
+`))
+
+// WriteBodyChecksums writes a gofmt-ed Go file in package pkg that registers sums with
+// RegisterBodyChecksums, sorted by version so regenerating it gives a stable diff.
+func WriteBodyChecksums(w io.Writer, pkg string, sums map[string]string) error {
+	type entry struct{ Version, Sum string }
+	data := struct {
+		Package string
+		Entries []entry
+	}{Package: pkg}
+	for _, v := range slices.Sorted(maps.Keys(sums)) {
+		data.Entries = append(data.Entries, entry{Version: v, Sum: sums[v]})
+	}
+
+	var buf bytes.Buffer
+	if err := bodyChecksumsTemplate.Execute(&buf, data); err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToExecuteTemplate, err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToExecuteTemplate, err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// PackageName returns the package clause of the first non-test .go file in dir.
+func PackageName(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, name), nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return file.Name.Name, nil
+	}
+	return "", fmt.Errorf("no Go files in %s", dir)
+}
@@ -0,0 +1,173 @@
+package migration
+
+import (
+	"bytes"
+	"errors"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const bodyFixture = `package migrations
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type AddUsers struct{}
+
+func (m *AddUsers) Version() string     { return "20240101_001" }
+func (m *AddUsers) Description() string { return "add users" }
+
+// Up creates the collection.
+func (m *AddUsers) Up(ctx context.Context, db *mongo.Database) error {
+	return db.CreateCollection(ctx, "users")
+}
+
+func (m *AddUsers) Down(ctx context.Context, db *mongo.Database) error {
+	return db.Collection("users").Drop(ctx)
+}
+`
+
+func writeFixture(t *testing.T, dir, name, src string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hashFixture(t *testing.T, src string) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeFixture(t, dir, "20240101_001_add_users.go", src)
+	sums, err := HashMigrationSources(dir)
+	if err != nil {
+		t.Fatalf("HashMigrationSources() error = %v", err)
+	}
+	if len(sums) != 1 || sums["20240101_001"] == "" {
+		t.Fatalf("HashMigrationSources() = %v, want one hash for 20240101_001", sums)
+	}
+	return sums["20240101_001"]
+}
+
+func TestHashMigrationSources(t *testing.T) {
+	base := hashFixture(t, bodyFixture)
+
+	tests := []struct {
+		name    string
+		src     string
+		changed bool
+	}{
+		{name: "up body", changed: true,
+			src: strings.Replace(bodyFixture, `"users")`, `"accounts")`, 1)},
+		{name: "down body", changed: true,
+			src: strings.Replace(bodyFixture, `Drop(ctx)`, `Drop(context.Background())`, 1)},
+		{name: "comments", changed: false,
+			src: strings.Replace(bodyFixture, "// Up creates the collection.", "// Up creates users.\n// It is idempotent.", 1)},
+		{name: "formatting", changed: false,
+			src: strings.Replace(bodyFixture, "\treturn db.CreateCollection(ctx, \"users\")",
+				"\t// create it\n\treturn db.CreateCollection(ctx,\n\t\t\"users\")", 1)},
+		{name: "description", changed: false,
+			src: strings.Replace(bodyFixture, `"add users"`, `"add the users collection"`, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.src == bodyFixture {
+				t.Fatal("fixture was not modified")
+			}
+			if got := hashFixture(t, tt.src); (got != base) != tt.changed {
+				t.Errorf("hash changed = %v, want %v", got != base, tt.changed)
+			}
+		})
+	}
+}
+
+func TestHashMigrationSourcesSkipsGeneratedAndTests(t *testing.T) {
+	dir := t.TempDir()
+	writeFixture(t, dir, "20240101_001_add_users.go", bodyFixture)
+	writeFixture(t, dir, "helpers_test.go", strings.Replace(bodyFixture, "20240101_001", "20240101_002", 1))
+
+	var buf bytes.Buffer
+	if err := WriteBodyChecksums(&buf, "migrations", map[string]string{"20240101_001": "abc"}); err != nil {
+		t.Fatal(err)
+	}
+	writeFixture(t, dir, BodyChecksumsFile, buf.String())
+
+	sums, err := HashMigrationSources(dir)
+	if err != nil {
+		t.Fatalf("HashMigrationSources() error = %v", err)
+	}
+	if len(sums) != 1 {
+		t.Errorf("HashMigrationSources() = %v, want only 20240101_001", sums)
+	}
+
+	writeFixture(t, dir, "copy.go", strings.Replace(bodyFixture, "AddUsers", "AddUsersAgain", -1))
+	if _, err := HashMigrationSources(dir); !errors.Is(err, ErrVersionExists) {
+		t.Errorf("duplicate version error = %v, want ErrVersionExists", err)
+	}
+}
+
+func TestWriteBodyChecksums(t *testing.T) {
+	var buf bytes.Buffer
+	sums := map[string]string{"20240102_001": "bbb", "20240101_001": "aaa"}
+	if err := WriteBodyChecksums(&buf, "migrations", sums); err != nil {
+		t.Fatalf("WriteBodyChecksums() error = %v", err)
+	}
+
+	src := buf.String()
+	file, err := parser.ParseFile(token.NewFileSet(), BodyChecksumsFile, src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("generated file does not parse: %v\n%s", err, src)
+	}
+	if file.Name.Name != "migrations" {
+		t.Errorf("package = %s, want migrations", file.Name.Name)
+	}
+	first, second := strings.Index(src, `"20240101_001": "aaa"`), strings.Index(src, `"20240102_001": "bbb"`)
+	if first < 0 || second < first {
+		t.Errorf("entries missing or not sorted by version:\n%s", src)
+	}
+}
+
+func TestChecksumIncludesBody(t *testing.T) {
+	m := &TestMigration{version: "20991231_body_checksum", description: "body"}
+	legacy := Checksum(m)
+
+	RegisterBodyChecksums(map[string]string{m.version: "feed"})
+	current := Checksum(m)
+	if current == legacy {
+		t.Fatal("Checksum() did not change after registering a body hash")
+	}
+
+	e := NewEngine(nil, "migrations", map[string]Migration{m.version: m})
+	if err := e.validateChecksum(m, MigrationRecord{Version: m.version, Checksum: current}); err != nil {
+		t.Errorf("current checksum rejected: %v", err)
+	}
+	if err := e.validateChecksum(m, MigrationRecord{Version: m.version, Checksum: legacy}); err != nil {
+		t.Errorf("record from before the body was hashed rejected: %v", err)
+	}
+
+	strict := NewEngine(nil, "migrations", map[string]Migration{m.version: m}, WithStrictChecksums(true))
+	err := strict.validateChecksum(m, MigrationRecord{Version: m.version, Description: m.description, Checksum: legacy})
+	if !errors.Is(err, ErrChecksumMismatch) || !strings.Contains(err.Error(), "legacy checksum") {
+		t.Errorf("strict legacy checksum error = %v, want a legacy checksum mismatch", err)
+	}
+
+	RegisterBodyChecksums(map[string]string{m.version: "beef"})
+	err = e.validateChecksum(m, MigrationRecord{Version: m.version, Description: m.description, Checksum: current})
+	if !errors.Is(err, ErrChecksumMismatch) || errors.Is(err, ErrDescriptionChanged) {
+		t.Errorf("edited body error = %v, want a checksum mismatch", err)
+	}
+
+	renamed := &TestMigration{version: m.version, description: "renamed"}
+	err = e.validateChecksum(renamed, MigrationRecord{
+		Version: m.version, Description: m.description, Checksum: Checksum(m),
+	})
+	if !errors.Is(err, ErrDescriptionChanged) {
+		t.Errorf("renamed migration error = %v, want ErrDescriptionChanged", err)
+	}
+}
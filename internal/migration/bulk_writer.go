@@ -0,0 +1,150 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const (
+	defaultBulkBatchSize   = 1000
+	defaultBulkConcurrency = 4
+)
+
+type bulkSemaphoreKey struct{}
+
+// BulkWriter queues write models and sends them to a collection as BulkWrite calls of
+// batchSize models, several at a time. The number of batches in flight is capped by a
+// semaphore: inside an engine run every BulkWriter shares the engine's semaphore (see
+// WithBulkConcurrency), so migrations running in parallel stay under one limit between
+// them. Batches may finish in any order, so models in different batches must not depend
+// on each other. When ctx carries a session, as it does while the engine runs a migration
+// in a transaction, batches are sent one at a time on the calling goroutine instead,
+// because a session must not be used concurrently. Before each batch is sent, the writer
+// waits on the RateLimiter in ctx, if any. A BulkWriter is not safe for concurrent use.
+type BulkWriter struct {
+	batchSize int
+	sem       chan struct{}
+	limiter   *RateLimiter
+	write     func(ctx context.Context, models []mongo.WriteModel) error
+	serial    bool
+	pending   []mongo.WriteModel
+	wg        sync.WaitGroup
+
+	mu   sync.Mutex
+	errs []error
+}
+
+// NewBulkWriter returns a BulkWriter for coll. A batchSize of zero or less uses 1000.
+// Outside an engine run, at most 4 batches are in flight at once.
+func NewBulkWriter(ctx context.Context, coll *mongo.Collection, batchSize int) *BulkWriter {
+	return newBulkWriter(ctx, batchSize, func(ctx context.Context, models []mongo.WriteModel) error {
+		_, err := coll.BulkWrite(ctx, models)
+		return err
+	})
+}
+
+func newBulkWriter(
+	ctx context.Context, batchSize int, write func(ctx context.Context, models []mongo.WriteModel) error,
+) *BulkWriter {
+	if batchSize <= 0 {
+		batchSize = defaultBulkBatchSize
+	}
+	sem, _ := ctx.Value(bulkSemaphoreKey{}).(chan struct{})
+	if sem == nil {
+		sem = make(chan struct{}, defaultBulkConcurrency)
+	}
+	serial := mongo.SessionFromContext(ctx) != nil
+	limiter := RateLimiterFromContext(ctx)
+	return &BulkWriter{batchSize: batchSize, sem: sem, limiter: limiter, write: write, serial: serial}
+}
+
+// Add queues models and sends a batch each time batchSize of them are queued. It blocks
+// while the concurrency limit is reached, and returns the error of any batch that has
+// already failed without queueing more.
+func (w *BulkWriter) Add(ctx context.Context, models ...mongo.WriteModel) error {
+	for _, m := range models {
+		if err := w.err(); err != nil {
+			return err
+		}
+		w.pending = append(w.pending, m)
+		if len(w.pending) >= w.batchSize {
+			if err := w.send(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return w.err()
+}
+
+// Close sends the models still queued, waits for every batch in flight, and returns the
+// errors of all failed batches. Each successful batch is added to the migration's
+// ProgressReporter, if it has one.
+func (w *BulkWriter) Close(ctx context.Context) error {
+	var err error
+	if len(w.pending) > 0 && w.err() == nil {
+		err = w.send(ctx)
+	}
+	w.wg.Wait()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return errors.Join(append(w.errs, err)...)
+}
+
+func (w *BulkWriter) send(ctx context.Context) error {
+	batch := w.pending
+	w.pending = nil
+
+	if err := w.limiter.Wait(ctx, len(batch)); err != nil {
+		return err
+	}
+	select {
+	case w.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if w.serial {
+		w.flush(ctx, batch)
+		<-w.sem
+		return nil
+	}
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		defer func() { <-w.sem }()
+		w.flush(ctx, batch)
+	}()
+	return nil
+}
+
+func (w *BulkWriter) flush(ctx context.Context, batch []mongo.WriteModel) {
+	if err := w.write(ctx, batch); err != nil {
+		w.mu.Lock()
+		w.errs = append(w.errs, fmt.Errorf("%w: batch of %d: %w", ErrBulkWriteFailed, len(batch), err))
+		w.mu.Unlock()
+		return
+	}
+	ProgressFromContext(ctx).Add(int64(len(batch)))
+}
+
+func (w *BulkWriter) err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.errs) == 0 {
+		return nil
+	}
+	return w.errs[0]
+}
+
+// withBulkSemaphore attaches the engine's in-flight batch limit to ctx for BulkWriter.
+func (e *Engine) withBulkSemaphore(ctx context.Context) context.Context {
+	if e.bulkSem == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, bulkSemaphoreKey{}, e.bulkSem)
+}
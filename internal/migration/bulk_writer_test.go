@@ -0,0 +1,120 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// inFlightWriter records how many batches are being written at once.
+type inFlightWriter struct {
+	current, peak, batches, models atomic.Int64
+}
+
+func (f *inFlightWriter) write(_ context.Context, models []mongo.WriteModel) error {
+	n := f.current.Add(1)
+	defer f.current.Add(-1)
+	for {
+		peak := f.peak.Load()
+		if n <= peak || f.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(2 * time.Millisecond)
+	f.batches.Add(1)
+	f.models.Add(int64(len(models)))
+	return nil
+}
+
+func insertModels(n int) []mongo.WriteModel {
+	models := make([]mongo.WriteModel, n)
+	for i := range models {
+		models[i] = mongo.NewInsertOneModel().SetDocument(bson.M{"i": i})
+	}
+	return models
+}
+
+func TestBulkWriterRespectsConcurrencyLimit(t *testing.T) {
+	e := NewEngine(nil, "", nil, WithBulkConcurrency(3))
+	ctx := e.withBulkSemaphore(context.Background())
+
+	f := &inFlightWriter{}
+	w := newBulkWriter(ctx, 2, f.write)
+	if err := w.Add(ctx, insertModels(41)...); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	if got := f.peak.Load(); got > 3 {
+		t.Fatalf("peak in-flight batches = %d, want at most 3", got)
+	}
+	if f.batches.Load() != 21 || f.models.Load() != 41 {
+		t.Fatalf("wrote %d models in %d batches, want 41 in 21", f.models.Load(), f.batches.Load())
+	}
+}
+
+func TestBulkWriterLimitIsSharedAcrossWriters(t *testing.T) {
+	e := NewEngine(nil, "", nil, WithBulkConcurrency(2))
+	ctx := e.withBulkSemaphore(context.Background())
+
+	f := &inFlightWriter{}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := newBulkWriter(ctx, 1, f.write)
+			if err := w.Add(ctx, insertModels(10)...); err != nil {
+				t.Errorf("Add: %v", err)
+			}
+			if err := w.Close(ctx); err != nil {
+				t.Errorf("Close: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := f.peak.Load(); got > 2 {
+		t.Fatalf("peak in-flight batches across writers = %d, want at most 2", got)
+	}
+	if got := f.models.Load(); got != 40 {
+		t.Fatalf("wrote %d models, want 40", got)
+	}
+}
+
+func TestBulkWriterReportsFailedBatches(t *testing.T) {
+	boom := errors.New("boom")
+	var calls atomic.Int64
+	w := newBulkWriter(context.Background(), 5, func(context.Context, []mongo.WriteModel) error {
+		if calls.Add(1) == 1 {
+			return boom
+		}
+		return nil
+	})
+
+	ctx := context.Background()
+	_ = w.Add(ctx, insertModels(5)...)
+	err := w.Close(ctx)
+	if !errors.Is(err, ErrBulkWriteFailed) || !errors.Is(err, boom) {
+		t.Fatalf("Close error = %v, want ErrBulkWriteFailed wrapping boom", err)
+	}
+	if err := w.Add(ctx, insertModels(1)...); !errors.Is(err, boom) {
+		t.Fatalf("Add after failure = %v, want the earlier error", err)
+	}
+}
+
+func TestBulkWriterDefaultsWithoutEngine(t *testing.T) {
+	w := newBulkWriter(context.Background(), 0, nil)
+	if w.batchSize != defaultBulkBatchSize || cap(w.sem) != defaultBulkConcurrency {
+		t.Fatalf("batchSize=%d limit=%d, want %d and %d",
+			w.batchSize, cap(w.sem), defaultBulkBatchSize, defaultBulkConcurrency)
+	}
+}
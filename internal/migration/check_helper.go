@@ -0,0 +1,83 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// CheckMigration is a Migration that asserts a data-quality condition instead of changing
+// data, so a violated invariant fails the deploy like a failed migration would. Up runs
+// the check; Down does nothing.
+type CheckMigration struct {
+	version     string
+	description string
+	check       func(ctx context.Context, db *mongo.Database) error
+}
+
+// NewCheckMigration wraps check as a migration. check returns an error to fail Up.
+func NewCheckMigration(
+	version, description string, check func(ctx context.Context, db *mongo.Database) error,
+) *CheckMigration {
+	return &CheckMigration{version: version, description: description, check: check}
+}
+
+// NewCountCheck counts the documents in coll matching filter. With expectZero any match
+// fails the check, e.g. users with a null email; otherwise no match fails it.
+func NewCountCheck(version, coll string, filter any, expectZero bool) *CheckMigration {
+	want := "at least one"
+	if expectZero {
+		want = "no"
+	}
+	description := fmt.Sprintf("Check that %s has %s document(s) matching %v", coll, want, filter)
+	return NewCheckMigration(version, description, func(ctx context.Context, db *mongo.Database) error {
+		n, err := db.Collection(coll).CountDocuments(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to count %s: %w", coll, err)
+		}
+		return checkCount(coll, n, expectZero)
+	})
+}
+
+// NewAggregateCheck runs pipeline on coll. With expectEmpty any result fails the check;
+// otherwise an empty result fails it. A pipeline ending in a $match on the violation
+// (or a $group plus $match on the bad totals) keeps the result small.
+func NewAggregateCheck(version, coll string, pipeline any, expectEmpty bool) *CheckMigration {
+	description := fmt.Sprintf("Check aggregation on %s", coll)
+	return NewCheckMigration(version, description, func(ctx context.Context, db *mongo.Database) error {
+		cur, err := db.Collection(coll).Aggregate(ctx, pipeline)
+		if err != nil {
+			return fmt.Errorf("failed to aggregate %s: %w", coll, err)
+		}
+		defer cur.Close(ctx)
+
+		var n int64
+		for cur.Next(ctx) {
+			n++
+		}
+		if err := cur.Err(); err != nil {
+			return fmt.Errorf("failed to read aggregation on %s: %w", coll, err)
+		}
+		return checkCount(coll, n, expectEmpty)
+	})
+}
+
+func checkCount(coll string, n int64, expectZero bool) error {
+	if expectZero && n > 0 {
+		return fmt.Errorf("%w: %d document(s) in %s violate the check", ErrCheckFailed, n, coll)
+	}
+	if !expectZero && n == 0 {
+		return fmt.Errorf("%w: no document in %s matches the check", ErrCheckFailed, coll)
+	}
+	return nil
+}
+
+func (c *CheckMigration) Version() string     { return c.version }
+func (c *CheckMigration) Description() string { return c.description }
+
+func (c *CheckMigration) Up(ctx context.Context, db *mongo.Database) error {
+	return c.check(ctx, db)
+}
+
+func (c *CheckMigration) Down(context.Context, *mongo.Database) error { return nil }
@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestCheckCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		n          int64
+		expectZero bool
+		wantErr    bool
+	}{
+		{name: "No violations", n: 0, expectZero: true},
+		{name: "Violations found", n: 3, expectZero: true, wantErr: true},
+		{name: "Required documents present", n: 1},
+		{name: "Required documents missing", n: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkCount("users", tt.n, tt.expectZero)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkCount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrCheckFailed) {
+				t.Errorf("error = %v, want ErrCheckFailed", err)
+			}
+		})
+	}
+}
+
+func TestCheckMigration(t *testing.T) {
+	var m Migration = NewCheckMigration("20240101_001", "users have emails",
+		func(context.Context, *mongo.Database) error { return ErrCheckFailed })
+
+	if err := m.Up(context.Background(), nil); !errors.Is(err, ErrCheckFailed) {
+		t.Errorf("Up() error = %v, want ErrCheckFailed", err)
+	}
+	if err := m.Down(context.Background(), nil); err != nil {
+		t.Errorf("Down() error = %v, want nil", err)
+	}
+	if m.Version() != "20240101_001" || m.Description() != "users have emails" {
+		t.Errorf("Version, Description = %q, %q", m.Version(), m.Description())
+	}
+}
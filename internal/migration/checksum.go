@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"context"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// verifyApplied validates the checksums of all applied migrations that are still registered.
+// Description-only changes are repaired in place when WithAutoRepairDescriptions is set.
+func (e *Engine) verifyApplied(ctx context.Context, applied map[string]MigrationRecord) error {
+	for _, v := range e.getSortedVersions(DirectionUp) {
+		rec, ok := applied[v]
+		if !ok {
+			continue
+		}
+		m := e.migrations[v]
+		err := e.validateChecksum(m, rec)
+		if err == nil {
+			continue
+		}
+		if !e.repairDescriptions || !descriptionOnlyChange(m, rec) {
+			return &MigrationError{Version: v, Direction: DirectionUp, Err: err}
+		}
+		if err := e.repairDescription(ctx, m, applied); err != nil {
+			return &MigrationError{Version: v, Direction: DirectionUp, Err: err}
+		}
+	}
+	return nil
+}
+
+// repairDescription stores the migration's current description and the matching checksum
+// on its record, and updates applied to match.
+func (e *Engine) repairDescription(ctx context.Context, m Migration, applied map[string]MigrationRecord) error {
+	if err := e.checkFence(ctx); err != nil {
+		return err
+	}
+	rec := applied[m.Version()]
+	rec.Description = m.Description()
+	rec.Checksum = e.calculateChecksum(m)
+
+	_, err := e.records().UpdateOne(ctx,
+		bson.M{"version": rec.Version},
+		bson.M{"$set": bson.M{"description": rec.Description, "checksum": rec.Checksum}},
+	)
+	if err != nil {
+		return err
+	}
+	applied[rec.Version] = rec
+	slog.Info("Repaired checksum after description change", "version", rec.Version, "description", rec.Description)
+	return nil
+}
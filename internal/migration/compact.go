@@ -0,0 +1,123 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CompactOptions controls Compact.
+type CompactOptions struct {
+	// DropOrphaned lists the orphaned records, by version, to delete, typically the
+	// Orphaned of a confirmed dry run. Only versions that are still orphaned when Compact
+	// runs are deleted; records orphaned since then are reported and kept.
+	DropOrphaned []string
+	// DryRun reports what Compact would change without writing.
+	DryRun bool
+}
+
+// CompactResult lists, in version order, the records Compact rewrote and the orphaned
+// records it found. Dropped lists the orphaned records that were deleted.
+type CompactResult struct {
+	Rewritten []string
+	Unchanged int
+	Orphaned  []string
+	Dropped   []string
+}
+
+// Compact rewrites every applied record to the form the engine would write for it today:
+// the registered description, its checksum and its metadata. AppliedAt and DurationMS are
+// kept. It is the description repair of WithAutoRepairDescriptions applied to the whole
+// history, including checksum changes, so only run it once the registered migrations are
+// known to be the ones that were applied. Records without a registered migration are
+// deleted when opts.DropOrphaned lists them. Compact writes under the migration lock; a dry run
+// only reads and does not take it.
+func (e *Engine) Compact(ctx context.Context, opts CompactOptions) (CompactResult, error) {
+	var res CompactResult
+	if e.readOnly && !opts.DryRun {
+		return res, ErrReadOnly
+	}
+	if err := e.checkDatabase(); err != nil {
+		return res, err
+	}
+
+	if !opts.DryRun {
+		lease, err := e.acquireLock(ctx)
+		if err != nil {
+			return res, err
+		}
+		defer e.releaseLock(context.Background(), lease)
+		ctx = withLease(ctx, lease)
+	}
+
+	applied, err := e.getAppliedMap(ctx)
+	if err != nil {
+		return res, fmt.Errorf("%w: %w", ErrFailedToReadMigrations, err)
+	}
+
+	versions := slices.SortedFunc(maps.Keys(applied), e.CompareVersions)
+	var rewrite []MigrationRecord
+	for _, v := range versions {
+		m, ok := e.migrations[v]
+		if !ok {
+			res.Orphaned = append(res.Orphaned, v)
+			continue
+		}
+		rec := compactRecord(e.newRecord(m), applied[v])
+		if recordsEqual(rec, applied[v]) {
+			res.Unchanged++
+			continue
+		}
+		rewrite = append(rewrite, rec)
+		res.Rewritten = append(res.Rewritten, v)
+	}
+	if opts.DryRun {
+		return res, nil
+	}
+
+	for _, rec := range rewrite {
+		if err := e.checkFence(ctx); err != nil {
+			return res, err
+		}
+		if _, err := e.records().ReplaceOne(ctx, bson.M{"version": rec.Version}, rec); err != nil {
+			return res, fmt.Errorf("%w: %s: %w", ErrFailedToSetVersion, rec.Version, err)
+		}
+		slog.Info("Compacted migration record", "version", rec.Version, "description", rec.Description)
+	}
+
+	drop := slices.DeleteFunc(slices.Clone(res.Orphaned), func(v string) bool {
+		return !slices.Contains(opts.DropOrphaned, v)
+	})
+	if len(drop) > 0 {
+		if err := e.checkFence(ctx); err != nil {
+			return res, err
+		}
+		_, err := e.records().DeleteMany(ctx, bson.M{"version": bson.M{"$in": drop}})
+		if err != nil {
+			return res, fmt.Errorf("%w: %w", ErrFailedToSetVersion, err)
+		}
+		res.Dropped = drop
+		slog.Info("Dropped orphaned migration records", "versions", drop)
+	}
+	return res, nil
+}
+
+// compactRecord returns canonical with the timing fields of stored, which Compact keeps.
+func compactRecord(canonical, stored MigrationRecord) MigrationRecord {
+	canonical.AppliedAt = stored.AppliedAt
+	canonical.DurationMS = stored.DurationMS
+	return canonical
+}
+
+func recordsEqual(a, b MigrationRecord) bool {
+	return a.Version == b.Version &&
+		a.Description == b.Description &&
+		a.Checksum == b.Checksum &&
+		a.AppliedAt.Equal(b.AppliedAt) &&
+		a.DurationMS == b.DurationMS &&
+		maps.Equal(a.Metadata, b.Metadata)
+}
@@ -0,0 +1,60 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// CompositeMigration groups related steps under one version and one record, so they are
+// applied and rolled back together. Up runs the steps in order and Down runs their Downs
+// in reverse; both stop at the first failing step. Steps that completed before a failure
+// are not undone, just as with a single migration that fails halfway. The steps' own
+// versions are ignored.
+type CompositeMigration struct {
+	version     string
+	description string
+	steps       []Migration
+}
+
+// NewCompositeMigration groups steps, in the order Up should run them, under version.
+func NewCompositeMigration(version, description string, steps ...Migration) *CompositeMigration {
+	return &CompositeMigration{version: version, description: description, steps: steps}
+}
+
+func (m *CompositeMigration) Version() string     { return m.version }
+func (m *CompositeMigration) Description() string { return m.description }
+
+func (m *CompositeMigration) Up(ctx context.Context, db *mongo.Database) error {
+	for i, step := range m.steps {
+		if err := step.Up(ctx, db); err != nil {
+			return m.stepError(i, err)
+		}
+	}
+	return nil
+}
+
+func (m *CompositeMigration) Down(ctx context.Context, db *mongo.Database) error {
+	for i := len(m.steps) - 1; i >= 0; i-- {
+		if err := m.steps[i].Down(ctx, db); err != nil {
+			return m.stepError(i, err)
+		}
+	}
+	return nil
+}
+
+// HasDown reports whether every step can be rolled back.
+func (m *CompositeMigration) HasDown() bool {
+	for _, step := range m.steps {
+		if !isReversible(step) {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *CompositeMigration) stepError(i int, err error) error {
+	return fmt.Errorf("%w: step %d of %d (%s): %w",
+		ErrCompositeStepFailed, i+1, len(m.steps), m.steps[i].Description(), err)
+}
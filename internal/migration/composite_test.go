@@ -0,0 +1,112 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type stepMigration struct {
+	TestMigration
+	log     *[]string
+	upErr   error
+	downErr error
+	noDown  bool
+}
+
+func (m *stepMigration) Up(context.Context, *mongo.Database) error {
+	if m.upErr != nil {
+		return m.upErr
+	}
+	*m.log = append(*m.log, "up "+m.description)
+	return nil
+}
+
+func (m *stepMigration) Down(context.Context, *mongo.Database) error {
+	if m.downErr != nil {
+		return m.downErr
+	}
+	*m.log = append(*m.log, "down "+m.description)
+	return nil
+}
+
+func (m *stepMigration) HasDown() bool { return !m.noDown }
+
+func newSteps(log *[]string, names ...string) []*stepMigration {
+	steps := make([]*stepMigration, len(names))
+	for i, name := range names {
+		steps[i] = &stepMigration{TestMigration: TestMigration{description: name}, log: log}
+	}
+	return steps
+}
+
+func composite(steps []*stepMigration) *CompositeMigration {
+	ms := make([]Migration, len(steps))
+	for i, s := range steps {
+		ms[i] = s
+	}
+	return NewCompositeMigration("20240101_001", "users rollout", ms...)
+}
+
+func TestCompositeMigrationOrder(t *testing.T) {
+	var log []string
+	m := composite(newSteps(&log, "collection", "index", "backfill"))
+
+	if err := m.Up(context.Background(), nil); err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if err := m.Down(context.Background(), nil); err != nil {
+		t.Fatalf("Down() error = %v", err)
+	}
+	want := []string{"up collection", "up index", "up backfill", "down backfill", "down index", "down collection"}
+	if !slices.Equal(log, want) {
+		t.Errorf("steps ran as %v, want %v", log, want)
+	}
+}
+
+func TestCompositeMigrationStopsOnFailure(t *testing.T) {
+	boom := errors.New("duplicate key")
+
+	t.Run("Up", func(t *testing.T) {
+		var log []string
+		steps := newSteps(&log, "collection", "index", "backfill")
+		steps[1].upErr = boom
+
+		err := composite(steps).Up(context.Background(), nil)
+		if !errors.Is(err, ErrCompositeStepFailed) || !errors.Is(err, boom) {
+			t.Fatalf("Up() error = %v, want ErrCompositeStepFailed wrapping the step error", err)
+		}
+		if !slices.Equal(log, []string{"up collection"}) {
+			t.Errorf("steps ran as %v, want the composite to stop at the failing step", log)
+		}
+	})
+
+	t.Run("Down", func(t *testing.T) {
+		var log []string
+		steps := newSteps(&log, "collection", "index", "backfill")
+		steps[1].downErr = boom
+
+		err := composite(steps).Down(context.Background(), nil)
+		if !errors.Is(err, ErrCompositeStepFailed) || !errors.Is(err, boom) {
+			t.Fatalf("Down() error = %v, want ErrCompositeStepFailed wrapping the step error", err)
+		}
+		if !slices.Equal(log, []string{"down backfill"}) {
+			t.Errorf("steps ran as %v, want the rollback to stop at the failing step", log)
+		}
+	})
+}
+
+func TestCompositeMigrationReversible(t *testing.T) {
+	var log []string
+	steps := newSteps(&log, "collection", "backfill")
+	if !isReversible(composite(steps)) {
+		t.Error("composite of reversible steps should be reversible")
+	}
+	steps[1].noDown = true
+	if isReversible(composite(steps)) {
+		t.Error("composite with an irreversible step should not be reversible")
+	}
+}
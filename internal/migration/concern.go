@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+// ParseWriteConcern parses "majority", a node count such as "1", or a replica set tag
+// name. An empty string returns nil, meaning the client's write concern applies.
+func ParseWriteConcern(s string) (*writeconcern.WriteConcern, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case s == "":
+		return nil, nil
+	case strings.EqualFold(s, writeconcern.WCMajority):
+		return writeconcern.Majority(), nil
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidConcern, s)
+		}
+		return &writeconcern.WriteConcern{W: n}, nil
+	}
+	return writeconcern.Custom(s), nil
+}
+
+// ParseReadConcern parses a read concern level. An empty string returns nil, meaning the
+// client's read concern applies.
+func ParseReadConcern(s string) (*readconcern.ReadConcern, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return nil, nil
+	case "local":
+		return readconcern.Local(), nil
+	case "available":
+		return readconcern.Available(), nil
+	case "majority":
+		return readconcern.Majority(), nil
+	case "linearizable":
+		return readconcern.Linearizable(), nil
+	case "snapshot":
+		return readconcern.Snapshot(), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrInvalidConcern, s)
+	}
+}
@@ -0,0 +1,121 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+func resolveCollectionOptions(t *testing.T, b *options.CollectionOptionsBuilder) options.CollectionOptions {
+	t.Helper()
+	var opts options.CollectionOptions
+	for _, set := range b.Opts {
+		if err := set(&opts); err != nil {
+			t.Fatalf("apply collection option: %v", err)
+		}
+	}
+	return opts
+}
+
+func TestRecordsOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []EngineOption
+		wantW     any
+		wantLevel string
+	}{
+		{name: "Majority write by default", wantW: "majority"},
+		{
+			name:  "Custom write concern",
+			opts:  []EngineOption{WithRecordWriteConcern(&writeconcern.WriteConcern{W: 2})},
+			wantW: 2,
+		},
+		{
+			name: "Nil write concern inherits the client",
+			opts: []EngineOption{WithRecordWriteConcern(nil)},
+		},
+		{
+			name:      "Read concern is applied",
+			opts:      []EngineOption{WithRecordReadConcern(readconcern.Majority())},
+			wantW:     "majority",
+			wantLevel: "majority",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(&mongo.Database{}, "", nil, tt.opts...)
+			got := resolveCollectionOptions(t, engine.recordsOptions())
+
+			switch {
+			case tt.wantW == nil && got.WriteConcern != nil:
+				t.Errorf("WriteConcern = %+v, want nil", got.WriteConcern)
+			case tt.wantW != nil && (got.WriteConcern == nil || got.WriteConcern.W != tt.wantW):
+				t.Errorf("WriteConcern = %+v, want w=%v", got.WriteConcern, tt.wantW)
+			}
+
+			switch {
+			case tt.wantLevel == "" && got.ReadConcern != nil:
+				t.Errorf("ReadConcern = %+v, want nil", got.ReadConcern)
+			case tt.wantLevel != "" && (got.ReadConcern == nil || got.ReadConcern.Level != tt.wantLevel):
+				t.Errorf("ReadConcern = %+v, want level %s", got.ReadConcern, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestParseWriteConcern(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantW   any
+		wantNil bool
+		wantErr bool
+	}{
+		{in: "", wantNil: true},
+		{in: "majority", wantW: "majority"},
+		{in: "MAJORITY", wantW: "majority"},
+		{in: "1", wantW: 1},
+		{in: "dc-east", wantW: "dc-east"},
+		{in: "-1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			wc, err := ParseWriteConcern(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidConcern) {
+					t.Fatalf("ParseWriteConcern(%q) error = %v, want ErrInvalidConcern", tt.in, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseWriteConcern(%q) failed: %v", tt.in, err)
+			}
+			if tt.wantNil {
+				if wc != nil {
+					t.Errorf("ParseWriteConcern(%q) = %+v, want nil", tt.in, wc)
+				}
+				return
+			}
+			if wc == nil || wc.W != tt.wantW {
+				t.Errorf("ParseWriteConcern(%q) = %+v, want w=%v", tt.in, wc, tt.wantW)
+			}
+		})
+	}
+}
+
+func TestParseReadConcern(t *testing.T) {
+	rc, err := ParseReadConcern("Majority")
+	if err != nil || rc == nil || rc.Level != "majority" {
+		t.Errorf("ParseReadConcern(Majority) = %+v, %v", rc, err)
+	}
+	if rc, err := ParseReadConcern(""); err != nil || rc != nil {
+		t.Errorf("ParseReadConcern(\"\") = %+v, %v, want nil, nil", rc, err)
+	}
+	if _, err := ParseReadConcern("eventual"); !errors.Is(err, ErrInvalidConcern) {
+		t.Errorf("ParseReadConcern(eventual) error = %v, want ErrInvalidConcern", err)
+	}
+}
@@ -0,0 +1,48 @@
+package migration
+
+import "github.com/drewjocham/mongo-migration-tool/internal/config"
+
+// OptionsFromConfig returns the engine options set by cfg, for every entrypoint that
+// builds an engine from configuration. Options that depend on the caller rather than the
+// configuration, such as WithProgress, are appended by the caller.
+func OptionsFromConfig(cfg *config.Config) ([]EngineOption, error) {
+	recordWrite, err := ParseWriteConcern(cfg.RecordWriteConcern)
+	if err != nil {
+		return nil, err
+	}
+	recordRead, err := ParseReadConcern(cfg.RecordReadConcern)
+	if err != nil {
+		return nil, err
+	}
+	preflight, err := ParsePreflightChecks(cfg.Preflight)
+	if err != nil {
+		return nil, err
+	}
+	compare, err := ParseVersionOrder(cfg.VersionOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	return []EngineOption{
+		WithCausalConsistency(cfg.CausalConsistency),
+		WithMaxParallel(cfg.MaxParallel),
+		WithBulkConcurrency(cfg.BulkConcurrency),
+		WithMaxOpsPerSec(cfg.MaxOpsPerSec),
+		WithEnvironment(cfg.Environment),
+		WithAutoRepairDescriptions(cfg.RepairDescriptions),
+		WithReadOnly(cfg.ReadOnly || cfg.ReadFromSecondary),
+		WithExpectedDatabase(cfg.ExpectedDatabase),
+		WithAuditCollection(cfg.AuditCollection),
+		WithForbidDrops(cfg.ForbidDrops),
+		WithRequireReversible(cfg.RequireReversible),
+		WithFailOnOrphaned(cfg.FailOnOrphaned),
+		WithStrictChecksums(cfg.StrictChecksums),
+		WithFloorVersion(cfg.FloorVersion),
+		WithStaleLockTimeout(cfg.StaleLockTimeout),
+		WithDisableTransactions(cfg.NoTransaction),
+		WithPreflight(preflight...),
+		WithVersionComparator(compare),
+		WithRecordWriteConcern(recordWrite),
+		WithRecordReadConcern(recordRead),
+	}, nil
+}
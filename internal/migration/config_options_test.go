@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestOptionsFromConfig(t *testing.T) {
+	cfg := &config.Config{
+		MaxParallel:        4,
+		RepairDescriptions: true,
+		ForbidDrops:        true,
+		ReadFromSecondary:  true,
+		FloorVersion:       "20240101_001",
+		RecordWriteConcern: "majority",
+	}
+	opts, err := OptionsFromConfig(cfg)
+	if err != nil {
+		t.Fatalf("OptionsFromConfig() error = %v", err)
+	}
+
+	e := NewEngine(&mongo.Database{}, "", nil, opts...)
+	if e.maxParallel != 4 || !e.repairDescriptions || !e.forbidDrops || !e.readOnly {
+		t.Errorf("engine = maxParallel %d, repairDescriptions %t, forbidDrops %t, readOnly %t; want 4 and all set",
+			e.maxParallel, e.repairDescriptions, e.forbidDrops, e.readOnly)
+	}
+	if e.floor != cfg.FloorVersion {
+		t.Errorf("floor = %q, want %q", e.floor, cfg.FloorVersion)
+	}
+
+	if _, err := OptionsFromConfig(&config.Config{VersionOrder: "random"}); err == nil {
+		t.Error("OptionsFromConfig() accepted an unknown version order")
+	}
+}
@@ -0,0 +1,86 @@
+package migration
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DiscoveredMigration is a migration found in source by DiscoverMigrations.
+type DiscoveredMigration struct {
+	File    string
+	Type    string
+	Version string
+}
+
+// DiscoverMigrations parses the non-test .go files in dir and returns every type whose
+// Version method returns a string literal. It does not compile or load the code, so it
+// can point out migrations that exist on disk but were never registered.
+func DiscoverMigrations(dir string) ([]DiscoveredMigration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []DiscoveredMigration
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		inFile, err := DiscoverFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, inFile...)
+	}
+	return found, nil
+}
+
+// DiscoverFile is DiscoverMigrations for a single file.
+func DiscoverFile(path string) ([]DiscoveredMigration, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	var found []DiscoveredMigration
+	for _, decl := range file.Decls {
+		if typ, version, ok := versionMethod(decl); ok {
+			found = append(found, DiscoveredMigration{File: path, Type: typ, Version: version})
+		}
+	}
+	return found, nil
+}
+
+// versionMethod matches `func (m *T) Version() string { return "literal" }`.
+func versionMethod(decl ast.Decl) (typ, version string, ok bool) {
+	fn, isFunc := decl.(*ast.FuncDecl)
+	if !isFunc || fn.Recv == nil || fn.Name.Name != "Version" || fn.Body == nil || len(fn.Body.List) != 1 {
+		return "", "", false
+	}
+	ret, isReturn := fn.Body.List[0].(*ast.ReturnStmt)
+	if !isReturn || len(ret.Results) != 1 {
+		return "", "", false
+	}
+	lit, isLit := ret.Results[0].(*ast.BasicLit)
+	if !isLit || lit.Kind != token.STRING {
+		return "", "", false
+	}
+	version, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", false
+	}
+
+	recv := fn.Recv.List[0].Type
+	if star, isStar := recv.(*ast.StarExpr); isStar {
+		recv = star.X
+	}
+	if ident, isIdent := recv.(*ast.Ident); isIdent {
+		typ = ident.Name
+	}
+	return typ, version, true
+}
@@ -6,14 +6,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"slices"
-	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
 )
 
 const (
@@ -21,6 +25,7 @@ const (
 	collLock              = "migrations_lock"
 	collMigrations        = "schema_migrations"
 	logExecutingMigration = "Executing migration"
+	logMigrationPlan      = "Migration plan"
 )
 
 type Migration interface {
@@ -30,11 +35,78 @@ type Migration interface {
 	Down(ctx context.Context, db *mongo.Database) error
 }
 
+// Independent is an optional interface for migrations that neither depend on nor affect
+// other migrations, which lets the engine run them concurrently when MaxParallel > 1.
+type Independent interface {
+	Independent() bool
+}
+
+func isIndependent(m Migration) bool {
+	i, ok := m.(Independent)
+	return ok && i.Independent()
+}
+
+// Irreversible is an optional interface for migrations that cannot be rolled back.
+type Irreversible interface {
+	Irreversible() bool
+}
+
+// Reversible is an optional interface that reports whether Down actually undoes Up,
+// for migrations whose Down is an empty placeholder.
+type Reversible interface {
+	HasDown() bool
+}
+
+func isReversible(m Migration) bool {
+	if i, ok := m.(Irreversible); ok && i.Irreversible() {
+		return false
+	}
+	if r, ok := m.(Reversible); ok && !r.HasDown() {
+		return false
+	}
+	return true
+}
+
+// EnvironmentScoped is an optional interface for migrations that only belong in some
+// deployment environments, such as seed data for development. See WithEnvironment.
+type EnvironmentScoped interface {
+	Environments() []string
+}
+
+// runsIn reports whether m applies to env. Migrations without Environments, or with an
+// empty list, run everywhere; otherwise env must match one entry, ignoring case.
+func runsIn(m Migration, env string) bool {
+	s, ok := m.(EnvironmentScoped)
+	if !ok {
+		return true
+	}
+	envs := s.Environments()
+	if len(envs) == 0 {
+		return true
+	}
+	env = strings.TrimSpace(env)
+	return slices.ContainsFunc(envs, func(e string) bool { return strings.EqualFold(strings.TrimSpace(e), env) })
+}
+
+// Tagged is an optional interface a Migration can implement to be selected by tag.
+type Tagged interface {
+	Tags() []string
+}
+
+// Annotated is an optional interface for migrations that carry governance metadata such
+// as an author or ticket reference. The metadata is stored on the migration's record.
+type Annotated interface {
+	Metadata() map[string]string
+}
+
 type MigrationRecord struct {
-	Version     string    `bson:"version"`
-	Description string    `bson:"description"`
-	AppliedAt   time.Time `bson:"applied_at"`
-	Checksum    string    `bson:"checksum"`
+	Version     string            `bson:"version" json:"version"`
+	Description string            `bson:"description" json:"description"`
+	AppliedAt   time.Time         `bson:"applied_at" json:"applied_at"`
+	Checksum    string            `bson:"checksum" json:"checksum"`
+	Metadata    map[string]string `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	// DurationMS is how long Up took. Records written by older versions leave it at zero.
+	DurationMS int64 `bson:"duration_ms,omitempty" json:"duration_ms,omitempty"`
 }
 
 type MigrationStatus struct {
@@ -42,19 +114,108 @@ type MigrationStatus struct {
 	Description string     `json:"description"`
 	Applied     bool       `json:"applied"`
 	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	// Checksum is the one recorded when the migration was applied; empty while pending.
+	Checksum string `json:"checksum,omitempty"`
 }
 
 type Engine struct {
-	db         *mongo.Database
-	migrations map[string]Migration
-	coll       string
+	db                 *mongo.Database
+	migrations         map[string]Migration
+	local              bool
+	coll               string
+	allowRunOne        bool
+	causalConsistency  bool
+	maxParallel        int
+	repairDescriptions bool
+	readOnly           bool
+	registry           *bson.Registry
+	expectedDatabase   string
+	auditColl          string
+	forbidDrops        bool
+	strictChecksums    bool
+	requireReversible  bool
+	lockHeartbeat      time.Duration
+	staleLockAfter     time.Duration
+	recordWrite        *writeconcern.WriteConcern
+	recordRead         *readconcern.ReadConcern
+	progress           *progressWriter
+	onProgress         func(ProgressUpdate)
+	onTiming           func(PhaseTiming)
+	preflight          []PreflightCheck
+	compare            VersionComparator
+	// sorted caches the registry versions in ascending order; see sortedVersions.
+	sorted []string
+
+	disableTransactions bool
+	environment         string
+	failOnOrphaned      bool
+	floor               string
+	// bulkSem caps the BulkWriter batches in flight across the whole run.
+	bulkSem chan struct{}
+	// rateLimiter paces the batching helpers across the whole run; nil means unlimited.
+	rateLimiter *RateLimiter
 }
 
-func NewEngine(db *mongo.Database, coll string, migrations map[string]Migration) *Engine {
+func NewEngine(db *mongo.Database, coll string, migrations map[string]Migration, opts ...EngineOption) *Engine {
 	if coll == "" {
 		coll = collMigrations
 	}
-	return &Engine{db: db, migrations: migrations, coll: coll}
+	e := &Engine{
+		db:            db,
+		migrations:    migrations,
+		coll:          coll,
+		maxParallel:   1,
+		lockHeartbeat: defaultLockHeartbeat,
+		recordWrite:   writeconcern.Majority(),
+		bulkSem:       make(chan struct{}, defaultBulkConcurrency),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(e)
+		}
+	}
+	e.sorted = e.sortVersions()
+	return e
+}
+
+// NewEngineFromSlice is NewEngine for callers that keep their migrations in a slice. It
+// fails on nil entries and on empty or duplicated versions instead of silently keeping one.
+func NewEngineFromSlice(
+	db *mongo.Database, coll string, migrations []Migration, opts ...EngineOption,
+) (*Engine, error) {
+	if _, err := CheckMigrations(migrations); err != nil {
+		return nil, err
+	}
+	set := make(map[string]Migration, len(migrations))
+	for _, m := range migrations {
+		set[m.Version()] = m
+	}
+	return NewEngine(db, coll, set, opts...), nil
+}
+
+// RegisterLocal adds migrations to this engine only, leaving the global registry untouched.
+// The first call replaces the set the engine was built with, so an engine with local
+// migrations runs exactly those. Register before running; it is not safe during a run.
+func (e *Engine) RegisterLocal(ms ...Migration) error {
+	all := make([]Migration, 0, len(ms))
+	if e.local {
+		for _, m := range e.migrations {
+			all = append(all, m)
+		}
+	}
+	if _, err := CheckMigrations(append(all, ms...)); err != nil {
+		return err
+	}
+
+	if !e.local {
+		e.migrations = make(map[string]Migration, len(ms))
+		e.local = true
+	}
+	for _, m := range ms {
+		e.migrations[m.Version()] = m
+	}
+	e.sorted = e.sortVersions()
+	return nil
 }
 
 func (e *Engine) GetStatus(ctx context.Context) ([]MigrationStatus, error) {
@@ -62,9 +223,15 @@ func (e *Engine) GetStatus(ctx context.Context) ([]MigrationStatus, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrFailedToReadMigrations, err)
 	}
+	return e.buildStatus(applied), nil
+}
 
-	versions := e.getSortedVersions(DirectionUp)
+// buildStatus walks the cached version order once. Applied timestamps share one backing
+// array, so a call allocates twice regardless of the registry size.
+func (e *Engine) buildStatus(applied map[string]MigrationRecord) []MigrationStatus {
+	versions := e.sortedVersions()
 	status := make([]MigrationStatus, len(versions))
+	appliedAt := make([]time.Time, 0, len(applied))
 
 	for i, v := range versions {
 		m := e.migrations[v]
@@ -75,20 +242,107 @@ func (e *Engine) GetStatus(ctx context.Context) ([]MigrationStatus, error) {
 			Applied:     isApplied,
 		}
 		if isApplied {
-			status[i].AppliedAt = &rec.AppliedAt
+			appliedAt = append(appliedAt, rec.AppliedAt)
+			status[i].AppliedAt = &appliedAt[len(appliedAt)-1]
+			status[i].Checksum = rec.Checksum
 		}
 	}
-	return status, nil
+	return status
+}
+
+func (e *Engine) Up(ctx context.Context, target string) error {
+	_, err := e.UpWithResult(ctx, target)
+	return err
+}
+
+// UpWithResult is Up reporting how many migrations were pending and which of them ran.
+// Filters narrow the plan as in PlanFiltered.
+func (e *Engine) UpWithResult(ctx context.Context, target string, filters ...MigrationFilter) (RunResult, error) {
+	return e.run(ctx, DirectionUp, target, filters...)
+}
+
+// UpTagged applies pending migrations that carry at least one of the given tags.
+// Migrations that do not implement Tagged are skipped while a tag filter is active.
+func (e *Engine) UpTagged(ctx context.Context, tags []string) error {
+	_, err := e.UpWithResult(ctx, "", TagFilter(tags...))
+	return err
 }
 
-func (e *Engine) Up(ctx context.Context, target string) error { return e.run(ctx, DirectionUp, target) }
 func (e *Engine) Down(ctx context.Context, target string) error {
+	_, err := e.DownWithResult(ctx, target)
+	return err
+}
+
+// DownWithResult is Down reporting how many migrations were applied and which were rolled back.
+func (e *Engine) DownWithResult(ctx context.Context, target string) (RunResult, error) {
 	return e.run(ctx, DirectionDown, target)
 }
 
+// RunResult is the outcome of a run. Pending counts the migrations the run planned;
+// Executed lists those that ran, in order, and Skipped those Up left pending because
+// they are scoped to other environments. A run with nothing pending has all three empty.
+// When a run fails, Executed and Skipped hold the migrations that completed before it.
+type RunResult struct {
+	Direction Direction
+	Pending   int
+	Executed  []string
+	Skipped   []string
+}
+
+// UpSelected applies only the given versions that are still pending, in version order,
+// even if earlier migrations are pending. Skipping over pending migrations can break
+// assumptions later migrations make, so this is meant for targeted fixes.
+func (e *Engine) UpSelected(ctx context.Context, versions []string) error {
+	_, err := e.UpSelectedWithResult(ctx, versions)
+	return err
+}
+
+// UpSelectedWithResult is UpSelected reporting which of the versions ran.
+func (e *Engine) UpSelectedWithResult(ctx context.Context, versions []string) (RunResult, error) {
+	if err := e.checkSelected(versions); err != nil {
+		return RunResult{Direction: DirectionUp}, err
+	}
+	slog.Warn("Running selected migrations out of sequence; this can violate ordering invariants",
+		"versions", versions, "direction", DirectionUp)
+	return e.UpWithResult(ctx, "", VersionFilter(versions...))
+}
+
+// DownSelected rolls back only the given versions that are applied, newest first, leaving
+// newer applied migrations in place. See UpSelected.
+func (e *Engine) DownSelected(ctx context.Context, versions []string) error {
+	if err := e.checkSelected(versions); err != nil {
+		return err
+	}
+	for _, v := range versions {
+		if err := e.checkFloor(v); err != nil {
+			return err
+		}
+	}
+	slog.Warn("Rolling back selected migrations out of sequence; this can violate ordering invariants",
+		"versions", versions, "direction", DirectionDown)
+	_, err := e.run(ctx, DirectionDown, "", VersionFilter(versions...))
+	return err
+}
+
+func (e *Engine) checkSelected(versions []string) error {
+	for _, v := range versions {
+		if _, ok := e.migrations[v]; !ok {
+			return fmt.Errorf("%w: %s", ErrMigrationNotFound, v)
+		}
+	}
+	return nil
+}
+
 func (e *Engine) ListApplied(ctx context.Context) ([]MigrationRecord, error) {
-	coll := e.db.Collection(e.coll)
-	cur, err := coll.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "applied_at", Value: -1}}))
+	return e.ListAppliedBetween(ctx, nil, nil)
+}
+
+// ListAppliedBetween returns applied records whose applied_at falls within [since, until].
+// A nil bound is treated as open-ended.
+func (e *Engine) ListAppliedBetween(ctx context.Context, since, until *time.Time) ([]MigrationRecord, error) {
+	coll := e.records()
+	filter := appliedAtFilter(since, until)
+	cur, err := coll.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "applied_at", Value: -1}}))
 	if err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrFailedToReadMigrations, err)
 	}
@@ -102,63 +356,472 @@ func (e *Engine) ListApplied(ctx context.Context) ([]MigrationRecord, error) {
 }
 
 func (e *Engine) Force(ctx context.Context, version string) error {
+	_, err := e.ForceWithResult(ctx, version)
+	return err
+}
+
+// ForceWithResult is Force reporting whether the version already had a record, in which
+// case the record is left as it was and PreviousAppliedAt says when it was applied. The
+// record is written under the migration lock.
+func (e *Engine) ForceWithResult(ctx context.Context, version string) (ForceResult, error) {
+	res := ForceResult{Version: version}
+	m, err := e.forceTarget(version)
+	if err != nil {
+		return res, err
+	}
+
+	lease, err := e.acquireLock(ctx)
+	if err != nil {
+		return res, err
+	}
+	defer e.releaseLock(context.Background(), lease)
+	return e.forceOne(withLease(ctx, lease), m)
+}
+
+// forceTarget returns the migration Force would mark, or why it cannot.
+func (e *Engine) forceTarget(version string) (Migration, error) {
+	if e.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := e.checkDatabase(); err != nil {
+		return nil, err
+	}
+	m, ok := e.migrations[version]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMigrationNotFound, version)
+	}
+	return m, nil
+}
+
+// forceOne writes the record of m unless it has one. The caller holds the lock; the
+// fence is checked before the write.
+func (e *Engine) forceOne(ctx context.Context, m Migration) (ForceResult, error) {
+	res := ForceResult{Version: m.Version()}
+	coll := e.records()
+	var existing MigrationRecord
+	err := coll.FindOne(ctx, bson.M{"version": m.Version()}).Decode(&existing)
+	switch {
+	case err == nil:
+		res.AlreadyApplied, res.PreviousAppliedAt = true, existing.AppliedAt
+		return res, nil
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return res, fmt.Errorf("%w: %w", ErrFailedToReadMigrations, err)
+	}
+
+	if err := e.checkFence(ctx); err != nil {
+		return res, err
+	}
+	if _, err := coll.InsertOne(ctx, e.newRecord(m)); err != nil {
+		return res, fmt.Errorf("%w: %w", ErrFailedToSetVersion, err)
+	}
+	return res, nil
+}
+
+// RunOne executes a single migration in the given direction regardless of whether it
+// is currently applied, then updates its record to match. It bypasses plan ordering and
+// checksum validation, so it is intended as a debugging tool and must be enabled with
+// WithAllowRunOne.
+func (e *Engine) RunOne(ctx context.Context, version string, dir Direction) error {
+	if !e.allowRunOne {
+		return ErrRunOneDisabled
+	}
 	m, ok := e.migrations[version]
 	if !ok {
-		return fmt.Errorf("%s: %s", ErrMigrationNotFound, version)
+		return fmt.Errorf("%w: %s", ErrMigrationNotFound, version)
+	}
+	if dir == DirectionUp {
+		if err := e.checkReversible([]string{version}); err != nil {
+			return err
+		}
+		e.warnUnguarded([]string{version})
+	} else if err := e.checkFloor(version); err != nil {
+		return err
+	}
+
+	lease, err := e.acquireLock(ctx)
+	if err != nil {
+		return err
+	}
+	defer e.releaseLock(context.Background(), lease)
+	ctx = withLease(ctx, lease)
+
+	slog.Warn("Re-running single migration", "version", version, "direction", dir)
+	work := func(sCtx context.Context) error { return e.performOne(sCtx, m, dir) }
+	start := time.Now()
+	if dir == DirectionDown {
+		err = e.archiveAround(ctx, m, func() error { return e.transact(ctx, work) })
+	} else {
+		err = e.transact(ctx, work)
+	}
+	e.audit(version, dir, start, err)
+	if err != nil {
+		return &MigrationError{Version: version, Direction: dir, Err: err}
+	}
+	return nil
+}
+
+// ForceResult is the outcome of force-marking one version. AlreadyApplied is set when
+// the version had a record before, which Force leaves untouched.
+type ForceResult struct {
+	Version           string
+	AlreadyApplied    bool
+	PreviousAppliedAt time.Time
+	Err               error
+}
+
+// ForceBatch force-marks each version under a single lock acquisition, renewed by the
+// heartbeat like a run. A failure for one version is recorded in its result and does not
+// stop the remaining versions, except losing the lock: the batch then stops and returns
+// the results so far with ErrLockLost.
+func (e *Engine) ForceBatch(ctx context.Context, versions []string) ([]ForceResult, error) {
+	lease, err := e.acquireLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer e.releaseLock(context.Background(), lease)
+	ctx = withLease(ctx, lease)
+
+	ctx, stopHeartbeat := e.startHeartbeat(ctx, lease)
+	defer stopHeartbeat()
+
+	results := make([]ForceResult, 0, len(versions))
+	for _, v := range versions {
+		res := ForceResult{Version: v}
+		m, err := e.forceTarget(v)
+		if err == nil {
+			res, err = e.forceOne(ctx, m)
+		}
+		res.Err = err
+		results = append(results, res)
+		if cause := context.Cause(ctx); cause != nil {
+			return results, cause
+		}
+		if errors.Is(err, ErrLockLost) {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+type ChecksumConflict struct {
+	Version  string
+	Recorded string
+	Current  string
+}
+
+type ImportResult struct {
+	Imported  []string
+	Conflicts []ChecksumConflict
+}
+
+// ImportRecords upserts records by version. Records whose checksum disagrees with the
+// registered migration of the same version are not written and are reported as conflicts.
+func (e *Engine) ImportRecords(ctx context.Context, records []MigrationRecord) (ImportResult, error) {
+	lease, err := e.acquireLock(ctx)
+	if err != nil {
+		return ImportResult{}, err
 	}
+	defer e.releaseLock(context.Background(), lease)
 
+	var result ImportResult
+	coll := e.records()
+	for _, rec := range records {
+		if rec.Version == "" {
+			return result, fmt.Errorf("%w: record without version", ErrInvalidMigrationVersion)
+		}
+		if m, ok := e.migrations[rec.Version]; ok {
+			if !e.checksumMatches(m, rec.Checksum) {
+				result.Conflicts = append(result.Conflicts, ChecksumConflict{
+					Version: rec.Version, Recorded: rec.Checksum, Current: e.calculateChecksum(m),
+				})
+				continue
+			}
+		}
+		_, err := coll.ReplaceOne(ctx, bson.M{"version": rec.Version}, rec, options.Replace().SetUpsert(true))
+		if err != nil {
+			return result, fmt.Errorf("%w: %s: %w", ErrFailedToSetVersion, rec.Version, err)
+		}
+		result.Imported = append(result.Imported, rec.Version)
+	}
+	return result, nil
+}
+
+func (e *Engine) run(ctx context.Context, dir Direction, target string, filters ...MigrationFilter) (RunResult, error) {
+	res := RunResult{Direction: dir}
+	if dir == DirectionDown && target != "" {
+		if err := e.checkFloor(target); err != nil {
+			return res, err
+		}
+	}
+	start := time.Now()
+	lease, err := e.acquireLock(ctx)
+	e.recordTiming(PhaseTiming{Phase: PhaseLock}, start, err)
+	if err != nil {
+		return res, err
+	}
+	defer e.releaseLock(context.Background(), lease) // to release on cancel
+	ctx = withLease(ctx, lease)
+
+	ctx, stopHeartbeat := e.startHeartbeat(ctx, lease)
+	defer stopHeartbeat()
+
+	ctx, endSession := e.withRunSession(ctx)
+	defer endSession()
+
+	start = time.Now()
 	applied, err := e.getAppliedMap(ctx)
+	e.recordTiming(PhaseTiming{Phase: PhaseStatus}, start, err)
 	if err != nil {
-		return fmt.Errorf("%s: %w", ErrFailedToReadMigrations, err)
+		return res, err
+	}
+	if err := e.checkOrphaned(applied); err != nil {
+		return res, err
+	}
+	if dir == DirectionUp {
+		if err := e.verifyApplied(ctx, applied); err != nil {
+			return res, err
+		}
 	}
 
-	if _, exists := applied[version]; exists {
+	plan, err := e.PlanFiltered(ctx, dir, target, filters...)
+	if err != nil {
+		return res, err
+	}
+	logPlan(ctx, dir, target, plan)
+	res.Pending = len(plan)
+	if dir == DirectionDown {
+		e.logFloorStop(ctx, applied)
+	}
+	if dir == DirectionUp {
+		if err := e.checkReversible(plan); err != nil {
+			return res, err
+		}
+		e.warnUnguarded(plan)
+		if err := e.Preflight(ctx, e.preflight); err != nil {
+			return res, err
+		}
+	}
+
+	for _, batch := range e.batches(plan) {
+		// Stop between batches once ctx is done; the migrations already applied keep their records.
+		if ctx.Err() != nil {
+			return res, fmt.Errorf("%w before %s: %w", ErrInterrupted, batch[0], context.Cause(ctx))
+		}
+		done, err := e.executeBatch(ctx, batch, dir, applied)
+		// Record what completed before reporting an error, so a failed run still says what it applied.
+		for _, v := range done {
+			if dir == DirectionUp && !runsIn(e.migrations[v], e.environment) {
+				res.Skipped = append(res.Skipped, v)
+				continue
+			}
+			res.Executed = append(res.Executed, v)
+		}
+		if err != nil {
+			if cause := context.Cause(ctx); errors.Is(cause, ErrLockLost) && !errors.Is(err, ErrLockLost) {
+				return res, fmt.Errorf("%w: %w", cause, err)
+			}
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// logPlan records the versions a run is about to execute, in order, as one structured
+// entry. It is logged for an empty plan too, so every run leaves a trace.
+func logPlan(ctx context.Context, dir Direction, target string, plan []string) {
+	versions := plan
+	if versions == nil {
+		versions = []string{}
+	}
+	slog.InfoContext(ctx, logMigrationPlan,
+		"direction", dir, "target", target, "count", len(versions), "plan", versions)
+}
+
+// checkOrphaned rejects the run when WithFailOnOrphaned is set and any applied record has
+// no registered migration, typically because its file was deleted.
+func (e *Engine) checkOrphaned(applied map[string]MigrationRecord) error {
+	if !e.failOnOrphaned {
+		return nil
+	}
+	var orphaned []string
+	for v := range applied {
+		if _, ok := e.migrations[v]; !ok {
+			orphaned = append(orphaned, v)
+		}
+	}
+	if len(orphaned) == 0 {
 		return nil
 	}
+	slices.SortFunc(orphaned, e.CompareVersions)
+	return fmt.Errorf("%w: %s", ErrOrphanedRecords, strings.Join(orphaned, ", "))
+}
 
-	coll := e.db.Collection(e.coll)
-	if _, err := coll.InsertOne(ctx, e.newRecord(m)); err != nil {
-		return fmt.Errorf("%s: %w", ErrFailedToSetVersion, err)
+// belowFloor reports whether v is at or below the floor set with WithFloorVersion.
+func (e *Engine) belowFloor(v string) bool {
+	return e.floor != "" && e.CompareVersions(v, e.floor) <= 0
+}
+
+func (e *Engine) checkFloor(v string) error {
+	if e.belowFloor(v) {
+		return fmt.Errorf("%w %s: cannot roll back %s", ErrBelowFloor, e.floor, v)
 	}
 	return nil
 }
 
-func (e *Engine) run(ctx context.Context, dir Direction, target string) error {
-	if err := e.acquireLock(ctx); err != nil {
-		return err
+// FloorStop returns the floor version when it keeps a down run to target from rolling back
+// an applied migration accepted by filters, and "" otherwise. A target is above the floor,
+// so a run to one ends before reaching it.
+func (e *Engine) FloorStop(ctx context.Context, target string, filters ...MigrationFilter) (string, error) {
+	if e.floor == "" || target != "" {
+		return "", nil
 	}
-	defer e.releaseLock(context.Background()) // to release on cancel
-
 	applied, err := e.getAppliedMap(ctx)
 	if err != nil {
-		return err
+		return "", err
+	}
+	for v := range applied {
+		if e.belowFloor(v) && matchesFilters(v, e.migrations[v], filters) {
+			return e.floor, nil
+		}
 	}
+	return "", nil
+}
 
-	plan, err := e.Plan(ctx, dir, target)
+// logFloorStop notes when the floor keeps applied migrations from being rolled back, so
+// a run that stops early says why.
+func (e *Engine) logFloorStop(ctx context.Context, applied map[string]MigrationRecord) {
+	for v := range applied {
+		if e.belowFloor(v) {
+			slog.InfoContext(ctx, "Rollback stops at the floor version", "floor", e.floor)
+			return
+		}
+	}
+}
+
+// checkReversible rejects the whole plan when WithRequireReversible is set and any of its
+// migrations cannot be rolled back, so nothing is applied.
+func (e *Engine) checkReversible(plan []string) error {
+	if !e.requireReversible {
+		return nil
+	}
+	for _, v := range plan {
+		if !isReversible(e.migrations[v]) {
+			return fmt.Errorf("%w: %s", ErrIrreversible, v)
+		}
+	}
+	return nil
+}
+
+func (e *Engine) executeOne(
+	ctx context.Context, version string, dir Direction, applied map[string]MigrationRecord,
+) error {
+	m := e.migrations[version]
+
+	if dir == DirectionUp {
+		if rec, ok := applied[version]; ok {
+			if err := e.validateChecksum(m, rec); err != nil {
+				return &MigrationError{Version: version, Direction: dir, Err: err}
+			}
+		}
+	}
+
+	if dir == DirectionUp && !runsIn(m, e.environment) {
+		slog.Info("Skipping migration outside its environments", "version", version, "environment", e.environment)
+		e.auditSkip(version, dir, "not enabled for environment "+strconv.Quote(e.environment))
+		return nil
+	}
+
+	slog.Info(logExecutingMigration, "version", version, "direction", dir)
+	e.progress.start(version, dir)
+	start := time.Now()
+	runCtx := e.withRateLimiter(e.withBulkSemaphore(e.withProgressReporter(ctx, version, dir)))
+	err := e.executeWithRetry(runCtx, m, dir)
+	e.progress.finish(version, dir, time.Since(start), err)
+	e.recordTiming(PhaseTiming{Phase: PhaseMigration, Version: version, Direction: dir}, start, err)
+	e.audit(version, dir, start, err)
 	if err != nil {
-		return err
+		return &MigrationError{Version: version, Direction: dir, Err: err}
+	}
+	return nil
+}
+
+// executeBatch runs a batch of migrations, concurrently when it holds more than one.
+// After the first failure, migrations that have not started yet are skipped.
+func (e *Engine) executeBatch(
+	ctx context.Context, batch []string, dir Direction, applied map[string]MigrationRecord,
+) ([]string, error) {
+	if len(batch) == 1 {
+		if err := e.executeOne(ctx, batch[0], dir, applied); err != nil {
+			return nil, err
+		}
+		return batch, nil
 	}
 
-	for _, version := range plan {
-		m := e.migrations[version]
+	bCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-		if dir == DirectionUp {
-			if rec, ok := applied[version]; ok {
-				if err := e.validateChecksum(m, rec); err != nil {
-					return err
-				}
+	sem := make(chan struct{}, e.maxParallel)
+	errs := make([]error, len(batch))
+	ran := make([]bool, len(batch))
+	var wg sync.WaitGroup
+	for i, version := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if bCtx.Err() != nil {
+				return
+			}
+			if errs[i] = e.executeOne(bCtx, version, dir, applied); errs[i] != nil {
+				cancel()
+				return
 			}
+			ran[i] = true
+		}()
+	}
+	wg.Wait()
+	var done []string
+	for i, version := range batch {
+		if ran[i] {
+			done = append(done, version)
 		}
+	}
+	return done, errors.Join(errs...)
+}
 
-		slog.Info(logExecutingMigration, "version", version, "direction", dir)
-		if err := e.executeWithRetry(ctx, m, dir); err != nil {
-			return fmt.Errorf("%w: %s: %w", ErrFailedToRunMigration, version, err)
+// batches splits a plan into groups that may run together. Consecutive migrations that
+// implement Independent share a batch when parallelism is enabled; everything else runs alone.
+// A shared causally consistent session cannot be used concurrently, so it forces serial runs.
+func (e *Engine) batches(plan []string) [][]string {
+	parallel := e.maxParallel > 1 && !e.causalConsistency
+	var out [][]string
+	for _, version := range plan {
+		if parallel && isIndependent(e.migrations[version]) && len(out) > 0 {
+			last := out[len(out)-1]
+			if isIndependent(e.migrations[last[0]]) {
+				out[len(out)-1] = append(last, version)
+				continue
+			}
 		}
+		out = append(out, []string{version})
 	}
-	return nil
+	return out
 }
 
 func (e *Engine) Plan(ctx context.Context, dir Direction, target string) ([]string, error) {
+	return e.PlanFiltered(ctx, dir, target)
+}
+
+// PlanFiltered is Plan restricted to migrations accepted by every filter.
+func (e *Engine) PlanFiltered(
+	ctx context.Context, dir Direction, target string, filters ...MigrationFilter,
+) ([]string, error) {
+	if dir == DirectionDown && target != "" {
+		if err := e.checkFloor(target); err != nil {
+			return nil, err
+		}
+	}
 	applied, err := e.getAppliedMap(ctx)
 	if err != nil {
 		return nil, err
@@ -168,10 +831,14 @@ func (e *Engine) Plan(ctx context.Context, dir Direction, target string) ([]stri
 	var plan []string
 
 	for _, v := range versions {
+		// Versions come newest first on the way down, so nothing past the floor qualifies.
+		if dir == DirectionDown && e.belowFloor(v) {
+			break
+		}
 		_, isApplied := applied[v]
 		shouldInclude := (dir == DirectionUp && !isApplied) || (dir == DirectionDown && isApplied)
 
-		if shouldInclude {
+		if shouldInclude && matchesFilters(v, e.migrations[v], filters) {
 			plan = append(plan, v)
 		}
 		if target != "" && v == target {
@@ -181,7 +848,29 @@ func (e *Engine) Plan(ctx context.Context, dir Direction, target string) ([]stri
 	return plan, nil
 }
 
+// EnsureMigrationsCollection creates the migrations collection if it does not exist yet,
+// which also materializes the database on a fresh cluster. It reports whether it created it.
+func (e *Engine) EnsureMigrationsCollection(ctx context.Context) (bool, error) {
+	if e.readOnly {
+		return false, ErrReadOnly
+	}
+	exists, err := collectionExists(ctx, e.db, e.coll)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+	if err := e.db.CreateCollection(ctx, e.coll); err != nil {
+		return false, fmt.Errorf("create collection %s failed: %w", e.coll, err)
+	}
+	return true, nil
+}
+
 func (e *Engine) ForceUnlock(ctx context.Context) error {
+	if e.readOnly {
+		return ErrReadOnly
+	}
 	coll := e.db.Collection(collLock)
 	_, err := coll.DeleteMany(ctx, bson.M{"lock_id": defaultLockID})
 	if err != nil {
@@ -191,15 +880,31 @@ func (e *Engine) ForceUnlock(ctx context.Context) error {
 }
 
 func (e *Engine) executeWithRetry(ctx context.Context, m Migration, dir Direction) error {
-	work := func(sCtx context.Context) error { return e.perform(sCtx, m, dir) }
-	session, err := e.db.Client().StartSession()
-	if err != nil {
+	work := func() error {
+		return e.transact(ctx, func(sCtx context.Context) error { return e.perform(sCtx, m, dir) })
+	}
+	if dir == DirectionDown {
+		return e.archiveAround(ctx, m, work)
+	}
+	return work()
+}
+
+func (e *Engine) transact(ctx context.Context, work func(context.Context) error) error {
+	if e.disableTransactions {
 		return work(ctx)
 	}
-	defer session.EndSession(ctx)
+	session := mongo.SessionFromContext(ctx)
+	if session == nil {
+		s, err := e.db.Client().StartSession(e.sessionOptions())
+		if err != nil {
+			return work(ctx)
+		}
+		defer s.EndSession(ctx)
+		session = s
+	}
 
-	err = mongo.WithSession(ctx, session, func(sCtx context.Context) error {
-		if err := session.StartTransaction(); err != nil {
+	err := mongo.WithSession(ctx, session, func(sCtx context.Context) error {
+		if err := session.StartTransaction(e.transactionOptions()); err != nil {
 			return err
 		}
 		if err := work(sCtx); err != nil {
@@ -215,37 +920,151 @@ func (e *Engine) executeWithRetry(ctx context.Context, m Migration, dir Directio
 	return err
 }
 
+// withRunSession attaches a single causally consistent session to ctx so that every
+// migration in a run observes the writes of the ones before it. It is a no-op unless
+// causal consistency is enabled.
+func (e *Engine) withRunSession(ctx context.Context) (context.Context, func()) {
+	if !e.causalConsistency {
+		return ctx, func() {}
+	}
+	session, err := e.db.Client().StartSession(e.sessionOptions())
+	if err != nil {
+		slog.Warn("Causal consistency unavailable, continuing without a shared session", "error", err)
+		return ctx, func() {}
+	}
+	return mongo.NewSessionContext(ctx, session), func() { session.EndSession(context.Background()) }
+}
+
+func (e *Engine) sessionOptions() *options.SessionOptionsBuilder {
+	opts := options.Session()
+	if e.causalConsistency {
+		opts.SetCausalConsistency(true)
+	}
+	return opts
+}
+
+func (e *Engine) transactionOptions() *options.TransactionOptionsBuilder {
+	opts := options.Transaction()
+	if e.causalConsistency {
+		opts.SetReadConcern(readconcern.Majority()).SetWriteConcern(writeconcern.Majority())
+	}
+	return opts
+}
+
 func (e *Engine) perform(ctx context.Context, m Migration, dir Direction) error {
-	coll := e.db.Collection(e.coll)
+	coll := e.records()
 	if dir == DirectionUp {
-		if err := m.Up(ctx, e.db); err != nil {
+		started := time.Now()
+		if err := e.up(ctx, m); err != nil {
 			return err
 		}
-		_, err := coll.InsertOne(ctx, e.newRecord(m))
-		return err
+		if err := e.checkFence(ctx); err != nil {
+			return err
+		}
+		return e.insertRecord(ctx, e.timedRecord(m, started))
 	}
 
 	if err := m.Down(ctx, e.db); err != nil {
 		return err
 	}
+	if err := e.checkFence(ctx); err != nil {
+		return err
+	}
 	_, err := coll.DeleteOne(ctx, bson.M{"version": m.Version()})
 	return err
 }
 
+// insertRecord writes rec unless its version already has a record. A retried write whose
+// first attempt reached the server finds a record stamped after this run took the lock,
+// which counts as success; an older record means the migration really was applied
+// before and is reported as ErrAlreadyApplied.
+func (e *Engine) insertRecord(ctx context.Context, rec MigrationRecord) error {
+	coll := e.records()
+	filter := bson.M{"version": rec.Version}
+	res, err := coll.UpdateOne(ctx, filter, bson.M{"$setOnInsert": rec}, options.UpdateOne().SetUpsert(true))
+	if err != nil {
+		return err
+	}
+	if res.UpsertedCount > 0 {
+		return nil
+	}
+
+	var existing MigrationRecord
+	if err := coll.FindOne(ctx, filter).Decode(&existing); err != nil {
+		return err
+	}
+	if lease, ok := ctx.Value(leaseKey{}).(*lockLease); ok && !existing.AppliedAt.Before(lease.acquired) {
+		slog.Debug("Migration record already written by this run", "version", rec.Version)
+		return nil
+	}
+	return fmt.Errorf("%w: %s applied at %s", ErrAlreadyApplied, rec.Version, existing.AppliedAt.Format(time.RFC3339))
+}
+
+func (e *Engine) performOne(ctx context.Context, m Migration, dir Direction) error {
+	if dir == DirectionDown {
+		return e.perform(ctx, m, dir)
+	}
+	started := time.Now()
+	if err := e.up(ctx, m); err != nil {
+		return err
+	}
+	if err := e.checkFence(ctx); err != nil {
+		return err
+	}
+	_, err := e.records().ReplaceOne(ctx, bson.M{"version": m.Version()}, e.timedRecord(m, started),
+		options.Replace().SetUpsert(true))
+	return err
+}
+
+// records returns the migrations collection with the record read and write concerns
+// applied. Migration bodies use e.db directly and keep the client's defaults.
+func (e *Engine) records() *mongo.Collection {
+	return e.db.Collection(e.coll, e.recordsOptions())
+}
+
+func (e *Engine) recordsOptions() *options.CollectionOptionsBuilder {
+	opts := options.Collection()
+	if e.recordWrite != nil {
+		opts.SetWriteConcern(e.recordWrite)
+	}
+	if e.recordRead != nil {
+		opts.SetReadConcern(e.recordRead)
+	}
+	if e.registry != nil {
+		opts.SetRegistry(e.registry)
+	}
+	return opts
+}
+
 func (e *Engine) getSortedVersions(dir Direction) []string {
+	versions := slices.Clone(e.sortedVersions())
+	if dir == DirectionDown {
+		slices.Reverse(versions)
+	}
+	return versions
+}
+
+// sortedVersions returns the registry versions in ascending order without sorting them on
+// every call. The slice is shared, so callers must not modify it. An engine built without
+// NewEngine, or whose map changed size since, falls back to sorting a fresh copy.
+func (e *Engine) sortedVersions() []string {
+	if e.sorted != nil && len(e.sorted) == len(e.migrations) {
+		return e.sorted
+	}
+	return e.sortVersions()
+}
+
+func (e *Engine) sortVersions() []string {
 	versions := make([]string, 0, len(e.migrations))
 	for v := range e.migrations {
 		versions = append(versions, v)
 	}
-	sort.Strings(versions)
-	if dir == DirectionDown {
-		slices.Reverse(versions)
-	}
+	slices.SortFunc(versions, e.CompareVersions)
 	return versions
 }
 
 func (e *Engine) getAppliedMap(ctx context.Context) (map[string]MigrationRecord, error) {
-	cursor, err := e.db.Collection(e.coll).Find(ctx, bson.M{})
+	cursor, err := e.records().Find(ctx, bson.M{})
 	if err != nil {
 		return nil, err
 	}
@@ -261,44 +1080,131 @@ func (e *Engine) getAppliedMap(ctx context.Context) (map[string]MigrationRecord,
 	return applied, nil
 }
 
+func appliedAtFilter(since, until *time.Time) bson.D {
+	rng := bson.D{}
+	if since != nil {
+		rng = append(rng, bson.E{Key: "$gte", Value: since.UTC()})
+	}
+	if until != nil {
+		rng = append(rng, bson.E{Key: "$lte", Value: until.UTC()})
+	}
+	if len(rng) == 0 {
+		return bson.D{}
+	}
+	return bson.D{{Key: "applied_at", Value: rng}}
+}
+
 func (e *Engine) validateChecksum(m Migration, record MigrationRecord) error {
-	if current := e.calculateChecksum(m); record.Checksum != current {
-		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", m.Version(), record.Checksum, current)
+	if e.checksumMatches(m, record.Checksum) {
+		return nil
 	}
-	return nil
+	if e.isLegacyChecksum(m, record.Checksum) {
+		return fmt.Errorf("%w for %s: the record has a legacy checksum that does not cover the code; "+
+			"review the migration and run admin compact to rewrite it", ErrChecksumMismatch, m.Version())
+	}
+	if descriptionOnlyChange(m, record) {
+		return fmt.Errorf("%w for %s: %w (%q -> %q); rerun with --auto-repair-descriptions to update the stored checksum",
+			ErrChecksumMismatch, m.Version(), ErrDescriptionChanged, record.Description, m.Description())
+	}
+	return fmt.Errorf("%w for %s: expected %s, got %s",
+		ErrChecksumMismatch, m.Version(), record.Checksum, e.calculateChecksum(m))
 }
 
 func (e *Engine) calculateChecksum(m Migration) string {
-	data := fmt.Sprintf("%s:%s", m.Version(), m.Description())
+	return Checksum(m)
+}
+
+// checksumMatches reports whether sum is the current checksum of m. Records written before
+// the body of m was hashed only cover version and description, so an edited body goes
+// unnoticed for them. They are accepted with a warning until admin compact rewrites them,
+// or rejected with WithStrictChecksums.
+func (e *Engine) checksumMatches(m Migration, sum string) bool {
+	if sum == e.calculateChecksum(m) {
+		return true
+	}
+	if !e.isLegacyChecksum(m, sum) || e.strictChecksums {
+		return false
+	}
+	slog.Warn("Applied migration has a legacy checksum that does not cover its code; "+
+		"review it and run admin compact to rewrite the record", "version", m.Version())
+	return true
+}
+
+// isLegacyChecksum reports whether sum is the checksum m had before a body hash was
+// registered for it.
+func (e *Engine) isLegacyChecksum(m Migration, sum string) bool {
+	legacy := checksumOf(m.Version(), m.Description())
+	return sum == legacy && legacy != e.calculateChecksum(m)
+}
+
+// Checksum returns the checksum the engine records for m and compares on later runs. It
+// covers the version and description, plus the source of Up and Down once checksum-gen
+// registered a hash for them with RegisterBodyChecksums.
+func Checksum(m Migration) string {
+	return bodyChecksumOf(m.Version(), m.Description(), bodyChecksum(m.Version()))
+}
+
+// VerifyChecksums checks that each version in expected is registered and that its
+// checksum equals the expected one, such as a value recorded when the migration was
+// reviewed. Versions are checked in order and the first difference fails with
+// ErrUnexpectedChecksum; call it before Up so nothing runs when a binary was rebuilt with
+// different migrations.
+func (e *Engine) VerifyChecksums(expected map[string]string) error {
+	for _, version := range slices.Sorted(maps.Keys(expected)) {
+		m, ok := e.migrations[version]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrMigrationNotFound, version)
+		}
+		want := strings.ToLower(strings.TrimSpace(expected[version]))
+		if current := e.calculateChecksum(m); current != want {
+			return fmt.Errorf("%w for %s: expected %s, got %s", ErrUnexpectedChecksum, version, want, current)
+		}
+	}
+	return nil
+}
+
+func checksumOf(version, description string) string {
+	data := fmt.Sprintf("%s:%s", version, description)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
+}
+
+// bodyChecksumOf is checksumOf extended with the hash of the migration's source. Without
+// one it equals checksumOf, so existing checksums do not change.
+func bodyChecksumOf(version, description, body string) string {
+	if body == "" {
+		return checksumOf(version, description)
+	}
+	data := fmt.Sprintf("%s:%s:%s", version, description, body)
 	return fmt.Sprintf("%x", sha256.Sum256([]byte(data)))
 }
 
+// descriptionOnlyChange reports whether record still matches the checksum of its own stored
+// description and only the migration's current description differs from it.
+func descriptionOnlyChange(m Migration, record MigrationRecord) bool {
+	if record.Description == m.Description() {
+		return false
+	}
+	return record.Checksum == checksumOf(record.Version, record.Description) ||
+		record.Checksum == bodyChecksumOf(record.Version, record.Description, bodyChecksum(record.Version))
+}
+
 func (e *Engine) newRecord(m Migration) MigrationRecord {
-	return MigrationRecord{
+	rec := MigrationRecord{
 		Version:     m.Version(),
 		Description: m.Description(),
 		AppliedAt:   time.Now().UTC(),
 		Checksum:    e.calculateChecksum(m),
 	}
-}
-
-func (e *Engine) acquireLock(ctx context.Context) error {
-	coll := e.db.Collection(collLock)
-
-	_, _ = coll.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		{Keys: bson.D{{Key: "acquired_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(600)},
-		{Keys: bson.D{{Key: "lock_id", Value: 1}}, Options: options.Index().SetUnique(true)},
-	})
-
-	_, err := coll.InsertOne(ctx, bson.M{"lock_id": defaultLockID, "acquired_at": time.Now().UTC()})
-	if mongo.IsDuplicateKeyError(err) {
-		return ErrFailedToLock
+	if a, ok := m.(Annotated); ok && len(a.Metadata()) > 0 {
+		rec.Metadata = maps.Clone(a.Metadata())
 	}
-	return err
+	return rec
 }
 
-func (e *Engine) releaseLock(ctx context.Context) {
-	_, _ = e.db.Collection(collLock).DeleteOne(ctx, bson.M{"lock_id": defaultLockID})
+func (e *Engine) timedRecord(m Migration, started time.Time) MigrationRecord {
+	rec := e.newRecord(m)
+	rec.DurationMS = time.Since(started).Milliseconds()
+	return rec
 }
 
 func isTransactionNotSupported(err error) bool {
@@ -2,9 +2,15 @@ package migration
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
@@ -52,6 +58,182 @@ func TestNewEngine(t *testing.T) {
 	}
 }
 
+func TestNewEngineFromSlice(t *testing.T) {
+	first := &TestMigration{version: "20240101_001"}
+	second := &TestMigration{version: "20240102_001"}
+
+	engine, err := NewEngineFromSlice(&mongo.Database{}, "", []Migration{first, second})
+	if err != nil {
+		t.Fatalf("NewEngineFromSlice() error = %v", err)
+	}
+	if len(engine.migrations) != 2 || engine.migrations[second.version] != second {
+		t.Errorf("migrations = %v, want both versions", engine.migrations)
+	}
+
+	tests := []struct {
+		name       string
+		migrations []Migration
+		wantErr    error
+	}{
+		{name: "Duplicate version", migrations: []Migration{first, second, &TestMigration{version: first.version}},
+			wantErr: ErrVersionExists},
+		{name: "Empty version", migrations: []Migration{first, &TestMigration{}}, wantErr: ErrInvalidMigrationVersion},
+		{name: "Nil migration", migrations: []Migration{first, nil}, wantErr: ErrInvalidMigrationVersion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewEngineFromSlice(&mongo.Database{}, "", tt.migrations)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewEngineFromSlice() error = %v, want %v", err, tt.wantErr)
+			}
+			if engine != nil {
+				t.Error("NewEngineFromSlice() must not return an engine on error")
+			}
+		})
+	}
+}
+
+func TestRegisterLocal(t *testing.T) {
+	global := &TestMigration{version: "20240101_001"}
+	a := NewEngine(&mongo.Database{}, "", map[string]Migration{global.version: global})
+	b := NewEngine(&mongo.Database{}, "", map[string]Migration{global.version: global})
+
+	onlyA := &TestMigration{version: "20240201_001"}
+	onlyB := &TestMigration{version: "20240202_001"}
+	if err := a.RegisterLocal(onlyA); err != nil {
+		t.Fatalf("RegisterLocal(a) error = %v", err)
+	}
+	if err := b.RegisterLocal(onlyB, global); err != nil {
+		t.Fatalf("RegisterLocal(b) error = %v", err)
+	}
+
+	if len(a.migrations) != 1 || a.migrations[onlyA.version] != onlyA {
+		t.Errorf("engine a migrations = %v, want only %s", a.migrations, onlyA.version)
+	}
+	if len(b.migrations) != 2 || b.migrations[onlyB.version] != onlyB || b.migrations[global.version] != global {
+		t.Errorf("engine b migrations = %v, want %s and %s", b.migrations, onlyB.version, global.version)
+	}
+	if _, ok := RegisteredMigrations()[onlyA.version]; ok {
+		t.Error("RegisterLocal must not touch the global registry")
+	}
+
+	if err := a.RegisterLocal(&TestMigration{version: onlyA.version}); !errors.Is(err, ErrVersionExists) {
+		t.Errorf("duplicate RegisterLocal error = %v, want %v", err, ErrVersionExists)
+	}
+	if len(a.migrations) != 1 {
+		t.Errorf("a rejected RegisterLocal must leave the engine unchanged, got %v", a.migrations)
+	}
+}
+
+type irreversibleMigration struct {
+	TestMigration
+	irreversible bool
+}
+
+func (m *irreversibleMigration) Irreversible() bool { return m.irreversible }
+
+type noDownMigration struct{ TestMigration }
+
+func (m *noDownMigration) HasDown() bool { return false }
+
+func TestRequireReversible(t *testing.T) {
+	plain := &TestMigration{version: "20240101_001"}
+	declared := &irreversibleMigration{TestMigration: TestMigration{version: "20240102_001"}, irreversible: true}
+	reversible := &irreversibleMigration{TestMigration: TestMigration{version: "20240103_001"}}
+	noDown := &noDownMigration{TestMigration{version: "20240104_001"}}
+	set := map[string]Migration{}
+	for _, m := range []Migration{plain, declared, reversible, noDown} {
+		set[m.Version()] = m
+	}
+
+	strict := NewEngine(&mongo.Database{}, "", set, WithRequireReversible(true))
+	tests := []struct {
+		name string
+		plan []string
+		want error
+	}{
+		{name: "Reversible plan", plan: []string{plain.version, reversible.version}},
+		{name: "Irreversible migration", plan: []string{plain.version, declared.version}, want: ErrIrreversible},
+		{name: "Empty Down", plan: []string{noDown.version}, want: ErrIrreversible},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := strict.checkReversible(tt.plan); !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Errorf("checkReversible(%v) = %v, want %v", tt.plan, err, tt.want)
+			}
+		})
+	}
+
+	lenient := NewEngine(&mongo.Database{}, "", set)
+	if err := lenient.checkReversible([]string{declared.version, noDown.version}); err != nil {
+		t.Errorf("without the option checkReversible = %v, want nil", err)
+	}
+
+	runOne := NewEngine(&mongo.Database{}, "", set, WithRequireReversible(true), WithAllowRunOne(true))
+	if err := runOne.RunOne(context.Background(), declared.version, DirectionUp); !errors.Is(err, ErrIrreversible) {
+		t.Errorf("RunOne() = %v, want %v before touching the database", err, ErrIrreversible)
+	}
+}
+
+func TestFailOnOrphaned(t *testing.T) {
+	known := &TestMigration{version: "20240101_001"}
+	set := map[string]Migration{known.version: known}
+	applied := map[string]MigrationRecord{
+		known.version:  {Version: known.version},
+		"20240301_001": {Version: "20240301_001"},
+		"20240201_001": {Version: "20240201_001"},
+	}
+
+	strict := NewEngine(&mongo.Database{}, "", set, WithFailOnOrphaned(true))
+	err := strict.checkOrphaned(applied)
+	if !errors.Is(err, ErrOrphanedRecords) {
+		t.Fatalf("checkOrphaned() = %v, want %v", err, ErrOrphanedRecords)
+	}
+	if !strings.HasSuffix(err.Error(), ": 20240201_001, 20240301_001") {
+		t.Errorf("checkOrphaned() = %q, want the orphaned versions in order", err)
+	}
+	if err := strict.checkOrphaned(map[string]MigrationRecord{known.version: {}}); err != nil {
+		t.Errorf("checkOrphaned() without orphans = %v, want nil", err)
+	}
+
+	lenient := NewEngine(&mongo.Database{}, "", set)
+	if err := lenient.checkOrphaned(applied); err != nil {
+		t.Errorf("without the option checkOrphaned = %v, want nil", err)
+	}
+}
+
+func TestFloorVersion(t *testing.T) {
+	ms := map[string]Migration{}
+	for _, v := range []string{"20240101_001", "20240201_001", "20240301_001"} {
+		ms[v] = &TestMigration{version: v}
+	}
+	ctx := context.Background()
+
+	// The zero Database panics on any command, so every refusal must happen before one.
+	floored := NewEngine(&mongo.Database{}, "", ms, WithFloorVersion("20240201_001"), WithAllowRunOne(true))
+	for name, run := range map[string]func() error{
+		"DownSelected": func() error { return floored.DownSelected(ctx, []string{"20240301_001", "20240101_001"}) },
+		"RunOne":       func() error { return floored.RunOne(ctx, "20240201_001", DirectionDown) },
+		"PlanToTarget": func() error { _, err := floored.Plan(ctx, DirectionDown, "20240101_001"); return err },
+		"DownToTarget": func() error { return floored.Down(ctx, "20240201_001") },
+	} {
+		err := run()
+		if !errors.Is(err, ErrBelowFloor) {
+			t.Fatalf("%s() = %v, want %v", name, err, ErrBelowFloor)
+		}
+		if !strings.Contains(err.Error(), "20240201_001") {
+			t.Errorf("%s() = %q, want it to name the floor", name, err)
+		}
+	}
+	if floored.belowFloor("20240301_001") || !floored.belowFloor("20240201_001") {
+		t.Error("belowFloor should cover the floor and older versions only")
+	}
+
+	if open := NewEngine(&mongo.Database{}, "", ms); open.checkFloor("20240101_001") != nil {
+		t.Error("without a floor every version may be rolled back")
+	}
+}
+
 func TestDirection(t *testing.T) {
 	tests := []struct {
 		direction Direction
@@ -103,3 +285,643 @@ func TestErrNotSupported(t *testing.T) {
 		t.Errorf("Expected error message %s, got %s", expected, err.Error())
 	}
 }
+
+func TestRunOneRequiresOptIn(t *testing.T) {
+	m := &TestMigration{version: "20240101_001", description: "Test migration"}
+	engine := NewEngine(&mongo.Database{}, "", map[string]Migration{m.version: m})
+
+	for _, dir := range []Direction{DirectionUp, DirectionDown} {
+		if err := engine.RunOne(context.Background(), m.version, dir); !errors.Is(err, ErrRunOneDisabled) {
+			t.Errorf("RunOne(%s) error = %v, want %v", dir, err, ErrRunOneDisabled)
+		}
+	}
+	if m.upExecuted || m.downExecuted {
+		t.Error("migration should not execute when RunOne is disabled")
+	}
+}
+
+func TestRunOneUnknownVersion(t *testing.T) {
+	engine := NewEngine(&mongo.Database{}, "", map[string]Migration{}, WithAllowRunOne(true))
+
+	err := engine.RunOne(context.Background(), "20240101_999", DirectionUp)
+	if !errors.Is(err, ErrMigrationNotFound) {
+		t.Errorf("RunOne error = %v, want %v", err, ErrMigrationNotFound)
+	}
+}
+
+func TestForceUnknownVersion(t *testing.T) {
+	engine := NewEngine(&mongo.Database{}, "", map[string]Migration{})
+
+	err := engine.Force(context.Background(), "20240101_999")
+	if !errors.Is(err, ErrMigrationNotFound) {
+		t.Errorf("Force error = %v, want %v", err, ErrMigrationNotFound)
+	}
+}
+
+func TestAppliedAtFilter(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	if f := appliedAtFilter(nil, nil); len(f) != 0 {
+		t.Errorf("expected empty filter, got %v", f)
+	}
+
+	f := appliedAtFilter(&since, &until)
+	if len(f) != 1 || f[0].Key != "applied_at" {
+		t.Fatalf("expected applied_at filter, got %v", f)
+	}
+	rng, ok := f[0].Value.(bson.D)
+	if !ok || len(rng) != 2 {
+		t.Fatalf("expected two range operators, got %v", f[0].Value)
+	}
+	if rng[0].Key != "$gte" || rng[1].Key != "$lte" {
+		t.Errorf("unexpected operators: %v", rng)
+	}
+
+	f = appliedAtFilter(nil, &until)
+	if rng := f[0].Value.(bson.D); len(rng) != 1 || rng[0].Key != "$lte" {
+		t.Errorf("expected only $lte, got %v", rng)
+	}
+}
+
+type independentMigration struct {
+	TestMigration
+}
+
+func (m *independentMigration) Independent() bool { return true }
+
+func TestBatches(t *testing.T) {
+	migrations := map[string]Migration{
+		"20240101_001": &independentMigration{TestMigration{version: "20240101_001"}},
+		"20240101_002": &independentMigration{TestMigration{version: "20240101_002"}},
+		"20240101_003": &TestMigration{version: "20240101_003"},
+		"20240101_004": &independentMigration{TestMigration{version: "20240101_004"}},
+		"20240101_005": &independentMigration{TestMigration{version: "20240101_005"}},
+	}
+	plan := []string{"20240101_001", "20240101_002", "20240101_003", "20240101_004", "20240101_005"}
+
+	tests := []struct {
+		name string
+		opts []EngineOption
+		want [][]string
+	}{
+		{
+			name: "Sequential by default",
+			want: [][]string{{"20240101_001"}, {"20240101_002"}, {"20240101_003"}, {"20240101_004"}, {"20240101_005"}},
+		},
+		{
+			name: "Independent runs are grouped",
+			opts: []EngineOption{WithMaxParallel(4)},
+			want: [][]string{{"20240101_001", "20240101_002"}, {"20240101_003"}, {"20240101_004", "20240101_005"}},
+		},
+		{
+			name: "Causal consistency forces sequential",
+			opts: []EngineOption{WithMaxParallel(4), WithCausalConsistency(true)},
+			want: [][]string{{"20240101_001"}, {"20240101_002"}, {"20240101_003"}, {"20240101_004"}, {"20240101_005"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewEngine(&mongo.Database{}, "", migrations, tt.opts...)
+			got := engine.batches(plan)
+			if len(got) != len(tt.want) {
+				t.Fatalf("batches() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if strings.Join(got[i], ",") != strings.Join(tt.want[i], ",") {
+					t.Errorf("batch %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExecutionOrder(t *testing.T) {
+	migrations := map[string]Migration{
+		"1.10.0": &TestMigration{version: "1.10.0", description: "ten"},
+		"1.2.0":  &independentMigration{TestMigration{version: "1.2.0", description: "two"}},
+		"1.3.0":  &independentMigration{TestMigration{version: "1.3.0", description: "three"}},
+		"1.9.0":  &TestMigration{version: "1.9.0", description: "nine"},
+	}
+	engine := NewEngine(nil, "", migrations, WithVersionComparator(CompareNumeric), WithMaxParallel(2))
+
+	want := []OrderEntry{
+		{Index: 1, Version: "1.2.0", Description: "two", Batch: 1},
+		{Index: 2, Version: "1.3.0", Description: "three", Batch: 1},
+		{Index: 3, Version: "1.9.0", Description: "nine", Batch: 2},
+		{Index: 4, Version: "1.10.0", Description: "ten", Batch: 3},
+	}
+	if got := engine.ExecutionOrder(); !slices.Equal(got, want) {
+		t.Errorf("ExecutionOrder() = %+v, want %+v", got, want)
+	}
+}
+
+func TestValidateChecksumDescriptionChange(t *testing.T) {
+	engine := NewEngine(&mongo.Database{}, "", nil)
+	original := &TestMigration{version: "20240101_001", description: "add users"}
+	record := engine.newRecord(original)
+
+	tests := []struct {
+		name            string
+		migration       Migration
+		record          MigrationRecord
+		wantErr         bool
+		descriptionOnly bool
+	}{
+		{name: "unchanged", migration: original, record: record},
+		{
+			name:            "description only",
+			migration:       &TestMigration{version: "20240101_001", description: "add users collection"},
+			record:          record,
+			wantErr:         true,
+			descriptionOnly: true,
+		},
+		{
+			// A record whose checksum no longer matches its own description was produced by
+			// something other than a description edit.
+			name:      "behavioral",
+			migration: &TestMigration{version: "20240101_001", description: "add users collection"},
+			record:    MigrationRecord{Version: record.Version, Description: record.Description, Checksum: "deadbeef"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.validateChecksum(tt.migration, tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrChecksumMismatch) {
+				t.Errorf("error %v does not match ErrChecksumMismatch", err)
+			}
+			if got := errors.Is(err, ErrDescriptionChanged); got != tt.descriptionOnly {
+				t.Errorf("errors.Is(err, ErrDescriptionChanged) = %v, want %v", got, tt.descriptionOnly)
+			}
+			if got := descriptionOnlyChange(tt.migration, tt.record); got != tt.descriptionOnly {
+				t.Errorf("descriptionOnlyChange() = %v, want %v", got, tt.descriptionOnly)
+			}
+		})
+	}
+}
+
+func TestReadOnlyEngineRefusesWrites(t *testing.T) {
+	// The zero Database would panic on any command, so reaching it would fail the test.
+	engine := NewEngine(&mongo.Database{}, "", map[string]Migration{
+		"20240101_001": &TestMigration{version: "20240101_001"},
+	}, WithReadOnly(true), WithAllowRunOne(true))
+	ctx := context.Background()
+
+	calls := map[string]func() error{
+		"Up":          func() error { return engine.Up(ctx, "") },
+		"UpTagged":    func() error { return engine.UpTagged(ctx, []string{"data"}) },
+		"Down":        func() error { return engine.Down(ctx, "") },
+		"Force":       func() error { return engine.Force(ctx, "20240101_001") },
+		"RunOne":      func() error { return engine.RunOne(ctx, "20240101_001", DirectionUp) },
+		"ForceUnlock": func() error { return engine.ForceUnlock(ctx) },
+		"ForceBatch": func() error {
+			_, err := engine.ForceBatch(ctx, []string{"20240101_001"})
+			return err
+		},
+		"ImportRecords": func() error {
+			_, err := engine.ImportRecords(ctx, nil)
+			return err
+		},
+		"EnsureMigrationsCollection": func() error {
+			_, err := engine.EnsureMigrationsCollection(ctx)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("%s() error = %v, want ErrReadOnly", name, err)
+			}
+		})
+	}
+}
+
+func TestRecordsRegistry(t *testing.T) {
+	if got := resolveCollectionOptions(t, NewEngine(&mongo.Database{}, "", nil).recordsOptions()); got.Registry != nil {
+		t.Errorf("Registry = %p, want nil so the database registry applies", got.Registry)
+	}
+
+	reg := bson.NewRegistry()
+	got := resolveCollectionOptions(t, NewEngine(&mongo.Database{}, "", nil, WithRegistry(reg)).recordsOptions())
+	if got.Registry != reg {
+		t.Errorf("Registry = %p, want %p", got.Registry, reg)
+	}
+}
+
+func TestExplainPlan(t *testing.T) {
+	migrations := map[string]Migration{}
+	for _, v := range []string{"001", "002", "003", "004", "005"} {
+		migrations[v] = &TestMigration{version: v, description: "migration " + v}
+	}
+	engine := NewEngine(&mongo.Database{}, "", migrations)
+	applied := map[string]MigrationRecord{"001": {Version: "001"}, "003": {Version: "003"}}
+
+	tests := []struct {
+		name   string
+		dir    Direction
+		target string
+		want   []PlanEntry
+	}{
+		{
+			name:   "Up to target",
+			dir:    DirectionUp,
+			target: "004",
+			want: []PlanEntry{
+				{Version: "001", Reason: ReasonApplied},
+				{Version: "002", Run: true, Reason: ReasonOutOfOrder},
+				{Version: "003", Reason: ReasonApplied},
+				{Version: "004", Run: true, Reason: ReasonPending},
+				{Version: "005", Reason: ReasonPastTarget},
+			},
+		},
+		{
+			name:   "Down to target",
+			dir:    DirectionDown,
+			target: "003",
+			want: []PlanEntry{
+				{Version: "005", Reason: ReasonPending},
+				{Version: "004", Reason: ReasonPending},
+				{Version: "003", Run: true, Reason: ReasonBeforeTarget},
+				{Version: "002", Reason: ReasonPastTarget},
+				{Version: "001", Reason: ReasonPastTarget},
+			},
+		},
+		{
+			name: "Down without target",
+			dir:  DirectionDown,
+			want: []PlanEntry{
+				{Version: "005", Reason: ReasonPending},
+				{Version: "004", Reason: ReasonPending},
+				{Version: "003", Run: true, Reason: ReasonApplied},
+				{Version: "002", Reason: ReasonPending},
+				{Version: "001", Run: true, Reason: ReasonApplied},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := engine.explain(tt.dir, tt.target, applied)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d entries, want %d", len(got), len(tt.want))
+			}
+			for i, want := range tt.want {
+				want.Description = "migration " + want.Version
+				if got[i] != want {
+					t.Errorf("entry %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExpectedDatabaseAbortsBeforeLock(t *testing.T) {
+	// The zero Database is named "" and panics on any command, so the guard must fire first.
+	engine := NewEngine(&mongo.Database{}, "", nil, WithExpectedDatabase("app_production"))
+
+	err := engine.Up(context.Background(), "")
+	if !errors.Is(err, ErrWrongDatabase) {
+		t.Fatalf("Up() error = %v, want ErrWrongDatabase", err)
+	}
+	if !strings.Contains(err.Error(), `expected "app_production", connected to ""`) {
+		t.Errorf("error %q does not name the expected and actual database", err)
+	}
+	if err := engine.Force(context.Background(), "20240101_001"); !errors.Is(err, ErrWrongDatabase) {
+		t.Errorf("Force() error = %v, want ErrWrongDatabase", err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	first := &TestMigration{version: "001", description: "first"}
+	second := &TestMigration{version: "002", description: "second"}
+	engine := NewEngine(&mongo.Database{}, "", map[string]Migration{"001": first, "002": second})
+
+	tests := []struct {
+		name    string
+		applied map[string]MigrationRecord
+		want    string
+	}{
+		{name: "Nothing applied", want: "applied=0 pending=2 dirty=false"},
+		{
+			name:    "Clean",
+			applied: map[string]MigrationRecord{"001": engine.newRecord(first)},
+			want:    "applied=1 pending=1 dirty=false",
+		},
+		{
+			name:    "Checksum mismatch",
+			applied: map[string]MigrationRecord{"001": {Version: "001", Checksum: "deadbeef"}},
+			want:    "applied=1 pending=1 dirty=true",
+		},
+		{
+			name: "Orphaned record",
+			applied: map[string]MigrationRecord{
+				"001": engine.newRecord(first),
+				"000": {Version: "000"},
+			},
+			want: "applied=2 pending=1 dirty=true",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := engine.summarize(tt.applied).String(); got != tt.want {
+				t.Errorf("summary = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type annotatedMigration struct {
+	TestMigration
+	metadata map[string]string
+}
+
+func (m *annotatedMigration) Metadata() map[string]string { return m.metadata }
+
+func TestNewRecordMetadata(t *testing.T) {
+	engine := NewEngine(&mongo.Database{}, "", nil)
+	md := map[string]string{"author": "jane", "ticket": "OPS-42"}
+
+	rec := engine.newRecord(&annotatedMigration{TestMigration{version: "001"}, md})
+	if len(rec.Metadata) != 2 || rec.Metadata["ticket"] != "OPS-42" {
+		t.Errorf("Metadata = %v, want %v", rec.Metadata, md)
+	}
+	md["author"] = "changed"
+	if rec.Metadata["author"] != "jane" {
+		t.Error("record metadata must not alias the migration's map")
+	}
+
+	if rec := engine.newRecord(&TestMigration{version: "002"}); rec.Metadata != nil {
+		t.Errorf("Metadata = %v for a migration without Metadata(), want nil", rec.Metadata)
+	}
+
+	data, err := bson.Marshal(engine.newRecord(&TestMigration{version: "003"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bson.Raw(data).LookupErr("metadata"); err == nil {
+		t.Error("metadata must be omitted from records without it")
+	}
+}
+
+func TestSafeDatabaseForbidsDrops(t *testing.T) {
+	// The zero Database panics on any command, so reaching it would fail the test.
+	forbidden := newSafeDatabase(&mongo.Database{}, true, DirectionUp, "20240101_001")
+	err := forbidden.Drop(context.Background())
+	if !errors.Is(err, ErrForbiddenOperation) || !strings.Contains(err.Error(), "20240101_001") {
+		t.Errorf("Drop() during up error = %v, want ErrForbiddenOperation naming the migration", err)
+	}
+
+	if err := newSafeDatabase(&mongo.Database{}, true, DirectionDown, "").checkDrop("collection users"); err != nil {
+		t.Errorf("checkDrop() during down = %v, want nil", err)
+	}
+	if err := newSafeDatabase(&mongo.Database{}, false, DirectionUp, "").checkDrop("collection users"); err != nil {
+		t.Errorf("checkDrop() without the policy = %v, want nil", err)
+	}
+}
+
+func TestSortedVersionsCache(t *testing.T) {
+	engine := NewEngine(nil, "", map[string]Migration{
+		"20240102_001": &TestMigration{version: "20240102_001"},
+		"20240101_001": &TestMigration{version: "20240101_001"},
+	})
+	if !slices.Equal(engine.sorted, []string{"20240101_001", "20240102_001"}) {
+		t.Fatalf("NewEngine cached %v", engine.sorted)
+	}
+
+	down := engine.getSortedVersions(DirectionDown)
+	if !slices.Equal(down, []string{"20240102_001", "20240101_001"}) || engine.sorted[0] != "20240101_001" {
+		t.Errorf("down order %v must not reorder the cache %v", down, engine.sorted)
+	}
+
+	if err := engine.RegisterLocal(&TestMigration{version: "20240103_001"}); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(engine.sortedVersions(), []string{"20240103_001"}) {
+		t.Errorf("RegisterLocal left a stale cache: %v", engine.sortedVersions())
+	}
+
+	status := engine.buildStatus(map[string]MigrationRecord{"20240103_001": {Version: "20240103_001"}})
+	if len(status) != 1 || !status[0].Applied || status[0].AppliedAt == nil {
+		t.Errorf("buildStatus() = %+v", status)
+	}
+}
+
+func BenchmarkBuildStatus(b *testing.B) {
+	const n = 5000
+	ms := make(map[string]Migration, n)
+	applied := make(map[string]MigrationRecord, n/2)
+	for i := range n {
+		v := fmt.Sprintf("%08d_migration", i)
+		ms[v] = &TestMigration{version: v}
+		if i%2 == 0 {
+			applied[v] = MigrationRecord{Version: v, AppliedAt: time.Now()}
+		}
+	}
+	engine := NewEngine(nil, "", ms)
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			engine.buildStatus(applied)
+		}
+	})
+	b.Run("resorted", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			engine.sorted = nil
+			engine.buildStatus(applied)
+		}
+	})
+}
+
+func TestDisableTransactionsSkipsSession(t *testing.T) {
+	// The zero Database has no client, so starting a session would panic.
+	engine := NewEngine(&mongo.Database{}, "", nil, WithDisableTransactions(true))
+
+	called := false
+	err := engine.transact(context.Background(), func(ctx context.Context) error {
+		called = true
+		if mongo.SessionFromContext(ctx) != nil {
+			t.Error("work ran inside a session")
+		}
+		return nil
+	})
+	if err != nil || !called {
+		t.Fatalf("transact() = %v, called = %v; want the work run directly", err, called)
+	}
+}
+
+type scopedMigration struct {
+	TestMigration
+	envs []string
+	ups  int
+}
+
+func (m *scopedMigration) Environments() []string { return m.envs }
+
+func (m *scopedMigration) Up(context.Context, *mongo.Database) error {
+	m.ups++
+	return nil
+}
+
+func TestRunsIn(t *testing.T) {
+	seed := &scopedMigration{envs: []string{"dev", "Staging"}}
+	tests := []struct {
+		name string
+		m    Migration
+		env  string
+		want bool
+	}{
+		{name: "Unscoped in production", m: &TestMigration{}, env: "production", want: true},
+		{name: "Unscoped without environment", m: &TestMigration{}, want: true},
+		{name: "Empty list", m: &scopedMigration{}, env: "production", want: true},
+		{name: "Listed", m: seed, env: "dev", want: true},
+		{name: "Listed ignoring case", m: seed, env: " staging ", want: true},
+		{name: "Not listed", m: seed, env: "production"},
+		{name: "No environment", m: seed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runsIn(tt.m, tt.env); got != tt.want {
+				t.Errorf("runsIn(%q) = %v, want %v", tt.env, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteOneSkipsOtherEnvironments(t *testing.T) {
+	seed := &scopedMigration{TestMigration: TestMigration{version: "20240101_001"}, envs: []string{"dev"}}
+	set := map[string]Migration{seed.version: seed}
+
+	// The zero Database has no client, so running the migration would panic.
+	engine := NewEngine(&mongo.Database{}, "", set, WithEnvironment("production"))
+	if err := engine.executeOne(context.Background(), seed.version, DirectionUp, nil); err != nil {
+		t.Fatalf("executeOne() = %v, want the migration skipped", err)
+	}
+	if seed.ups != 0 {
+		t.Fatalf("Up ran %d time(s) outside its environments", seed.ups)
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	m := &TestMigration{version: "20240101_001", description: "add index"}
+	engine := NewEngine(&mongo.Database{}, "", map[string]Migration{m.version: m})
+	reviewed := Checksum(m)
+	tampered := checksumOf(m.version, "tampered")
+
+	tests := []struct {
+		name     string
+		expected map[string]string
+		want     error
+	}{
+		{name: "Nothing expected"},
+		{name: "Matching", expected: map[string]string{m.version: reviewed}},
+		{name: "Matching ignoring case", expected: map[string]string{m.version: strings.ToUpper(reviewed)}},
+		{name: "Mismatch", expected: map[string]string{m.version: tampered}, want: ErrUnexpectedChecksum},
+		{name: "Unregistered", expected: map[string]string{"20240101_999": reviewed}, want: ErrMigrationNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := engine.VerifyChecksums(tt.expected)
+			if !errors.Is(err, tt.want) || (tt.want == nil && err != nil) {
+				t.Fatalf("VerifyChecksums() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+// recordingHandler keeps every record logged through it.
+type recordingHandler struct {
+	slog.Handler
+	records *[]slog.Record
+}
+
+func (recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	*h.records = append(*h.records, r)
+	return nil
+}
+
+func TestLogPlan(t *testing.T) {
+	var records []slog.Record
+	prev := slog.Default()
+	slog.SetDefault(slog.New(recordingHandler{Handler: slog.DiscardHandler, records: &records}))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	logPlan(context.Background(), DirectionDown, "001", []string{"003", "002"})
+	logPlan(context.Background(), DirectionUp, "", nil)
+
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want one per plan", len(records))
+	}
+	tests := []struct {
+		dir   Direction
+		plan  []string
+		count int64
+	}{
+		{DirectionDown, []string{"003", "002"}, 2},
+		{DirectionUp, []string{}, 0},
+	}
+	for i, tt := range tests {
+		r := records[i]
+		if r.Message != logMigrationPlan {
+			t.Errorf("record %d message = %q, want %q", i, r.Message, logMigrationPlan)
+		}
+		attrs := map[string]slog.Value{}
+		r.Attrs(func(a slog.Attr) bool {
+			attrs[a.Key] = a.Value
+			return true
+		})
+		if got := attrs["direction"].Any(); got != tt.dir {
+			t.Errorf("record %d direction = %v, want %v", i, got, tt.dir)
+		}
+		if got := attrs["count"].Int64(); got != tt.count {
+			t.Errorf("record %d count = %d, want %d", i, got, tt.count)
+		}
+		plan, ok := attrs["plan"].Any().([]string)
+		if !ok || !slices.Equal(plan, tt.plan) || plan == nil {
+			t.Errorf("record %d plan = %#v, want %#v", i, attrs["plan"].Any(), tt.plan)
+		}
+	}
+	if got := records[0].NumAttrs(); got != 4 {
+		t.Errorf("got %d attributes, want direction, target, count and plan", got)
+	}
+}
+
+type archivingMigration struct {
+	TestMigration
+	archive bool
+}
+
+func (m *archivingMigration) ArchiveOnDown() bool          { return m.archive }
+func (m *archivingMigration) ArchiveCollections() []string { return []string{"audit_events"} }
+
+func TestArchiveOnDownSkipsWithoutOptIn(t *testing.T) {
+	if got := ArchivedName("audit_events", "20240101_001"); got != "audit_events_archived_20240101_001" {
+		t.Errorf("ArchivedName() = %q", got)
+	}
+
+	// The zero database would panic on use, so these must not reach the server.
+	e := NewEngine(&mongo.Database{}, "", nil)
+	for _, m := range []Migration{
+		&TestMigration{version: "20240101_001"},
+		&archivingMigration{TestMigration: TestMigration{version: "20240101_002"}},
+	} {
+		ran := false
+		err := e.archiveAround(context.Background(), m, func() error { ran = true; return nil })
+		if err != nil || !ran {
+			t.Errorf("archiveAround(%s) = %v, ran down = %v; want nil and true", m.Version(), err, ran)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package migration
 
+import "fmt"
+
 type ErrorMigration string
 
 func (e ErrorMigration) Error() string {
@@ -9,6 +11,7 @@ func (e ErrorMigration) Error() string {
 const (
 	ErrInvalidMigrationVersion = ErrorMigration("invalid migration version")
 	ErrMigrationNotFound       = ErrorMigration("migration not found")
+	ErrVersionExists           = ErrorMigration("migration version already exists")
 	ErrFailedToGenerate        = ErrorMigration("failed to generate migration")
 	ErrFailedToReadTemplate    = ErrorMigration("failed to read template")
 	ErrFailedToParseTemplate   = ErrorMigration("failed to parse template")
@@ -17,8 +20,47 @@ const (
 	ErrFailedToConnect         = ErrorMigration("failed to connect to database")
 	ErrFailedToPing            = ErrorMigration("failed to ping database")
 	ErrFailedToLock            = ErrorMigration("failed to acquire lock")
+	ErrLockHeld                = ErrorMigration("migration lock is held by another run")
 	ErrFailedToUnlock          = ErrorMigration("failed to release lock")
+	ErrChecksumMismatch        = ErrorMigration("checksum mismatch")
+	ErrUnexpectedChecksum      = ErrorMigration("checksum differs from the expected one")
+	ErrDescriptionChanged      = ErrorMigration("only the description changed")
+	ErrInvalidConcern          = ErrorMigration("invalid read or write concern")
+	ErrLockLost                = ErrorMigration("migration lock was lost to another holder")
 	ErrFailedToReadMigrations  = ErrorMigration("failed to read migrations")
 	ErrFailedToRunMigration    = ErrorMigration("failed to run migration")
 	ErrFailedToSetVersion      = ErrorMigration("failed to set version")
+	ErrWrongDatabase           = ErrorMigration("refusing to migrate an unexpected database")
+	ErrForbiddenOperation      = ErrorMigration("operation forbidden by policy")
+	ErrReadOnly                = ErrorMigration("engine is read-only")
+	ErrInterrupted             = ErrorMigration("migration run interrupted")
+	ErrIrreversible            = ErrorMigration("migration has no rollback and reversible migrations are required")
+	ErrAlreadyApplied          = ErrorMigration("migration already has a record")
+	ErrPreflightFailed         = ErrorMigration("preflight checks failed")
+	ErrInvalidPreflight        = ErrorMigration("invalid preflight check")
+	ErrCheckFailed             = ErrorMigration("data check failed")
+	ErrInvalidVersionOrder     = ErrorMigration("invalid version order")
+	ErrFailedToEstimate        = ErrorMigration("failed to estimate migration cost")
+	ErrCompositeStepFailed     = ErrorMigration("composite migration step failed")
+	ErrBulkWriteFailed         = ErrorMigration("bulk write failed")
+	ErrOrphanedRecords         = ErrorMigration("applied migrations are missing from the code")
+	ErrFailedToArchive         = ErrorMigration("failed to archive collection")
+	ErrBelowFloor              = ErrorMigration("refusing to roll back at or below the floor version")
+	ErrRunOneDisabled          = ErrorMigration("running a single migration is disabled (enable AllowRunOne)")
 )
+
+// MigrationError reports which migration failed and in which direction. It matches
+// ErrFailedToRunMigration with errors.Is and unwraps to the underlying cause.
+type MigrationError struct {
+	Version   string
+	Direction Direction
+	Err       error
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", ErrFailedToRunMigration, e.Version, e.Err)
+}
+
+func (e *MigrationError) Unwrap() []error {
+	return []error{ErrFailedToRunMigration, e.Err}
+}
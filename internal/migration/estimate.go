@@ -0,0 +1,76 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// CostEstimate is a migration's rough idea of how much work its Up does, such as the
+// documents a backfill rewrites or the indexes it builds on large collections.
+type CostEstimate struct {
+	Documents int64  `json:"documents"`
+	Indexes   int    `json:"indexes"`
+	Note      string `json:"note,omitempty"`
+}
+
+// Add returns the sum of both estimates. Notes are not combined.
+func (c CostEstimate) Add(o CostEstimate) CostEstimate {
+	return CostEstimate{Documents: c.Documents + o.Documents, Indexes: c.Indexes + o.Indexes}
+}
+
+// Estimator is an optional interface for migrations that can estimate their cost before
+// running, typically with EstimatedDocumentCount on the collections they touch.
+type Estimator interface {
+	EstimateCost(ctx context.Context, db *mongo.Database) (CostEstimate, error)
+}
+
+// PlanEstimate is the estimate for one planned migration. Estimate is nil when the
+// migration does not implement Estimator, meaning its cost is unknown.
+type PlanEstimate struct {
+	Version     string        `json:"version"`
+	Description string        `json:"description"`
+	Estimate    *CostEstimate `json:"estimate,omitempty"`
+}
+
+// PlanCost is the estimate for a whole plan. Total only covers migrations with an
+// estimate; Unknown counts the rest.
+type PlanCost struct {
+	Migrations []PlanEstimate `json:"migrations"`
+	Total      CostEstimate   `json:"total"`
+	Unknown    int            `json:"unknown"`
+}
+
+// EstimatePlan asks every pending migration up to target for its cost, in execution order.
+// Nothing is applied and no lock is taken.
+func (e *Engine) EstimatePlan(ctx context.Context, target string) (PlanCost, error) {
+	plan, err := e.Plan(ctx, DirectionUp, target)
+	if err != nil {
+		return PlanCost{}, err
+	}
+	return e.estimate(ctx, plan)
+}
+
+func (e *Engine) estimate(ctx context.Context, plan []string) (PlanCost, error) {
+	cost := PlanCost{Migrations: make([]PlanEstimate, 0, len(plan))}
+	for _, v := range plan {
+		m := e.migrations[v]
+		entry := PlanEstimate{Version: v, Description: m.Description()}
+
+		est, ok := m.(Estimator)
+		if !ok {
+			cost.Unknown++
+			cost.Migrations = append(cost.Migrations, entry)
+			continue
+		}
+		c, err := est.EstimateCost(ctx, e.db)
+		if err != nil {
+			return PlanCost{}, fmt.Errorf("%w for %s: %w", ErrFailedToEstimate, v, err)
+		}
+		entry.Estimate = &c
+		cost.Total = cost.Total.Add(c)
+		cost.Migrations = append(cost.Migrations, entry)
+	}
+	return cost, nil
+}
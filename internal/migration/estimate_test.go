@@ -0,0 +1,62 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+type estimatedMigration struct {
+	TestMigration
+	cost CostEstimate
+	err  error
+}
+
+func (m *estimatedMigration) EstimateCost(context.Context, *mongo.Database) (CostEstimate, error) {
+	return m.cost, m.err
+}
+
+func TestEstimateSumsPlan(t *testing.T) {
+	backfill := &estimatedMigration{
+		TestMigration: TestMigration{version: "20240101_001"},
+		cost:          CostEstimate{Documents: 1200, Note: "rewrites users"},
+	}
+	index := &estimatedMigration{
+		TestMigration: TestMigration{version: "20240102_001"},
+		cost:          CostEstimate{Documents: 800, Indexes: 2},
+	}
+	plain := &TestMigration{version: "20240103_001"}
+	engine := NewEngine(nil, "", map[string]Migration{
+		backfill.version: backfill, index.version: index, plain.version: plain,
+	})
+
+	cost, err := engine.estimate(context.Background(), []string{backfill.version, index.version, plain.version})
+	if err != nil {
+		t.Fatalf("estimate() error = %v", err)
+	}
+	if cost.Total != (CostEstimate{Documents: 2000, Indexes: 2}) {
+		t.Errorf("Total = %+v, want 2000 documents and 2 indexes", cost.Total)
+	}
+	if cost.Unknown != 1 {
+		t.Errorf("Unknown = %d, want 1", cost.Unknown)
+	}
+	if len(cost.Migrations) != 3 || cost.Migrations[2].Estimate != nil {
+		t.Errorf("Migrations = %+v, want the plain migration last without an estimate", cost.Migrations)
+	}
+	if cost.Migrations[0].Estimate.Note != "rewrites users" {
+		t.Errorf("first estimate = %+v, want its note kept", cost.Migrations[0].Estimate)
+	}
+}
+
+func TestEstimateWrapsErrors(t *testing.T) {
+	boom := errors.New("collection scan timed out")
+	m := &estimatedMigration{TestMigration: TestMigration{version: "20240101_001"}, err: boom}
+	engine := NewEngine(nil, "", map[string]Migration{m.version: m})
+
+	_, err := engine.estimate(context.Background(), []string{m.version})
+	if !errors.Is(err, ErrFailedToEstimate) || !errors.Is(err, boom) {
+		t.Errorf("estimate() error = %v, want ErrFailedToEstimate wrapping the cause", err)
+	}
+}
@@ -0,0 +1,74 @@
+package migration
+
+import "context"
+
+// PlanReason says why ExplainPlan did or did not select a migration.
+type PlanReason string
+
+const (
+	ReasonApplied      PlanReason = "already applied"
+	ReasonPending      PlanReason = "pending"
+	ReasonPastTarget   PlanReason = "past target"
+	ReasonBeforeTarget PlanReason = "not yet at target"
+	ReasonOutOfOrder   PlanReason = "out of order"
+)
+
+// PlanEntry is the decision ExplainPlan made for one registered migration.
+type PlanEntry struct {
+	Version     string     `json:"version"`
+	Description string     `json:"description"`
+	Run         bool       `json:"run"`
+	Reason      PlanReason `json:"reason"`
+}
+
+// ExplainPlan returns an entry for every registered migration, in execution order, saying
+// whether Plan would select it and why. Entries with Run set are exactly Plan's result.
+func (e *Engine) ExplainPlan(ctx context.Context, dir Direction, target string) ([]PlanEntry, error) {
+	applied, err := e.getAppliedMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return e.explain(dir, target, applied), nil
+}
+
+func (e *Engine) explain(dir Direction, target string, applied map[string]MigrationRecord) []PlanEntry {
+	versions := e.getSortedVersions(dir)
+
+	// A pending migration older than the newest applied one runs out of order on Up.
+	var newestApplied string
+	for _, v := range versions {
+		if _, ok := applied[v]; ok && v > newestApplied {
+			newestApplied = v
+		}
+	}
+
+	entries := make([]PlanEntry, 0, len(versions))
+	pastTarget := false
+	for _, v := range versions {
+		entry := PlanEntry{Version: v, Description: e.migrations[v].Description()}
+		_, isApplied := applied[v]
+
+		switch {
+		case pastTarget:
+			entry.Reason = ReasonPastTarget
+		case dir == DirectionUp && isApplied:
+			entry.Reason = ReasonApplied
+		case dir == DirectionUp && v < newestApplied:
+			entry.Run, entry.Reason = true, ReasonOutOfOrder
+		case dir == DirectionUp:
+			entry.Run, entry.Reason = true, ReasonPending
+		case !isApplied:
+			entry.Reason = ReasonPending
+		case target != "":
+			entry.Run, entry.Reason = true, ReasonBeforeTarget
+		default:
+			entry.Run, entry.Reason = true, ReasonApplied
+		}
+		entries = append(entries, entry)
+
+		if target != "" && v == target {
+			pastTarget = true
+		}
+	}
+	return entries
+}
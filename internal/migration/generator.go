@@ -3,9 +3,14 @@ package migration
 import (
 	"bytes"
 	_ "embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"text/template"
 	"time"
@@ -14,19 +19,53 @@ import (
 //go:embed template.tmpl
 var migrationTemplate string
 
+//go:embed test_template.tmpl
+var migrationTestTemplate string
+
+// Version stamp formats for Generator.VersionFormat.
+const (
+	VersionFormatTimestamp = "timestamp"
+	VersionFormatSequence  = "sequence"
+	VersionFormatSemver    = "semver"
+)
+
+var semverPattern = regexp.MustCompile(`^v?\d+\.\d+\.\d+$`)
+
 type Generator struct {
 	OutputPath string
+	// VersionFormat selects how the version stamp is generated. Empty means timestamp.
+	VersionFormat string
+	// Version is the explicit stamp required by the semver format, e.g. v1.4.0.
+	Version string
+	// Existing lists versions that are registered but may not have a file in OutputPath.
+	// They take part in sequence numbering and the uniqueness check.
+	Existing []string
+	// WithTest also writes a <version>_test.go skeleton that runs Up and Down.
+	WithTest bool
 }
 
 func (g *Generator) Create(name string) (string, string, error) {
-	timestamp := time.Now().Format("20060102_150405")
+	existing, err := g.existingVersions()
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrFailedToCreateFile, err)
+	}
+
+	stamp, err := g.stamp(existing)
+	if err != nil {
+		return "", "", err
+	}
+	for _, v := range existing {
+		if v == stamp || strings.HasPrefix(v, stamp+"_") {
+			return "", "", fmt.Errorf("%w: %s is already used by %s", ErrVersionExists, stamp, v)
+		}
+	}
 
 	cleanName := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(name))
-	version := fmt.Sprintf("%s_%s", timestamp, cleanName)
+	version := fmt.Sprintf("%s_%s", stamp, cleanName)
 	targetPath := filepath.Join(g.OutputPath, version+".go")
 
 	if err := os.MkdirAll(g.OutputPath, 0750); err != nil {
-		return "", "", fmt.Errorf("%s: %w", ErrFailedToCreateFile, err)
+		return "", "", fmt.Errorf("%w: %w", ErrFailedToCreateFile, err)
 	}
 
 	data := struct {
@@ -38,18 +77,89 @@ func (g *Generator) Create(name string) (string, string, error) {
 		PackageName: filepath.Base(g.OutputPath),
 		Version:     version,
 		Description: name,
-		StructName:  "Migration_" + version,
+		StructName:  "Migration_" + strings.ReplaceAll(version, ".", "_"),
 	}
 
-	tmpl, err := template.New("migration").Parse(migrationTemplate)
+	if err := writeTemplate(targetPath, migrationTemplate, data); err != nil {
+		return "", "", err
+	}
+	if g.WithTest {
+		testPath := filepath.Join(g.OutputPath, version+"_test.go")
+		if err := writeTemplate(testPath, migrationTestTemplate, data); err != nil {
+			return "", "", err
+		}
+	}
+	return targetPath, version, nil
+}
+
+func writeTemplate(path, text string, data any) error {
+	tmpl, err := template.New(filepath.Base(path)).Parse(text)
 	if err != nil {
-		return "", "", fmt.Errorf("%s: %w", ErrFailedToParseTemplate, err)
+		return fmt.Errorf("%w: %w", ErrFailedToParseTemplate, err)
 	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", "", fmt.Errorf("%s: %w", ErrFailedToExecuteTemplate, err)
+		return fmt.Errorf("%w: %w", ErrFailedToExecuteTemplate, err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// stamp returns the leading part of the version that orders migrations. Versions are
+// sorted as strings, so sequence numbers are zero-padded; semver stamps only sort
+// correctly while every component stays below 10.
+func (g *Generator) stamp(existing []string) (string, error) {
+	switch strings.ToLower(g.VersionFormat) {
+	case "", VersionFormatTimestamp:
+		return time.Now().Format("20060102_150405"), nil
+	case VersionFormatSequence:
+		return nextSequence(existing), nil
+	case VersionFormatSemver:
+		if g.Version == "" {
+			return "", fmt.Errorf("%w: the semver format needs an explicit version such as v1.4.0", ErrInvalidMigrationVersion)
+		}
+		if !semverPattern.MatchString(g.Version) {
+			return "", fmt.Errorf("%w: %q is not MAJOR.MINOR.PATCH", ErrInvalidMigrationVersion, g.Version)
+		}
+		return g.Version, nil
+	default:
+		return "", fmt.Errorf("%w: unknown version format %q (use timestamp, sequence or semver)",
+			ErrInvalidMigrationVersion, g.VersionFormat)
+	}
+}
+
+// existingVersions combines Existing with the versions of migration files in OutputPath.
+func (g *Generator) existingVersions() ([]string, error) {
+	versions := slices.Clone(g.Existing)
+	entries, err := os.ReadDir(g.OutputPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return versions, nil
+	}
+	if err != nil {
+		return nil, err
 	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		versions = append(versions, strings.TrimSuffix(name, ".go"))
+	}
+	return versions, nil
+}
 
-	return targetPath, version, os.WriteFile(targetPath, buf.Bytes(), 0600)
+// nextSequence returns one more than the highest numeric stamp in versions, padded to at
+// least four digits.
+func nextSequence(versions []string) string {
+	highest, width := 0, 4
+	for _, v := range versions {
+		stamp, _, _ := strings.Cut(v, "_")
+		n, err := strconv.Atoi(stamp)
+		if err != nil {
+			continue
+		}
+		highest = max(highest, n)
+		width = max(width, len(stamp))
+	}
+	return fmt.Sprintf("%0*d", width, highest+1)
 }
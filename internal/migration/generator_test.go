@@ -0,0 +1,177 @@
+package migration
+
+import (
+	"errors"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGeneratorVersionFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		gen     Generator
+		files   []string
+		want    *regexp.Regexp
+		wantErr error
+	}{
+		{
+			name: "Timestamp by default",
+			want: regexp.MustCompile(`^\d{8}_\d{6}_add_users$`),
+		},
+		{
+			name:  "Sequence starts at one",
+			gen:   Generator{VersionFormat: VersionFormatSequence},
+			files: []string{"helpers.go"},
+			want:  regexp.MustCompile(`^0001_add_users$`),
+		},
+		{
+			name:  "Sequence continues after files and registered versions",
+			gen:   Generator{VersionFormat: VersionFormatSequence, Existing: []string{"0007_registered"}},
+			files: []string{"0002_first.go", "0005_second.go"},
+			want:  regexp.MustCompile(`^0008_add_users$`),
+		},
+		{
+			name: "Semver uses the explicit version",
+			gen:  Generator{VersionFormat: VersionFormatSemver, Version: "v1.4.0"},
+			want: regexp.MustCompile(`^v1\.4\.0_add_users$`),
+		},
+		{
+			name:    "Semver requires a version",
+			gen:     Generator{VersionFormat: VersionFormatSemver},
+			wantErr: ErrInvalidMigrationVersion,
+		},
+		{
+			name:    "Semver rejects malformed versions",
+			gen:     Generator{VersionFormat: VersionFormatSemver, Version: "1.4"},
+			wantErr: ErrInvalidMigrationVersion,
+		},
+		{
+			name:    "Semver must be unique",
+			gen:     Generator{VersionFormat: VersionFormatSemver, Version: "v1.4.0"},
+			files:   []string{"v1.4.0_other.go"},
+			wantErr: ErrVersionExists,
+		},
+		{
+			name:    "Unknown format",
+			gen:     Generator{VersionFormat: "uuid"},
+			wantErr: ErrInvalidMigrationVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "migrations")
+			if err := os.MkdirAll(dir, 0750); err != nil {
+				t.Fatal(err)
+			}
+			for _, f := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, f), []byte("package migrations\n"), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			gen := tt.gen
+			gen.OutputPath = dir
+			path, version, err := gen.Create("add users")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Create() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if !tt.want.MatchString(version) {
+				t.Errorf("version = %q, want match for %s", version, tt.want)
+			}
+
+			src, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read generated file: %v", err)
+			}
+			structName := "Migration_" + strings.ReplaceAll(version, ".", "_")
+			if !strings.Contains(string(src), "type "+structName+" struct") {
+				t.Errorf("generated file does not declare %s", structName)
+			}
+		})
+	}
+}
+
+func TestGeneratorTemplateCompilesAgainstDriverV2(t *testing.T) {
+	g := Generator{OutputPath: filepath.Join(t.TempDir(), "migrations")}
+	path, _, err := g.Create("add users")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("generated file does not parse: %v", err)
+	}
+	for _, imp := range file.Imports {
+		p := strings.Trim(imp.Path.Value, `"`)
+		if strings.HasPrefix(p, "go.mongodb.org/mongo-driver/") && !strings.HasPrefix(p, "go.mongodb.org/mongo-driver/v2/") {
+			t.Errorf("generated file imports the v1 driver: %s", p)
+		}
+	}
+}
+
+func TestGeneratorWithTest(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "migrations")
+	g := Generator{OutputPath: dir, VersionFormat: VersionFormatSequence, WithTest: true}
+	path, version, err := g.Create("add users")
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	testPath := strings.TrimSuffix(path, ".go") + "_test.go"
+	data, err := os.ReadFile(testPath)
+	if err != nil {
+		t.Fatalf("companion test not created: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), testPath, data, 0); err != nil {
+		t.Fatalf("companion test does not parse: %v", err)
+	}
+	for _, want := range []string{
+		"package migrations",
+		"func TestMigration_" + version + "(t *testing.T)",
+		`t.Run("Up"`,
+		`t.Run("Down"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("companion test is missing %q", want)
+		}
+	}
+
+	next, _, err := g.Create("add orders")
+	if err != nil {
+		t.Fatalf("second Create() error = %v", err)
+	}
+	if filepath.Base(next) != "0002_add_orders.go" {
+		t.Errorf("second migration = %s, want the test file to be ignored when numbering", filepath.Base(next))
+	}
+}
+
+func TestGeneratorWrapsCreateErrors(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "migrations")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	g := &Generator{OutputPath: filepath.Join(file, "nested")}
+	_, _, err := g.Create("add users")
+	if !errors.Is(err, ErrFailedToCreateFile) {
+		t.Fatalf("Create() error = %v, want %v", err, ErrFailedToCreateFile)
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) {
+		t.Errorf("Create() error = %v, want the underlying filesystem error kept", err)
+	}
+}
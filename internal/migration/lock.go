@@ -0,0 +1,229 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+const (
+	fenceLockID            = defaultLockID + "_fence"
+	lockTTLSeconds         = 600
+	defaultLockHeartbeat   = time.Minute
+	heartbeatUpdateTimeout = 10 * time.Second
+)
+
+// lockLease identifies one acquisition of the engine lock by its fencing token. Tokens
+// increase on every acquisition, so a holder whose lock expired or was force-unlocked and
+// then taken by another process can tell that the lock document is no longer its own.
+type lockLease struct {
+	fence    int64
+	acquired time.Time
+}
+
+type leaseKey struct{}
+
+func withLease(ctx context.Context, lease *lockLease) context.Context {
+	return context.WithValue(ctx, leaseKey{}, lease)
+}
+
+// nextFence atomically increments the fencing counter kept next to the lock document.
+// The counter has no acquired_at field, so the lock's TTL index never removes it.
+func (e *Engine) nextFence(ctx context.Context) (int64, error) {
+	var counter struct {
+		Fence int64 `bson:"fence"`
+	}
+	err := e.db.Collection(collLock).FindOneAndUpdate(ctx,
+		bson.M{"lock_id": fenceLockID},
+		bson.M{"$inc": bson.M{"fence": int64(1)}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	return counter.Fence, err
+}
+
+// acquireLock is the first step of every operation that writes migration records, so it
+// is also where a read-only engine refuses them.
+//
+// Safety contract: at most one caller holds the lock at a time, across processes, because
+// the lock is a single document whose lock_id is unique. Concurrent callers race on the
+// insert and all but one get ErrLockHeld; none of them waits. The unique index is
+// created before any lock write and a failure to create it aborts, since without it every
+// racing insert would succeed. A holder that crashed keeps the lock until the TTL index
+// removes it or WithStaleLockTimeout clears it, and the fencing token lets a holder
+// detect that its lock was taken over in the meantime.
+func (e *Engine) acquireLock(ctx context.Context) (*lockLease, error) {
+	if e.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := e.checkDatabase(); err != nil {
+		return nil, err
+	}
+	coll := e.db.Collection(collLock)
+	if err := ensureLockIndexes(ctx, coll); err != nil {
+		return nil, err
+	}
+
+	fence, err := e.nextFence(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToLock, err)
+	}
+
+	lease := &lockLease{fence: fence, acquired: time.Now().UTC().Truncate(time.Millisecond)}
+	insert := func() error {
+		now := time.Now().UTC()
+		_, err := coll.InsertOne(ctx, bson.M{
+			"lock_id":     defaultLockID,
+			"fence":       lease.fence,
+			"acquired_at": now,
+			"heartbeat":   now,
+		})
+		return err
+	}
+
+	err = insert()
+	if mongo.IsDuplicateKeyError(err) && e.clearStaleLock(ctx) {
+		err = insert()
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToLock, ErrLockHeld)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return lease, nil
+}
+
+// ensureLockIndexes creates the unique lock_id index, which the lock depends on, and the
+// TTL index, which only speeds up recovery from a crashed holder. They are created one at
+// a time so a conflicting TTL index left by an older version cannot keep the unique one
+// from being built.
+func ensureLockIndexes(ctx context.Context, coll *mongo.Collection) error {
+	unique := mongo.IndexModel{Keys: bson.D{{Key: "lock_id", Value: 1}}, Options: options.Index().SetUnique(true)}
+	if _, err := coll.Indexes().CreateOne(ctx, unique); err != nil {
+		return fmt.Errorf("%w: cannot create the unique lock index: %w", ErrFailedToLock, err)
+	}
+	ttl := mongo.IndexModel{
+		Keys:    bson.D{{Key: "acquired_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(lockTTLSeconds),
+	}
+	if _, err := coll.Indexes().CreateOne(ctx, ttl); err != nil {
+		slog.Warn("Could not create the migration lock TTL index; crashed locks will not expire",
+			"error", err)
+	}
+	return nil
+}
+
+// clearStaleLock deletes the lock when it was last acquired or renewed longer ago than
+// the WithStaleLockTimeout threshold, and reports whether it did. A crashed holder's lock
+// otherwise lingers until the TTL monitor gets to it, which can take well over a minute.
+func (e *Engine) clearStaleLock(ctx context.Context) bool {
+	if e.staleLockAfter <= 0 {
+		return false
+	}
+	cutoff := time.Now().UTC().Add(-e.staleLockAfter)
+	res, err := e.db.Collection(collLock).DeleteOne(ctx, bson.M{
+		"lock_id":     defaultLockID,
+		"acquired_at": bson.M{"$lt": cutoff},
+	})
+	if err != nil || res.DeletedCount == 0 {
+		return false
+	}
+	slog.Warn("cleared stale migration lock", "older_than", e.staleLockAfter)
+	return true
+}
+
+// checkDatabase guards against a run aimed at the wrong database, e.g. through a stale
+// environment variable. It runs before the lock is taken, so nothing is written.
+func (e *Engine) checkDatabase() error {
+	if e.expectedDatabase == "" || e.db.Name() == e.expectedDatabase {
+		return nil
+	}
+	return fmt.Errorf("%w: expected %q, connected to %q", ErrWrongDatabase, e.expectedDatabase, e.db.Name())
+}
+
+// releaseLock removes the lock only if it is still held by lease, so a stale holder
+// never deletes a lock that another process has since acquired.
+func (e *Engine) releaseLock(ctx context.Context, lease *lockLease) {
+	_, _ = e.db.Collection(collLock).DeleteOne(ctx, bson.M{"lock_id": defaultLockID, "fence": lease.fence})
+}
+
+// renewLock pushes acquired_at forward so the TTL index does not expire a lock that is
+// still in use. It returns ErrLockLost when the lock document no longer belongs to lease.
+func (e *Engine) renewLock(ctx context.Context, lease *lockLease) error {
+	now := time.Now().UTC()
+	res, err := e.db.Collection(collLock).UpdateOne(ctx,
+		bson.M{"lock_id": defaultLockID, "fence": lease.fence},
+		bson.M{"$set": bson.M{"acquired_at": now, "heartbeat": now}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// checkFence verifies that the lease attached to ctx still holds the lock. The engine
+// calls it right before writing a migration record so that a holder that was paused past
+// its TTL and lost the lock aborts instead of writing. Without a lease it does nothing.
+func (e *Engine) checkFence(ctx context.Context) error {
+	lease, ok := ctx.Value(leaseKey{}).(*lockLease)
+	if !ok {
+		return nil
+	}
+	n, err := e.db.Collection(collLock).CountDocuments(ctx, bson.M{"lock_id": defaultLockID, "fence": lease.fence})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// startHeartbeat renews the lock every heartbeat interval until stop is called. If the
+// lock turns out to be lost, the returned context is cancelled with ErrLockLost as cause
+// so the running migrations stop instead of writing without holding the lock.
+func (e *Engine) startHeartbeat(ctx context.Context, lease *lockLease) (context.Context, func()) {
+	if e.lockHeartbeat <= 0 {
+		return ctx, func() {}
+	}
+
+	hbCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(e.lockHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-hbCtx.Done():
+				return
+			case <-ticker.C:
+				uCtx, uCancel := context.WithTimeout(context.WithoutCancel(hbCtx), heartbeatUpdateTimeout)
+				err := e.renewLock(uCtx, lease)
+				uCancel()
+				switch {
+				case errors.Is(err, ErrLockLost):
+					slog.Error("Migration lock was lost, aborting run", "lock_id", defaultLockID)
+					cancel(ErrLockLost)
+					return
+				case err != nil:
+					slog.Warn("Failed to renew migration lock", "error", err)
+				}
+			}
+		}
+	}()
+
+	return hbCtx, func() {
+		cancel(nil)
+		<-done
+	}
+}
@@ -0,0 +1,250 @@
+package migration
+
+import (
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/v2/mongo/writeconcern"
+)
+
+type EngineOption func(*Engine)
+
+// WithAllowRunOne enables Engine.RunOne. Re-running a migration outside of the normal
+// plan can leave the database out of sync with the recorded history, so it is off by default.
+func WithAllowRunOne(allow bool) EngineOption {
+	return func(e *Engine) {
+		e.allowRunOne = allow
+	}
+}
+
+// WithCausalConsistency runs all migrations of a run in one causally consistent session
+// with majority read and write concern, so later migrations read the writes of earlier ones.
+func WithCausalConsistency(enabled bool) EngineOption {
+	return func(e *Engine) {
+		e.causalConsistency = enabled
+	}
+}
+
+// WithMaxParallel sets how many Independent migrations may run at once. The default of 1
+// keeps execution strictly sequential.
+func WithMaxParallel(n int) EngineOption {
+	return func(e *Engine) {
+		if n < 1 {
+			n = 1
+		}
+		e.maxParallel = n
+	}
+}
+
+// WithBulkConcurrency sets how many BulkWriter batches may be in flight at once across
+// every migration of a run. The default is 4.
+func WithBulkConcurrency(n int) EngineOption {
+	return func(e *Engine) {
+		if n < 1 {
+			n = 1
+		}
+		e.bulkSem = make(chan struct{}, n)
+	}
+}
+
+// WithReadOnly makes the engine refuse every operation that writes, including taking the
+// lock, so it can be used with a user that only has read permissions. Mutating methods
+// return ErrReadOnly before touching the database.
+func WithReadOnly(readOnly bool) EngineOption {
+	return func(e *Engine) {
+		e.readOnly = readOnly
+	}
+}
+
+// WithExpectedDatabase makes every operation that takes the lock fail with
+// ErrWrongDatabase unless the engine's database has this name. Empty disables the check.
+func WithExpectedDatabase(name string) EngineOption {
+	return func(e *Engine) {
+		e.expectedDatabase = name
+	}
+}
+
+// WithEnvironment names the deployment environment the engine runs in. Up skips
+// migrations implementing EnvironmentScoped that do not list it, without writing a record,
+// so they stay pending; the skip is audited. With no environment set, every scoped
+// migration is skipped.
+func WithEnvironment(env string) EngineOption {
+	return func(e *Engine) {
+		e.environment = env
+	}
+}
+
+// WithAuditCollection appends an AuditEntry to the named collection for every migration
+// the engine executes, including failed ones. Audit writes are best-effort and happen
+// outside the migration's transaction. Empty disables auditing.
+func WithAuditCollection(name string) EngineOption {
+	return func(e *Engine) {
+		e.auditColl = name
+	}
+}
+
+// WithForbidDrops makes SafeDatabase refuse to drop databases and collections during Up.
+// Up then refuses a plan with any migration that does not implement SafeMigration; see
+// SafeDatabase for what the proxy can and cannot intercept.
+func WithForbidDrops(forbid bool) EngineOption {
+	return func(e *Engine) {
+		e.forbidDrops = forbid
+	}
+}
+
+// WithDisableTransactions runs every migration and its record write without a
+// transaction, skipping the attempt that deployments without transaction support only
+// fail and fall back from. Without a transaction, a migration that fails after some of
+// its writes leaves them in place and the record is written separately, so a crash in
+// between can leave changes without a record.
+func WithDisableTransactions(disable bool) EngineOption {
+	return func(e *Engine) {
+		e.disableTransactions = disable
+	}
+}
+
+// WithRegistry encodes and decodes migration records with reg instead of the registry of
+// the database, e.g. to store custom types or decimals in a particular way. Only the
+// migrations collection uses it; the database handed to migrations is unaffected.
+func WithRegistry(reg *bson.Registry) EngineOption {
+	return func(e *Engine) {
+		e.registry = reg
+	}
+}
+
+// WithStrictChecksums rejects applied records whose checksum predates the body hash that
+// checksum-gen registered for their migration, instead of accepting them with a warning.
+// Such a record only covers the version and description, so the code it was applied with
+// cannot be verified. Run admin compact once the migrations are reviewed to rewrite them.
+func WithStrictChecksums(strict bool) EngineOption {
+	return func(e *Engine) {
+		e.strictChecksums = strict
+	}
+}
+
+// WithAutoRepairDescriptions lets Up rewrite the stored description and checksum of an
+// applied migration whose description is the only thing that changed, instead of failing
+// with ErrChecksumMismatch.
+func WithAutoRepairDescriptions(enabled bool) EngineOption {
+	return func(e *Engine) {
+		e.repairDescriptions = enabled
+	}
+}
+
+// WithRecordWriteConcern sets the write concern for inserting and deleting migration
+// records, independent of what the migrations themselves use. The default is majority so
+// the history survives a failover; nil falls back to the client's write concern.
+func WithRecordWriteConcern(wc *writeconcern.WriteConcern) EngineOption {
+	return func(e *Engine) {
+		e.recordWrite = wc
+	}
+}
+
+// WithRecordReadConcern sets the read concern for reading migration records. By default
+// the client's read concern applies.
+func WithRecordReadConcern(rc *readconcern.ReadConcern) EngineOption {
+	return func(e *Engine) {
+		e.recordRead = rc
+	}
+}
+
+// WithLockHeartbeat sets how often a running Up or Down renews its lock so the lock's TTL
+// does not expire during long migrations. Zero or a negative interval disables renewal.
+func WithLockHeartbeat(interval time.Duration) EngineOption {
+	return func(e *Engine) {
+		e.lockHeartbeat = interval
+	}
+}
+
+// WithRequireReversible refuses to apply migrations that implement Irreversible returning
+// true or Reversible with HasDown returning false. The check covers the whole plan before
+// anything is applied. Migrations implementing neither are assumed reversible.
+func WithRequireReversible(enabled bool) EngineOption {
+	return func(e *Engine) {
+		e.requireReversible = enabled
+	}
+}
+
+// WithFailOnOrphaned refuses to run Up or Down while the migrations collection holds a
+// record for a version that is no longer registered, so a deleted migration file is noticed
+// before anything else changes.
+func WithFailOnOrphaned(enabled bool) EngineOption {
+	return func(e *Engine) {
+		e.failOnOrphaned = enabled
+	}
+}
+
+// WithFloorVersion keeps version and everything older applied: Down and DownWithResult
+// stop above it, and a target, selected version or RunOne at or below it is refused
+// with ErrBelowFloor. Empty disables the floor.
+func WithFloorVersion(version string) EngineOption {
+	return func(e *Engine) {
+		e.floor = version
+	}
+}
+
+// WithStaleLockTimeout makes lock acquisition delete an existing lock whose acquired_at
+// is older than d and retry once, instead of failing until the TTL index removes it. It
+// is meant for ephemeral CI where a crashed job may leave a lock behind. Zero disables it.
+func WithStaleLockTimeout(d time.Duration) EngineOption {
+	return func(e *Engine) {
+		e.staleLockAfter = d
+	}
+}
+
+// WithVersionComparator sets the order in which migrations run and in which the CLI
+// filters versions. The default is CompareLexical.
+func WithVersionComparator(compare VersionComparator) EngineOption {
+	return func(e *Engine) {
+		if compare != nil {
+			e.compare = compare
+		}
+	}
+}
+
+// WithPreflight runs checks before every up run, after the lock is taken and before the
+// first migration. Any failing check aborts the run with ErrPreflightFailed.
+func WithPreflight(checks ...PreflightCheck) EngineOption {
+	return func(e *Engine) {
+		e.preflight = append(e.preflight, checks...)
+	}
+}
+
+// WithProgress writes a start and finish line per migration to w. Lines are serialized,
+// so output stays readable when migrations run in parallel. Migrations that report through
+// ProgressFromContext also get a progress bar.
+func WithProgress(w io.Writer) EngineOption {
+	return func(e *Engine) {
+		if w != nil {
+			e.progress = &progressWriter{w: w}
+		}
+	}
+}
+
+// WithProgressCallback calls fn with every update a migration reports through
+// ProgressFromContext. fn may be called concurrently when migrations run in parallel.
+func WithProgressCallback(fn func(ProgressUpdate)) EngineOption {
+	return func(e *Engine) {
+		e.onProgress = fn
+	}
+}
+
+// WithMaxOpsPerSec caps the write rate of DeleteInBatches and BulkWriter at n operations
+// per second, shared by every migration of a run. Migrations read the limiter
+// with RateLimiterFromContext to pace their own writes. Zero or less disables the cap.
+func WithMaxOpsPerSec(n int) EngineOption {
+	return func(e *Engine) {
+		e.rateLimiter = NewRateLimiter(n)
+	}
+}
+
+// WithTimings calls fn with how long each phase of an up or down run took: acquiring the
+// lock, reading the applied records, and every migration executed. fn may be called
+// concurrently when migrations run in parallel.
+func WithTimings(fn func(PhaseTiming)) EngineOption {
+	return func(e *Engine) {
+		e.onTiming = fn
+	}
+}
@@ -0,0 +1,29 @@
+package migration
+
+// OrderEntry is one migration in the order Up runs them. Migrations that share a Batch
+// may run concurrently; see WithMaxParallel and Independent.
+type OrderEntry struct {
+	Index       int    `json:"index"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Batch       int    `json:"batch"`
+}
+
+// ExecutionOrder lists every registered migration in the order Up would run them against
+// an empty database: sorted by the version comparator, then grouped into batches as a run
+// would group them. It does not touch the database. Migrations cannot declare
+// dependencies on each other, so nothing else reorders them.
+func (e *Engine) ExecutionOrder() []OrderEntry {
+	var entries []OrderEntry
+	for b, batch := range e.batches(e.sortedVersions()) {
+		for _, v := range batch {
+			entries = append(entries, OrderEntry{
+				Index:       len(entries) + 1,
+				Version:     v,
+				Description: e.migrations[v].Description(),
+				Batch:       b + 1,
+			})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,66 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PermissionProbePrefix names the temporary collections ProbePermissions creates, so a
+// leftover one is easy to recognize and drop.
+const PermissionProbePrefix = "mmt_permission_probe_"
+
+const probeCleanupTimeout = 10 * time.Second
+
+// PermissionCheck is the outcome of one operation tried by ProbePermissions.
+type PermissionCheck struct {
+	Operation string `json:"operation"`
+	Allowed   bool   `json:"allowed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ProbePermissions checks that the connected user can do what migrations usually need:
+// create a collection, build an index on it, insert and delete a document, and drop it.
+// Every step runs in a temporary collection, even after an earlier one failed, and the
+// collection is dropped at the end whether or not the run was cancelled.
+func (e *Engine) ProbePermissions(ctx context.Context) ([]PermissionCheck, error) {
+	if e.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := e.checkDatabase(); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s%d", PermissionProbePrefix, time.Now().UnixNano())
+	coll := e.db.Collection(name)
+	var checks []PermissionCheck
+	try := func(op string, fn func() error) {
+		check := PermissionCheck{Operation: op, Allowed: true}
+		if err := fn(); err != nil {
+			check.Allowed, check.Error = false, err.Error()
+		}
+		checks = append(checks, check)
+	}
+
+	try("create collection", func() error { return e.db.CreateCollection(ctx, name) })
+	try("create index", func() error {
+		_, err := coll.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "probe", Value: 1}}})
+		return err
+	})
+	try("insert", func() error {
+		_, err := coll.InsertOne(ctx, bson.M{"_id": name, "probe": 1})
+		return err
+	})
+	try("delete", func() error {
+		_, err := coll.DeleteOne(ctx, bson.M{"_id": name})
+		return err
+	})
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeCleanupTimeout)
+	defer cancel()
+	try("drop collection", func() error { return coll.Drop(cleanupCtx) })
+	return checks, nil
+}
@@ -0,0 +1,114 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// PreflightCheck asserts something about the database that must hold before any
+// migration is applied, such as a baseline collection or index being present.
+type PreflightCheck struct {
+	Name  string
+	Check func(ctx context.Context, db *mongo.Database) error
+}
+
+// CollectionExists checks that coll exists.
+func CollectionExists(coll string) PreflightCheck {
+	return PreflightCheck{
+		Name: "collection " + coll,
+		Check: func(ctx context.Context, db *mongo.Database) error {
+			names, err := db.ListCollectionNames(ctx, bson.D{{Key: "name", Value: coll}})
+			if err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				return fmt.Errorf("collection %q does not exist", coll)
+			}
+			return nil
+		},
+	}
+}
+
+// IndexExists checks that coll has an index named index.
+func IndexExists(coll, index string) PreflightCheck {
+	return PreflightCheck{
+		Name: "index " + coll + "." + index,
+		Check: func(ctx context.Context, db *mongo.Database) error {
+			specs, err := db.Collection(coll).Indexes().ListSpecifications(ctx)
+			if err != nil {
+				return err
+			}
+			if !slices.ContainsFunc(specs, func(s mongo.IndexSpecification) bool { return s.Name == index }) {
+				return fmt.Errorf("collection %q has no index %q", coll, index)
+			}
+			return nil
+		},
+	}
+}
+
+// CollectionNotEmpty checks that coll holds at least one document, e.g. that a baseline
+// data load happened.
+func CollectionNotEmpty(coll string) PreflightCheck {
+	return PreflightCheck{
+		Name: "documents in " + coll,
+		Check: func(ctx context.Context, db *mongo.Database) error {
+			err := db.Collection(coll).FindOne(ctx, bson.D{}).Err()
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				return fmt.Errorf("collection %q is empty", coll)
+			}
+			return err
+		},
+	}
+}
+
+// ParsePreflightChecks builds checks from config specs of the form collection:<name>,
+// index:<collection>.<index> and documents:<name>.
+func ParsePreflightChecks(specs []string) ([]PreflightCheck, error) {
+	checks := make([]PreflightCheck, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		kind, arg, _ := strings.Cut(spec, ":")
+		if arg == "" {
+			return nil, fmt.Errorf("%w: %q needs a target after the colon", ErrInvalidPreflight, spec)
+		}
+		switch kind {
+		case "collection":
+			checks = append(checks, CollectionExists(arg))
+		case "documents":
+			checks = append(checks, CollectionNotEmpty(arg))
+		case "index":
+			coll, index, ok := strings.Cut(arg, ".")
+			if !ok || coll == "" || index == "" {
+				return nil, fmt.Errorf("%w: %q must look like index:<collection>.<index>", ErrInvalidPreflight, spec)
+			}
+			checks = append(checks, IndexExists(coll, index))
+		default:
+			return nil, fmt.Errorf("%w: unknown kind %q in %q", ErrInvalidPreflight, kind, spec)
+		}
+	}
+	return checks, nil
+}
+
+// Preflight runs every check against the engine's database and reports all failures
+// together, so one run shows everything that is off about the starting state.
+func (e *Engine) Preflight(ctx context.Context, checks []PreflightCheck) error {
+	var errs []error
+	for _, c := range checks {
+		if err := c.Check(ctx, e.db); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrPreflightFailed, errors.Join(errs...))
+	}
+	return nil
+}
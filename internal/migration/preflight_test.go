@@ -0,0 +1,54 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestParsePreflightChecks(t *testing.T) {
+	checks, err := ParsePreflightChecks([]string{"collection:users", " index:users.email_1 ", "", "documents:plans"})
+	if err != nil {
+		t.Fatalf("ParsePreflightChecks() error = %v", err)
+	}
+	var names []string
+	for _, c := range checks {
+		names = append(names, c.Name)
+	}
+	if got, want := strings.Join(names, "|"), "collection users|index users.email_1|documents in plans"; got != want {
+		t.Errorf("checks = %q, want %q", got, want)
+	}
+
+	for _, spec := range []string{"collection", "collection:", "index:users", "index:.email_1", "view:users"} {
+		if _, err := ParsePreflightChecks([]string{spec}); !errors.Is(err, ErrInvalidPreflight) {
+			t.Errorf("ParsePreflightChecks(%q) error = %v, want ErrInvalidPreflight", spec, err)
+		}
+	}
+}
+
+func TestPreflightReportsEveryFailure(t *testing.T) {
+	pass := PreflightCheck{Name: "pass", Check: func(context.Context, *mongo.Database) error { return nil }}
+	fail := func(name string) PreflightCheck {
+		return PreflightCheck{Name: name, Check: func(context.Context, *mongo.Database) error {
+			return errors.New("missing")
+		}}
+	}
+	e := &Engine{}
+
+	if err := e.Preflight(context.Background(), []PreflightCheck{pass, pass}); err != nil {
+		t.Fatalf("passing checks returned %v", err)
+	}
+
+	err := e.Preflight(context.Background(), []PreflightCheck{fail("baseline"), pass, fail("users index")})
+	if !errors.Is(err, ErrPreflightFailed) {
+		t.Fatalf("error = %v, want ErrPreflightFailed", err)
+	}
+	for _, name := range []string{"baseline: missing", "users index: missing"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not mention %q", err, name)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+)
+
+// progressWriter serializes per-migration progress lines. A nil writer discards output.
+type progressWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (p *progressWriter) start(version string, dir Direction) {
+	p.printf("%s %s %s\n", ui.Start, dir, version)
+}
+
+func (p *progressWriter) finish(version string, dir Direction, elapsed time.Duration, err error) {
+	if err != nil {
+		p.printf("%s %s %s failed after %s: %v\n", ui.Failed, dir, version, elapsed.Round(time.Millisecond), err)
+		return
+	}
+	p.printf("%s %s %s (%s)\n", ui.Finished, dir, version, elapsed.Round(time.Millisecond))
+}
+
+func (p *progressWriter) printf(format string, args ...any) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fmt.Fprintf(p.w, format, args...)
+}
+
+func (p *progressWriter) bar(u ProgressUpdate) {
+	const width = 20
+	filled := int(u.Percent() / 100 * width)
+	p.printf("  %s %s [%s%s] %3.0f%% (%d/%d)\n", u.Direction, u.Version,
+		strings.Repeat("#", filled), strings.Repeat(".", width-filled), u.Percent(), u.Processed, u.Total)
+}
+
+// ProgressUpdate is one report from a ProgressReporter.
+type ProgressUpdate struct {
+	Version   string
+	Direction Direction
+	Processed int64
+	Total     int64
+}
+
+// Percent returns Processed as a percentage of Total, capped at 100, or 0 while the
+// total is unknown.
+func (u ProgressUpdate) Percent() float64 {
+	if u.Total <= 0 {
+		return 0
+	}
+	return min(float64(u.Processed)/float64(u.Total)*100, 100)
+}
+
+type progressReporterKey struct{}
+
+// ProgressReporter lets a long-running migration report how far it has got. The engine
+// places one in the context passed to Up and Down when progress output or a progress
+// callback is configured; ProgressFromContext returns nil otherwise, and every method is
+// a no-op on a nil reporter, so migrations can report unconditionally.
+type ProgressReporter struct {
+	mu         sync.Mutex
+	update     ProgressUpdate
+	lastDecile int64
+	writer     *progressWriter
+	notify     func(ProgressUpdate)
+}
+
+// ProgressFromContext returns the reporter for the running migration, or nil.
+func ProgressFromContext(ctx context.Context) *ProgressReporter {
+	p, _ := ctx.Value(progressReporterKey{}).(*ProgressReporter)
+	return p
+}
+
+// SetTotal sets the number of units the migration expects to process.
+func (p *ProgressReporter) SetTotal(total int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.update.Total = total
+	p.mu.Unlock()
+}
+
+// CountTotal seeds the total with the number of documents in coll matching filter.
+func (p *ProgressReporter) CountTotal(ctx context.Context, coll *mongo.Collection, filter any) error {
+	if p == nil {
+		return nil
+	}
+	if filter == nil {
+		filter = bson.D{}
+	}
+	n, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to count documents for progress: %w", err)
+	}
+	p.SetTotal(n)
+	return nil
+}
+
+// Add records n more processed units and reports the new position. The progress bar is
+// redrawn each time another tenth of the total is reached; callbacks see every call.
+func (p *ProgressReporter) Add(n int64) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.update.Processed += n
+	u := p.update
+	decile := int64(u.Percent() / 10)
+	redraw := u.Total > 0 && decile > p.lastDecile
+	if redraw {
+		p.lastDecile = decile
+	}
+	p.mu.Unlock()
+
+	if p.notify != nil {
+		p.notify(u)
+	}
+	if redraw {
+		p.writer.bar(u)
+	}
+}
+
+// withProgressReporter attaches a reporter for version to ctx when anything would
+// consume its updates.
+func (e *Engine) withProgressReporter(ctx context.Context, version string, dir Direction) context.Context {
+	if e.progress == nil && e.onProgress == nil {
+		return ctx
+	}
+	p := &ProgressReporter{
+		update: ProgressUpdate{Version: version, Direction: dir},
+		writer: e.progress,
+		notify: e.onProgress,
+	}
+	return context.WithValue(ctx, progressReporterKey{}, p)
+}
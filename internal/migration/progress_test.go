@@ -0,0 +1,117 @@
+package migration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+)
+
+func TestProgressWriterSerializesLines(t *testing.T) {
+	var buf bytes.Buffer
+	p := &progressWriter{w: &buf}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			version := fmt.Sprintf("20240101_%03d", i)
+			p.start(version, DirectionUp)
+			p.finish(version, DirectionUp, time.Millisecond, nil)
+		}()
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 100 {
+		t.Fatalf("expected 100 lines, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "▶ up 20240101_") && !strings.HasPrefix(line, "✔ up 20240101_") {
+			t.Errorf("interleaved or malformed line: %q", line)
+		}
+	}
+}
+
+func TestProgressWriterReportsFailure(t *testing.T) {
+	var buf bytes.Buffer
+	p := &progressWriter{w: &buf}
+	p.finish("20240101_001", DirectionDown, time.Second, errors.New("boom"))
+
+	if !strings.Contains(buf.String(), "✖ down 20240101_001") || !strings.Contains(buf.String(), "boom") {
+		t.Errorf("unexpected failure line: %q", buf.String())
+	}
+}
+
+func TestProgressWriterASCII(t *testing.T) {
+	ui.SetASCII(true)
+	t.Cleanup(func() { ui.SetASCII(false) })
+
+	var buf bytes.Buffer
+	p := &progressWriter{w: &buf}
+	p.start("20240101_001", DirectionUp)
+	p.finish("20240101_001", DirectionUp, time.Millisecond, nil)
+	p.finish("20240101_002", DirectionUp, time.Millisecond, errors.New("boom"))
+
+	want := "[RUN] up 20240101_001\n[OK] up 20240101_001 (1ms)\n[FAIL] up 20240101_002 failed after 1ms: boom\n"
+	if buf.String() != want {
+		t.Errorf("ASCII progress = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNilProgressWriterIsNoop(t *testing.T) {
+	var p *progressWriter
+	p.start("20240101_001", DirectionUp)
+	p.finish("20240101_001", DirectionUp, 0, nil)
+}
+
+func TestProgressReporterReportsIncreasingCounts(t *testing.T) {
+	var updates []ProgressUpdate
+	var buf bytes.Buffer
+	e := &Engine{progress: &progressWriter{w: &buf}, onProgress: func(u ProgressUpdate) {
+		updates = append(updates, u)
+	}}
+
+	ctx := e.withProgressReporter(context.Background(), "20240101_001", DirectionUp)
+	p := ProgressFromContext(ctx)
+	if p == nil {
+		t.Fatal("expected a reporter in the migration context")
+	}
+	p.SetTotal(100)
+	for i := 0; i < 4; i++ {
+		p.Add(25)
+	}
+
+	if len(updates) != 4 {
+		t.Fatalf("expected 4 updates, got %d", len(updates))
+	}
+	for i, u := range updates {
+		if want := int64(25 * (i + 1)); u.Processed != want || u.Total != 100 {
+			t.Errorf("update %d = %d/%d, want %d/100", i, u.Processed, u.Total, want)
+		}
+	}
+	if got := updates[len(updates)-1].Percent(); got != 100 {
+		t.Errorf("final percent = %v, want 100", got)
+	}
+	if !strings.Contains(buf.String(), "[####################] 100% (100/100)") {
+		t.Errorf("missing final progress bar:\n%s", buf.String())
+	}
+}
+
+func TestProgressReporterIsOptional(t *testing.T) {
+	e := &Engine{}
+	ctx := e.withProgressReporter(context.Background(), "20240101_001", DirectionUp)
+	p := ProgressFromContext(ctx)
+	if p != nil {
+		t.Fatal("expected no reporter without a progress consumer")
+	}
+	p.SetTotal(10)
+	p.Add(1)
+}
@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type rateLimiterKey struct{}
+
+// RateLimiter paces writes to at most a fixed number of operations per second, so a data
+// migration can run next to production traffic. Each Wait reserves the next slots in
+// turn, so callers sharing a limiter share its rate. A nil RateLimiter does not limit,
+// and every method is a no-op on it, so migrations can call Wait unconditionally. It is
+// safe for concurrent use.
+type RateLimiter struct {
+	opsPerSec int
+	interval  time.Duration
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// NewRateLimiter returns a limiter for opsPerSec operations per second, or nil when
+// opsPerSec is zero or less.
+func NewRateLimiter(opsPerSec int) *RateLimiter {
+	return newRateLimiter(opsPerSec, time.Now, sleepContext)
+}
+
+func newRateLimiter(
+	opsPerSec int, now func() time.Time, sleep func(ctx context.Context, d time.Duration) error,
+) *RateLimiter {
+	if opsPerSec <= 0 {
+		return nil
+	}
+	return &RateLimiter{opsPerSec: opsPerSec, interval: time.Second / time.Duration(opsPerSec), now: now, sleep: sleep}
+}
+
+// OpsPerSec returns the configured rate, or 0 for a nil limiter.
+func (l *RateLimiter) OpsPerSec() int {
+	if l == nil {
+		return 0
+	}
+	return l.opsPerSec
+}
+
+// Wait blocks until n operations may start without exceeding the rate, or until ctx is
+// done. The first call returns at once; each call then delays the next by n operations'
+// worth of time.
+func (l *RateLimiter) Wait(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := l.now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	start := l.next
+	l.next = start.Add(time.Duration(n) * l.interval)
+	l.mu.Unlock()
+
+	if d := start.Sub(now); d > 0 {
+		return l.sleep(ctx, d)
+	}
+	return ctx.Err()
+}
+
+// RateLimiterFromContext returns the limiter the engine placed in the context passed to
+// Up and Down when a rate is configured (see WithMaxOpsPerSec), or nil otherwise.
+// DeleteInBatches and BulkWriter wait on it before every batch.
+func RateLimiterFromContext(ctx context.Context) *RateLimiter {
+	l, _ := ctx.Value(rateLimiterKey{}).(*RateLimiter)
+	return l
+}
+
+// ContextWithRateLimiter returns ctx carrying l for the batching helpers, e.g. to give
+// one collection its own limiter inside a migration.
+func ContextWithRateLimiter(ctx context.Context, l *RateLimiter) context.Context {
+	return context.WithValue(ctx, rateLimiterKey{}, l)
+}
+
+// withRateLimiter attaches the engine's limiter, shared by every migration of the run, to ctx.
+func (e *Engine) withRateLimiter(ctx context.Context) context.Context {
+	if e.rateLimiter == nil {
+		return ctx
+	}
+	return ContextWithRateLimiter(ctx, e.rateLimiter)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
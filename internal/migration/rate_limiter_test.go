@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock advances only when the limiter sleeps.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func TestRateLimiterPacesToTargetRate(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	start := clock.Now()
+	l := newRateLimiter(200, clock.Now, clock.Sleep)
+	ctx := context.Background()
+
+	// 1000 writes in batches of 50 at 200/s: the last batch may start once the first
+	// 950 have had their 4.75s.
+	for range 20 {
+		if err := l.Wait(ctx, 50); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+	}
+	if got, want := clock.Now().Sub(start), 4750*time.Millisecond; got != want {
+		t.Errorf("elapsed = %s, want %s", got, want)
+	}
+
+	// Idle time is not banked: after a pause the next batch starts at once, the one
+	// after waits for it.
+	clock.now = clock.now.Add(time.Minute)
+	before := clock.Now()
+	_ = l.Wait(ctx, 100)
+	_ = l.Wait(ctx, 1)
+	if got := clock.Now().Sub(before); got != 500*time.Millisecond {
+		t.Errorf("after a pause elapsed = %s, want 500ms", got)
+	}
+}
+
+func TestRateLimiterNilAndCancelled(t *testing.T) {
+	var l *RateLimiter
+	if NewRateLimiter(0) != nil || l.OpsPerSec() != 0 || l.Wait(context.Background(), 10) != nil {
+		t.Error("a zero rate should give a nil limiter that never waits")
+	}
+
+	clock := &fakeClock{}
+	l = newRateLimiter(10, clock.Now, clock.Sleep)
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = l.Wait(ctx, 10)
+	cancel()
+	if err := l.Wait(ctx, 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait on a cancelled context = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestBulkWriterWaitsOnRateLimiter(t *testing.T) {
+	clock := &fakeClock{}
+	e := NewEngine(nil, "", nil, WithMaxOpsPerSec(100))
+	e.rateLimiter = newRateLimiter(100, clock.Now, clock.Sleep)
+	ctx := e.withRateLimiter(context.Background())
+	if got := RateLimiterFromContext(ctx).OpsPerSec(); got != 100 {
+		t.Fatalf("RateLimiterFromContext().OpsPerSec() = %d, want 100", got)
+	}
+
+	f := &inFlightWriter{}
+	w := newBulkWriter(ctx, 10, f.write)
+	if err := w.Add(ctx, insertModels(50)...); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if err := w.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	// Five batches of 10 at 100/s: the fifth starts after 400ms.
+	if got := clock.Now().Sub(time.Time{}); got != 400*time.Millisecond {
+		t.Errorf("elapsed = %s, want 400ms", got)
+	}
+	if f.models.Load() != 50 {
+		t.Errorf("wrote %d models, want 50", f.models.Load())
+	}
+}
@@ -1,33 +1,46 @@
 package migration
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
 )
 
 var (
 	registryMu sync.RWMutex
 	registered = make(map[string]Migration)
+	// attempts keeps every migration passed to Register, including rejected ones, so
+	// CheckRegistry can report mistakes that callers ignoring Register's error never see.
+	attempts []Migration
 
-	versionPattern = regexp.MustCompile(`^\d{8}(?:_\d{3,6})?(?:_[a-z0-9_]+)?$`)
+	// versionPatterns are the recommended version shapes, one per Generator format.
+	versionPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\d{8}(?:_\d{3,6})?(?:_[a-z0-9_]+)?$`),
+		regexp.MustCompile(`^\d{4,}_[a-z0-9_]+$`),
+		regexp.MustCompile(`^v?\d+\.\d+\.\d+_[a-z0-9_]+$`),
+	}
 )
 
+// Register adds m to the global registry. Versions must be non-empty and unique; versions
+// that do not follow the recommended format are accepted and reported by CheckRegistry.
 func Register(m Migration) error {
 	if m == nil {
 		return fmt.Errorf("migration must not be nil")
 	}
 
-	version := m.Version()
-	if !isValidVersionFormat(version) {
-		return fmt.Errorf("invalid version format: %s (expected YYYYMMDD[_HHMMSS][_slug])", version)
-	}
-
 	registryMu.Lock()
 	defer registryMu.Unlock()
+	attempts = append(attempts, m)
 
+	version := m.Version()
+	if strings.TrimSpace(version) == "" {
+		return fmt.Errorf("%w: %T has an empty version", ErrInvalidMigrationVersion, m)
+	}
 	if _, exists := registered[version]; exists {
-		return fmt.Errorf("migration %s already registered", version)
+		return fmt.Errorf("%w: migration %s already registered", ErrVersionExists, version)
 	}
 
 	registered[version] = m
@@ -53,6 +66,23 @@ func RegisteredMigrations() map[string]Migration {
 	return copy
 }
 
+// CatalogEntry describes one migration for listings that do not need a database.
+type CatalogEntry struct {
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Checksum    string `json:"checksum"`
+}
+
+// Catalog lists ms sorted by version.
+func Catalog(ms map[string]Migration) []CatalogEntry {
+	entries := make([]CatalogEntry, 0, len(ms))
+	for v, m := range ms {
+		entries = append(entries, CatalogEntry{Version: v, Description: m.Description(), Checksum: Checksum(m)})
+	}
+	slices.SortFunc(entries, func(a, b CatalogEntry) int { return strings.Compare(a.Version, b.Version) })
+	return entries
+}
+
 type MigrationFilter func(version string, m Migration) bool
 
 func GetMigrations(filters ...MigrationFilter) map[string]Migration {
@@ -61,20 +91,104 @@ func GetMigrations(filters ...MigrationFilter) map[string]Migration {
 
 	results := make(map[string]Migration)
 	for v, m := range registered {
-		keep := true
-		for _, filter := range filters {
-			if !filter(v, m) {
-				keep = false
-				break
-			}
-		}
-		if keep {
+		if matchesFilters(v, m, filters) {
 			results[v] = m
 		}
 	}
 	return results
 }
 
+// TagFilter keeps migrations tagged with any of the given tags. With no tags it keeps everything.
+func TagFilter(tags ...string) MigrationFilter {
+	wanted := make(map[string]struct{}, len(tags))
+	for _, t := range tags {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			wanted[t] = struct{}{}
+		}
+	}
+	return func(_ string, m Migration) bool {
+		if len(wanted) == 0 {
+			return true
+		}
+		for _, t := range MigrationTags(m) {
+			if _, ok := wanted[strings.ToLower(t)]; ok {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// VersionFilter keeps exactly the given versions. With no versions it keeps nothing.
+func VersionFilter(versions ...string) MigrationFilter {
+	wanted := make(map[string]struct{}, len(versions))
+	for _, v := range versions {
+		wanted[strings.TrimSpace(v)] = struct{}{}
+	}
+	return func(version string, _ Migration) bool {
+		_, ok := wanted[version]
+		return ok
+	}
+}
+
+// MigrationTags returns the tags of m, or nil if it does not implement Tagged.
+func MigrationTags(m Migration) []string {
+	if t, ok := m.(Tagged); ok {
+		return t.Tags()
+	}
+	return nil
+}
+
+func matchesFilters(version string, m Migration, filters []MigrationFilter) bool {
+	for _, filter := range filters {
+		if filter != nil && !filter(version, m) {
+			return false
+		}
+	}
+	return true
+}
+
 func isValidVersionFormat(version string) bool {
-	return versionPattern.MatchString(version)
+	for _, p := range versionPatterns {
+		if p.MatchString(version) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRegistry runs CheckMigrations over every migration passed to Register so far.
+func CheckRegistry() (warnings []string, err error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return CheckMigrations(attempts)
+}
+
+// CheckMigrations reports registration mistakes in ms: empty and duplicated versions are
+// errors, versions outside the recommended formats are warnings.
+func CheckMigrations(ms []Migration) (warnings []string, err error) {
+	var errs []error
+	owners := make(map[string]string, len(ms))
+	for i, m := range ms {
+		if m == nil {
+			errs = append(errs, fmt.Errorf("%w: migration %d is nil", ErrInvalidMigrationVersion, i))
+			continue
+		}
+		version, owner := m.Version(), fmt.Sprintf("%T", m)
+		switch {
+		case strings.TrimSpace(version) == "":
+			errs = append(errs, fmt.Errorf("%w: %s has an empty version", ErrInvalidMigrationVersion, owner))
+			continue
+		case owners[version] != "":
+			errs = append(errs, fmt.Errorf("%w: %s is returned by both %s and %s",
+				ErrVersionExists, version, owners[version], owner))
+			continue
+		}
+		owners[version] = owner
+		if !isValidVersionFormat(version) {
+			warnings = append(warnings, fmt.Sprintf(
+				"%s (%s) does not follow YYYYMMDD_NNN_slug, NNNN_slug or vX.Y.Z_slug", version, owner))
+		}
+	}
+	return warnings, errors.Join(errs...)
 }
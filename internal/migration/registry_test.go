@@ -0,0 +1,101 @@
+package migration
+
+import (
+	"errors"
+	"testing"
+)
+
+type taggedMigration struct {
+	TestMigration
+	tags []string
+}
+
+func (m *taggedMigration) Tags() []string { return m.tags }
+
+func TestTagFilter(t *testing.T) {
+	data := &taggedMigration{TestMigration: TestMigration{version: "20240101_001"}, tags: []string{"data"}}
+	index := &taggedMigration{TestMigration: TestMigration{version: "20240102_001"}, tags: []string{"Index", "schema"}}
+	untagged := &TestMigration{version: "20240103_001"}
+
+	tests := []struct {
+		name string
+		tags []string
+		m    Migration
+		want bool
+	}{
+		{name: "Matching tag", tags: []string{"data"}, m: data, want: true},
+		{name: "Any tag matches", tags: []string{"data", "index"}, m: index, want: true},
+		{name: "Case insensitive", tags: []string{"INDEX"}, m: index, want: true},
+		{name: "No matching tag", tags: []string{"schema"}, m: data, want: false},
+		{name: "Untagged excluded when filtering", tags: []string{"data"}, m: untagged, want: false},
+		{name: "No tags keeps untagged", tags: nil, m: untagged, want: true},
+		{name: "Blank tags are ignored", tags: []string{" ", ""}, m: untagged, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TagFilter(tt.tags...)(tt.m.Version(), tt.m); got != tt.want {
+				t.Errorf("TagFilter(%v) = %v, want %v", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFilters(t *testing.T) {
+	m := &TestMigration{version: "20240101_001"}
+	keep := func(string, Migration) bool { return true }
+	drop := func(string, Migration) bool { return false }
+
+	if !matchesFilters(m.version, m, nil) {
+		t.Error("no filters should keep the migration")
+	}
+	if !matchesFilters(m.version, m, []MigrationFilter{keep, nil}) {
+		t.Error("nil filters should be ignored")
+	}
+	if matchesFilters(m.version, m, []MigrationFilter{keep, drop}) {
+		t.Error("all filters must match")
+	}
+}
+
+func TestVersionFilter(t *testing.T) {
+	filter := VersionFilter("001", " 003")
+	for version, want := range map[string]bool{"001": true, "002": false, "003": true} {
+		if got := filter(version, &TestMigration{version: version}); got != want {
+			t.Errorf("VersionFilter(001, 003)(%s) = %v, want %v", version, got, want)
+		}
+	}
+	if VersionFilter()("001", &TestMigration{version: "001"}) {
+		t.Error("VersionFilter() with no versions must keep nothing")
+	}
+}
+
+func TestCheckMigrations(t *testing.T) {
+	tests := []struct {
+		name         string
+		versions     []string
+		wantErr      error
+		wantWarnings int
+	}{
+		{name: "Clean", versions: []string{"20240101_001", "0002_add_index", "v1.2.0_backfill"}},
+		{name: "Empty version", versions: []string{"20240101_001", " "}, wantErr: ErrInvalidMigrationVersion},
+		{name: "Duplicate version", versions: []string{"20240101_001", "20240101_001"}, wantErr: ErrVersionExists},
+		{name: "Malformed version warns", versions: []string{"20240101_001", "add-users", "2024.1"}, wantWarnings: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ms := make([]Migration, 0, len(tt.versions))
+			for _, v := range tt.versions {
+				ms = append(ms, &TestMigration{version: v})
+			}
+
+			warnings, err := CheckMigrations(ms)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("CheckMigrations() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(warnings) != tt.wantWarnings {
+				t.Errorf("CheckMigrations() warnings = %q, want %d", warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
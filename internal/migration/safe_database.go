@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SafeMigration is an optional interface for migrations whose Up should run under the
+// engine's operation policy. When a migration implements it, the engine calls UpSafe
+// instead of Up. A plain Up gets the unguarded *mongo.Database, so WithForbidDrops only
+// covers migrations that implement this; the others run with a warning.
+type SafeMigration interface {
+	UpSafe(ctx context.Context, db *SafeDatabase) error
+}
+
+// SafeDatabase is a thin proxy around *mongo.Database that refuses operations forbidden by
+// the engine's policy, currently dropping databases and collections during Up.
+//
+// The policy only covers calls made through the proxy: Drop on the database and on
+// collections returned by Collection. Commands sent with RunCommand, the embedded
+// Database field and Client are not checked.
+type SafeDatabase struct {
+	*mongo.Database
+	forbidDrops bool
+	dir         Direction
+	version     string
+}
+
+// SafeCollection is the collection counterpart of SafeDatabase.
+type SafeCollection struct {
+	*mongo.Collection
+	db *SafeDatabase
+}
+
+func newSafeDatabase(db *mongo.Database, forbidDrops bool, dir Direction, version string) *SafeDatabase {
+	return &SafeDatabase{Database: db, forbidDrops: forbidDrops, dir: dir, version: version}
+}
+
+func (s *SafeDatabase) Collection(name string, opts ...options.Lister[options.CollectionOptions]) *SafeCollection {
+	return &SafeCollection{Collection: s.Database.Collection(name, opts...), db: s}
+}
+
+func (s *SafeDatabase) Drop(ctx context.Context) error {
+	if err := s.checkDrop("database " + s.Name()); err != nil {
+		return err
+	}
+	return s.Database.Drop(ctx)
+}
+
+func (c *SafeCollection) Drop(ctx context.Context, opts ...options.Lister[options.DropCollectionOptions]) error {
+	if err := c.db.checkDrop("collection " + c.Name()); err != nil {
+		return err
+	}
+	return c.Collection.Drop(ctx, opts...)
+}
+
+func (s *SafeDatabase) checkDrop(target string) error {
+	if s.forbidDrops && s.dir == DirectionUp {
+		return fmt.Errorf("%w: migration %s drops %s during up; move the drop to Down or disable "+
+			"MIGRATIONS_FORBID_DROPS", ErrForbiddenOperation, s.version, target)
+	}
+	return nil
+}
+
+// warnUnguarded names the migrations of plan that WithForbidDrops cannot guard because
+// they only implement Up. They still run: the policy refuses drops, not migrations.
+func (e *Engine) warnUnguarded(plan []string) {
+	if !e.forbidDrops {
+		return
+	}
+	for _, v := range plan {
+		if _, ok := e.migrations[v].(SafeMigration); !ok {
+			slog.Warn("Migration only implements Up, so drops are not checked for it; implement UpSafe to guard it",
+				"version", v)
+		}
+	}
+}
+
+// up runs the Up step of m, through SafeDatabase when m supports it.
+func (e *Engine) up(ctx context.Context, m Migration) error {
+	if sm, ok := m.(SafeMigration); ok {
+		return sm.UpSafe(ctx, newSafeDatabase(e.db, e.forbidDrops, DirectionUp, m.Version()))
+	}
+	return m.Up(ctx, e.db)
+}
@@ -0,0 +1,92 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SelfTestSuffix is appended to the migrations collection name for the collection SelfTest
+// writes to, so the real migration records are never touched.
+const SelfTestSuffix = "_selftest"
+
+const selfTestVersion = "00000000_000_selftest"
+
+// selfTestMigration changes nothing; only its record is written and removed.
+type selfTestMigration struct{}
+
+func (selfTestMigration) Version() string                                 { return selfTestVersion }
+func (selfTestMigration) Description() string                             { return "built-in self-test" }
+func (selfTestMigration) Up(_ context.Context, _ *mongo.Database) error   { return nil }
+func (selfTestMigration) Down(_ context.Context, _ *mongo.Database) error { return nil }
+
+// SelfTestStep is the outcome of one step run by SelfTest.
+type SelfTestStep struct {
+	Step   string `json:"step"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// SelfTest runs the engine end to end without a user migration. A separate engine with a
+// single no-op migration applies it and rolls it back against the migrations collection
+// name plus SelfTestSuffix, checking the record appears and disappears. Steps stop at the
+// first failure, and the temporary collection is dropped at the end either way. The run
+// takes the shared migration lock like any other.
+func (e *Engine) SelfTest(ctx context.Context) ([]SelfTestStep, error) {
+	if e.readOnly {
+		return nil, ErrReadOnly
+	}
+	if err := e.checkDatabase(); err != nil {
+		return nil, err
+	}
+
+	name := e.coll + SelfTestSuffix
+	probe := NewEngine(e.db, name, map[string]Migration{selfTestVersion: selfTestMigration{}})
+	var steps []SelfTestStep
+	failed := false
+	try := func(step string, fn func() error) {
+		if failed {
+			return
+		}
+		s := SelfTestStep{Step: step, Passed: true}
+		if err := fn(); err != nil {
+			s.Passed, s.Error, failed = false, err.Error(), true
+		}
+		steps = append(steps, s)
+	}
+	recorded := func(want bool) func() error {
+		return func() error {
+			applied, err := probe.getAppliedMap(ctx)
+			if err != nil {
+				return err
+			}
+			if _, ok := applied[selfTestVersion]; ok != want {
+				return fmt.Errorf("record for %s in %s: got %t, want %t", selfTestVersion, name, ok, want)
+			}
+			return nil
+		}
+	}
+
+	try("apply", func() error { return probe.Up(ctx, "") })
+	try("record written", func() error {
+		names, err := e.db.ListCollectionNames(ctx, bson.M{"name": name})
+		if err == nil && !slices.Contains(names, name) {
+			err = fmt.Errorf("collection %s was not created", name)
+		}
+		if err != nil {
+			return err
+		}
+		return recorded(true)()
+	})
+	try("roll back", func() error { return probe.Down(ctx, "") })
+	try("record removed", recorded(false))
+
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), probeCleanupTimeout)
+	defer cancel()
+	failed = false
+	try("drop collection", func() error { return e.db.Collection(name).Drop(cleanupCtx) })
+	return steps, nil
+}
@@ -0,0 +1,70 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// StatusSummary condenses GetStatus into counts for scripts and CI. Dirty is set when an
+// applied migration's checksum no longer matches or a record has no registered migration.
+type StatusSummary struct {
+	Applied int  `json:"applied"`
+	Pending int  `json:"pending"`
+	Dirty   bool `json:"dirty"`
+}
+
+// String formats the summary as a single key=value line.
+func (s StatusSummary) String() string {
+	return fmt.Sprintf("applied=%d pending=%d dirty=%t", s.Applied, s.Pending, s.Dirty)
+}
+
+func (e *Engine) Summary(ctx context.Context) (StatusSummary, error) {
+	applied, err := e.getAppliedMap(ctx)
+	if err != nil {
+		return StatusSummary{}, fmt.Errorf("%w: %w", ErrFailedToReadMigrations, err)
+	}
+	return e.summarize(applied), nil
+}
+
+func (e *Engine) summarize(applied map[string]MigrationRecord) StatusSummary {
+	var s StatusSummary
+	for v, m := range e.migrations {
+		rec, ok := applied[v]
+		if !ok {
+			s.Pending++
+			continue
+		}
+		if e.validateChecksum(m, rec) != nil {
+			s.Dirty = true
+		}
+	}
+	for v := range applied {
+		if _, ok := e.migrations[v]; !ok {
+			s.Dirty = true
+		}
+	}
+	s.Applied = len(applied)
+	return s
+}
+
+// Validate checks that every applied migration that is still registered matches its
+// record. It returns the mismatches joined, each as a *MigrationError wrapping
+// ErrChecksumMismatch, or nil when the history is clean.
+func (e *Engine) Validate(ctx context.Context) error {
+	applied, err := e.getAppliedMap(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrFailedToReadMigrations, err)
+	}
+	var errs []error
+	for _, v := range e.getSortedVersions(DirectionUp) {
+		rec, ok := applied[v]
+		if !ok {
+			continue
+		}
+		if err := e.validateChecksum(e.migrations[v], rec); err != nil {
+			errs = append(errs, &MigrationError{Version: v, Direction: DirectionUp, Err: err})
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,29 @@
+package migration
+
+import "time"
+
+// Phases reported through WithTimings.
+const (
+	PhaseLock      = "lock"
+	PhaseStatus    = "status"
+	PhaseMigration = "migration"
+)
+
+// PhaseTiming is how long one phase of a run took. Version and Direction are set for
+// PhaseMigration only; Failed is set when the phase returned an error.
+type PhaseTiming struct {
+	Phase     string        `json:"phase"`
+	Version   string        `json:"version,omitempty"`
+	Direction Direction     `json:"direction,omitempty"`
+	Duration  time.Duration `json:"duration_ns"`
+	Failed    bool          `json:"failed,omitempty"`
+}
+
+func (e *Engine) recordTiming(t PhaseTiming, start time.Time, err error) {
+	if e.onTiming == nil {
+		return
+	}
+	t.Duration = time.Since(start)
+	t.Failed = err != nil
+	e.onTiming(t)
+}
@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// indexRollbackTimeout bounds Rollback, which keeps running after the caller's ctx is done.
+const indexRollbackTimeout = 30 * time.Second
+
+// TrackedIndexes creates indexes on one collection and remembers which of them it created,
+// so an Up that fails partway can drop exactly those. Without a transaction, as on a
+// standalone server, nothing else removes them. Indexes that already existed are never
+// tracked, so Rollback leaves them alone. Typical use in Up:
+//
+//	tracked := migration.NewTrackedIndexes(db.Collection("users"))
+//	defer func() {
+//		if err != nil {
+//			err = errors.Join(err, tracked.Rollback(ctx))
+//		}
+//	}()
+type TrackedIndexes struct {
+	coll    *mongo.Collection
+	created []string
+}
+
+func NewTrackedIndexes(coll *mongo.Collection) *TrackedIndexes {
+	return &TrackedIndexes{coll: coll}
+}
+
+// Create builds the indexes one at a time, in order, naming them as CreateIndexes does.
+// It stops at the first failure; the indexes created before it stay tracked.
+func (t *TrackedIndexes) Create(ctx context.Context, indexes ...*IndexBuilder) error {
+	specs, err := t.coll.Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return fmt.Errorf("list indexes failed: %w", err)
+	}
+	existing := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		existing[spec.Name] = true
+	}
+
+	for _, idx := range indexes {
+		if idx == nil {
+			continue
+		}
+		model := idx.Model()
+		if err := ensureIndexName(&model, nil); err != nil {
+			return err
+		}
+		name, err := t.coll.Indexes().CreateOne(ctx, model)
+		if err != nil {
+			return fmt.Errorf("create index %s failed: %w", indexName(model), err)
+		}
+		if !existing[name] && !slices.Contains(t.created, name) {
+			t.created = append(t.created, name)
+		}
+	}
+	return nil
+}
+
+// Created returns the names of the indexes created so far, in creation order.
+func (t *TrackedIndexes) Created() []string {
+	return slices.Clone(t.created)
+}
+
+// Rollback drops the tracked indexes, newest first, and forgets them. It runs even when
+// ctx is already cancelled, since it is meant for cleanup after a failed or interrupted
+// Up. Indexes that are already gone are skipped; other failures are joined.
+func (t *TrackedIndexes) Rollback(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), indexRollbackTimeout)
+	defer cancel()
+
+	var errs []error
+	var kept []string
+	for i := len(t.created) - 1; i >= 0; i-- {
+		if err := DropIndexes(ctx, t.coll, t.created[i]); err != nil {
+			errs = append(errs, err)
+			kept = append(kept, t.created[i])
+		}
+	}
+	slices.Reverse(kept)
+	t.created = kept
+	return errors.Join(errs...)
+}
+
+func indexName(model mongo.IndexModel) string {
+	values, err := buildIndexOptions(model.Options)
+	if err != nil || values.Name == nil {
+		return "(unnamed)"
+	}
+	return *values.Name
+}
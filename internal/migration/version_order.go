@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+)
+
+// VersionComparator orders migration versions the way strings.Compare orders strings.
+type VersionComparator func(a, b string) int
+
+// CompareLexical orders versions as plain strings. It is the default and suits the
+// zero-padded timestamp and sequence formats.
+func CompareLexical(a, b string) int { return strings.Compare(a, b) }
+
+// CompareNumeric orders runs of digits by their value, so 2_seed sorts before 10_seed and
+// v1.9.0 before v1.10.0. Everything else compares byte by byte.
+func CompareNumeric(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			ei, ej := digitRunEnd(a, i), digitRunEnd(b, j)
+			na, nb := strings.TrimLeft(a[i:ei], "0"), strings.TrimLeft(b[j:ej], "0")
+			if len(na) != len(nb) {
+				return cmp.Compare(len(na), len(nb))
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			i, j = ei, ej
+			continue
+		}
+		if a[i] != b[j] {
+			return cmp.Compare(a[i], b[j])
+		}
+		i++
+		j++
+	}
+	if c := cmp.Compare(len(a)-i, len(b)-j); c != 0 {
+		return c
+	}
+	// Equal by value, e.g. 01 and 1; fall back to the strings for a total order.
+	return strings.Compare(a, b)
+}
+
+// ParseVersionOrder returns the comparator named by a config value: lexical (or empty)
+// or numeric.
+func ParseVersionOrder(name string) (VersionComparator, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "lexical":
+		return CompareLexical, nil
+	case "numeric":
+		return CompareNumeric, nil
+	default:
+		return nil, fmt.Errorf("%w: %q (want lexical or numeric)", ErrInvalidVersionOrder, name)
+	}
+}
+
+// CompareVersions orders a and b with the engine's comparator.
+func (e *Engine) CompareVersions(a, b string) int {
+	if e.compare == nil {
+		return CompareLexical(a, b)
+	}
+	return e.compare(a, b)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func digitRunEnd(s string, i int) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
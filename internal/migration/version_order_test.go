@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareNumeric(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2_seed", "10_index", -1},
+		{"v1.9.0_a", "v1.10.0_a", -1},
+		{"0002_users", "0010_users", -1},
+		{"20240101_001", "20240101_001", 0},
+		{"1_a", "1_b", -1},
+		{"1", "1_a", -1},
+		{"01_a", "1_a", -1},
+	}
+	for _, tt := range tests {
+		if got := CompareNumeric(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareNumeric(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := CompareNumeric(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareNumeric(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestVersionComparatorOrdersPlan(t *testing.T) {
+	ms := map[string]Migration{}
+	for _, v := range []string{"10_index", "2_seed", "9_backfill"} {
+		ms[v] = nil
+	}
+
+	lexical := NewEngine(nil, "", ms)
+	if got := lexical.getSortedVersions(DirectionUp); !slices.Equal(got, []string{"10_index", "2_seed", "9_backfill"}) {
+		t.Errorf("lexical order = %v", got)
+	}
+	numeric := NewEngine(nil, "", ms, WithVersionComparator(CompareNumeric))
+	if got := numeric.getSortedVersions(DirectionUp); !slices.Equal(got, []string{"2_seed", "9_backfill", "10_index"}) {
+		t.Errorf("numeric order = %v", got)
+	}
+
+	if _, err := ParseVersionOrder("semantic"); err == nil {
+		t.Error("ParseVersionOrder accepted an unknown order")
+	}
+}
@@ -0,0 +1,11 @@
+package render
+
+type ErrorRender string
+
+func (e ErrorRender) Error() string {
+	return string(e)
+}
+
+const (
+	ErrUnsupportedFormat = ErrorRender("unsupported output format")
+)
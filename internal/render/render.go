@@ -0,0 +1,209 @@
+// Package render writes list results in the output formats shared by the CLI commands.
+package render
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+)
+
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatYAML  = "yaml"
+)
+
+// Formats lists every supported format, in the order used for flag help.
+var Formats = []string{FormatTable, FormatJSON, FormatJSONL, FormatCSV, FormatYAML}
+
+// FlagUsage is the help text for an --output flag backed by this package.
+var FlagUsage = "Output format (" + strings.Join(Formats, ", ") + ")"
+
+// List is one result set. Table and CSV output use Columns and Rows; JSON, JSON Lines and
+// YAML encode Items, which must be a slice.
+type List struct {
+	Columns []string
+	Rows    [][]string
+	Items   any
+	// Empty is printed instead of a table when there are no rows.
+	Empty string
+}
+
+// Encoder writes a List in one output format.
+type Encoder interface {
+	Encode(list List) error
+}
+
+// NewEncoder returns the encoder for format, which is matched case-insensitively.
+// An empty format selects the table.
+func NewEncoder(format string, w io.Writer) (Encoder, error) {
+	switch strings.ToLower(format) {
+	case FormatTable, "":
+		return tableEncoder{w: w}, nil
+	case FormatJSON:
+		return jsonEncoder{w: w}, nil
+	case FormatJSONL:
+		return jsonlEncoder{w: w}, nil
+	case FormatCSV:
+		return csvEncoder{w: w}, nil
+	case FormatYAML, "yml":
+		return yamlEncoder{w: w}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// Write is shorthand for NewEncoder followed by Encode.
+func Write(w io.Writer, format string, list List) error {
+	enc, err := NewEncoder(format, w)
+	if err != nil {
+		return err
+	}
+	return enc.Encode(list)
+}
+
+type tableEncoder struct{ w io.Writer }
+
+// tablePadding is the number of spaces between table columns.
+const tablePadding = 3
+
+// Encode aligns the columns by their visible width, so cells carrying terminal styling
+// line up with plain ones.
+func (e tableEncoder) Encode(list List) error {
+	if len(list.Rows) == 0 && list.Empty != "" {
+		_, err := fmt.Fprintln(e.w, list.Empty)
+		return err
+	}
+
+	rule := make([]string, len(list.Columns))
+	for i, col := range list.Columns {
+		rule[i] = strings.Repeat("-", len(col))
+	}
+	lines := append([][]string{list.Columns, rule}, list.Rows...)
+
+	var widths []int
+	for _, line := range lines {
+		for i, cell := range line {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], visibleWidth(cell))
+		}
+	}
+
+	var b strings.Builder
+	for _, line := range lines {
+		for i, cell := range line {
+			b.WriteString(cell)
+			if i < len(line)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-visibleWidth(cell)+tablePadding))
+			}
+		}
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(e.w, b.String())
+	return err
+}
+
+// visibleWidth is the number of runes in cell once terminal styling is removed.
+func visibleWidth(cell string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(cell, ""))
+}
+
+type jsonEncoder struct{ w io.Writer }
+
+func (e jsonEncoder) Encode(list List) error {
+	enc := jsonutil.NewEncoder(e.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(list.Items)
+}
+
+type jsonlEncoder struct{ w io.Writer }
+
+func (e jsonlEncoder) Encode(list List) error {
+	items := reflect.ValueOf(list.Items)
+	if items.Kind() != reflect.Slice {
+		return fmt.Errorf("%w: jsonl needs a slice, got %T", ErrUnsupportedFormat, list.Items)
+	}
+	for i := 0; i < items.Len(); i++ {
+		line, err := jsonutil.Marshal(items.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		if _, err := e.w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+type csvEncoder struct{ w io.Writer }
+
+// Encode writes a header and one record per row. Terminal styling and padding meant for
+// the table are stripped from cells.
+func (e csvEncoder) Encode(list List) error {
+	cw := csv.NewWriter(e.w)
+	if err := cw.Write(list.Columns); err != nil {
+		return err
+	}
+	for _, row := range list.Rows {
+		record := make([]string, len(row))
+		for i, cell := range row {
+			record[i] = strings.TrimSpace(ansiPattern.ReplaceAllString(cell, ""))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type yamlEncoder struct{ w io.Writer }
+
+// Encode goes through JSON first so YAML keys and omitted fields match the JSON output,
+// and decodes into a yaml.Node so field order survives.
+func (e yamlEncoder) Encode(list List) error {
+	data, err := jsonutil.Marshal(list.Items)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf.Bytes())
+	return err
+}
+
+// blockStyle drops the flow and quoting styles that parsing JSON leaves on every node.
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
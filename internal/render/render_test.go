@@ -0,0 +1,63 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type item struct {
+	Version string `json:"version"`
+	Count   int    `json:"count"`
+	Note    string `json:"note,omitempty"`
+}
+
+func sampleList() List {
+	return List{
+		Columns: []string{"VERSION", "COUNT"},
+		Rows:    [][]string{{"\033[32m001\033[0m", "1"}, {"002", "2"}},
+		Items:   []item{{Version: "001", Count: 1}, {Version: "002", Count: 2, Note: "x"}},
+		Empty:   "Nothing here.",
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: FormatTable, want: "VERSION   COUNT\n-------   -----\n\033[32m001\033[0m       1\n002       2\n"},
+		{format: FormatJSON, want: "[\n  {\n    \"version\": \"001\",\n    \"count\": 1\n  },\n  {\n    \"version\": \"002\",\n    \"count\": 2,\n    \"note\": \"x\"\n  }\n]\n"},
+		{format: FormatJSONL, want: "{\"version\":\"001\",\"count\":1}\n{\"version\":\"002\",\"count\":2,\"note\":\"x\"}\n"},
+		{format: FormatCSV, want: "VERSION,COUNT\n001,1\n002,2\n"},
+		{format: FormatYAML, want: "- version: \"001\"\n  count: 1\n- version: \"002\"\n  count: 2\n  note: x\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, tt.format, sampleList()); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got:\n%q\nwant:\n%q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTableEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "", List{Columns: []string{"A"}, Empty: "Nothing here."}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "Nothing here.\n" {
+		t.Errorf("got %q", buf.String())
+	}
+}
+
+func TestNewEncoderUnsupported(t *testing.T) {
+	if _, err := NewEncoder("xml", &bytes.Buffer{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("NewEncoder(xml) error = %v, want ErrUnsupportedFormat", err)
+	}
+}
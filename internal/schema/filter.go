@@ -0,0 +1,64 @@
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// CollectionFilter selects collections by exact name or regular expression.
+// A nil or empty filter matches every collection.
+type CollectionFilter struct {
+	names   map[string]struct{}
+	pattern *regexp.Regexp
+}
+
+func NewCollectionFilter(names []string, pattern string) (*CollectionFilter, error) {
+	f := &CollectionFilter{names: make(map[string]struct{}, len(names))}
+	for _, n := range names {
+		if n = strings.TrimSpace(n); n != "" {
+			f.names[n] = struct{}{}
+		}
+	}
+	if pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid collection pattern: %w", err)
+		}
+		f.pattern = re
+	}
+	return f, nil
+}
+
+// Match reports whether name is listed explicitly or matches the pattern.
+func (f *CollectionFilter) Match(name string) bool {
+	if f == nil || (len(f.names) == 0 && f.pattern == nil) {
+		return true
+	}
+	if _, ok := f.names[name]; ok {
+		return true
+	}
+	return f.pattern != nil && f.pattern.MatchString(name)
+}
+
+// Collections returns the subset of names accepted by the filter, preserving order.
+func (f *CollectionFilter) Collections(names []string) []string {
+	out := make([]string, 0, len(names))
+	for _, n := range names {
+		if f.Match(n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// FilterIndexes returns the specs whose collection is accepted by the filter.
+func (f *CollectionFilter) FilterIndexes(specs []IndexSpec) []IndexSpec {
+	out := make([]IndexSpec, 0, len(specs))
+	for _, spec := range specs {
+		if f.Match(spec.Collection) {
+			out = append(out, spec)
+		}
+	}
+	return out
+}
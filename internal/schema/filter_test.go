@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectionFilter(t *testing.T) {
+	all := []string{"users", "user_events", "orders", "audit_log"}
+
+	tests := []struct {
+		name    string
+		names   []string
+		pattern string
+		want    []string
+	}{
+		{name: "No filter keeps all", want: all},
+		{name: "Exact names", names: []string{"orders", "audit_log"}, want: []string{"orders", "audit_log"}},
+		{name: "Pattern", pattern: "^user", want: []string{"users", "user_events"}},
+		{name: "Names and pattern", names: []string{"orders"}, pattern: "_log$",
+			want: []string{"orders", "audit_log"}},
+		{name: "Unknown name", names: []string{"missing"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewCollectionFilter(tt.names, tt.pattern)
+			if err != nil {
+				t.Fatalf("NewCollectionFilter() error = %v", err)
+			}
+			if got := f.Collections(all); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Collections() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollectionFilterIndexes(t *testing.T) {
+	specs := []IndexSpec{
+		{Collection: "users", Name: "idx_users_email"},
+		{Collection: "orders", Name: "idx_orders_created_at"},
+	}
+	f, err := NewCollectionFilter([]string{"orders"}, "")
+	if err != nil {
+		t.Fatalf("NewCollectionFilter() error = %v", err)
+	}
+
+	got := f.FilterIndexes(specs)
+	if len(got) != 1 || got[0].Collection != "orders" {
+		t.Errorf("FilterIndexes() = %v, want only orders", got)
+	}
+}
+
+func TestCollectionFilterInvalidPattern(t *testing.T) {
+	if _, err := NewCollectionFilter(nil, "("); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestNilCollectionFilterMatchesAll(t *testing.T) {
+	var f *CollectionFilter
+	if !f.Match("anything") {
+		t.Error("nil filter should match every collection")
+	}
+}
@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Symbol is a decoration printed in front of a result line. It renders in the icon set
+// chosen with SetIcons or MMT_ICONS: plain Unicode marks by default, emoji, or bracketed
+// ASCII tags for terminals and log parsers that choke on multibyte output. MMT_ASCII and
+// SetASCII(true) select the ASCII set too.
+type Symbol int
+
+const (
+	OK Symbol = iota
+	Fail
+	Warn
+	Done
+	Created
+	RolledBack
+	Applied
+	Pending
+	Start
+	Finished
+	Failed
+	// Mark is the bare applied mark for compact layouts such as status templates.
+	Mark
+)
+
+// IconSet selects how every Symbol renders.
+type IconSet int32
+
+const (
+	IconsUnicode IconSet = iota
+	IconsASCII
+	IconsEmoji
+)
+
+var iconSetNames = [...]string{IconsUnicode: "unicode", IconsASCII: "ascii", IconsEmoji: "emoji"}
+
+var symbols = [...][len(iconSetNames)]string{
+	OK:         {IconsUnicode: "✓", IconsASCII: "[OK]", IconsEmoji: "✅"},
+	Fail:       {IconsUnicode: "✗", IconsASCII: "[FAIL]", IconsEmoji: "❌"},
+	Warn:       {IconsUnicode: "!", IconsASCII: "[WARN]", IconsEmoji: "⚠️ "},
+	Done:       {IconsUnicode: "✓", IconsASCII: "[DONE]", IconsEmoji: "✨"},
+	Created:    {IconsUnicode: "+", IconsASCII: "[NEW]", IconsEmoji: "🚀"},
+	RolledBack: {IconsUnicode: "↩", IconsASCII: "[UNDO]", IconsEmoji: "↩️ "},
+	Applied:    {IconsUnicode: "[✓]", IconsASCII: "[APPLIED]", IconsEmoji: "✅"},
+	Pending:    {IconsUnicode: "[ ]", IconsASCII: "[PENDING]", IconsEmoji: "⏳"},
+	Start:      {IconsUnicode: "▶", IconsASCII: "[RUN]", IconsEmoji: "▶"},
+	Finished:   {IconsUnicode: "✔", IconsASCII: "[OK]", IconsEmoji: "✔"},
+	Failed:     {IconsUnicode: "✖", IconsASCII: "[FAIL]", IconsEmoji: "✖"},
+	Mark:       {IconsUnicode: "✓", IconsASCII: "x", IconsEmoji: "✅"},
+}
+
+var icons atomic.Int32
+
+func init() {
+	if name := os.Getenv("MMT_ICONS"); name != "" {
+		if set, err := ParseIconSet(name); err == nil {
+			SetIcons(set)
+			return
+		}
+	}
+	enabled, _ := strconv.ParseBool(os.Getenv("MMT_ASCII"))
+	SetASCII(enabled)
+}
+
+// ParseIconSet parses unicode, ascii or emoji, ignoring case. An empty name is unicode.
+func ParseIconSet(name string) (IconSet, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return IconsUnicode, nil
+	}
+	for set, n := range iconSetNames {
+		if n == name {
+			return IconSet(set), nil
+		}
+	}
+	return IconsUnicode, fmt.Errorf("unknown icon set %q: use %s", name, strings.Join(iconSetNames[:], ", "))
+}
+
+func (s IconSet) String() string {
+	if s < 0 || int(s) >= len(iconSetNames) {
+		return ""
+	}
+	return iconSetNames[s]
+}
+
+// SetIcons switches every Symbol to set.
+func SetIcons(set IconSet) {
+	icons.Store(int32(set))
+}
+
+// Icons returns the icon set symbols render in.
+func Icons() IconSet {
+	return IconSet(icons.Load())
+}
+
+// SetASCII switches every Symbol to its ASCII form, or back to the default Unicode set.
+func SetASCII(enabled bool) {
+	if enabled {
+		SetIcons(IconsASCII)
+	} else {
+		SetIcons(IconsUnicode)
+	}
+}
+
+// ASCII reports whether symbols render as ASCII.
+func ASCII() bool {
+	return Icons() == IconsASCII
+}
+
+func (s Symbol) String() string {
+	set := Icons()
+	if s < 0 || int(s) >= len(symbols) || set < 0 || int(set) >= len(iconSetNames) {
+		return ""
+	}
+	return symbols[s][set]
+}
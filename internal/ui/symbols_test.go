@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSymbolsASCII(t *testing.T) {
+	t.Cleanup(func() { SetASCII(false) })
+
+	for s := OK; int(s) < len(symbols); s++ {
+		SetIcons(IconsEmoji)
+		if fancy := s.String(); utf8.RuneCountInString(fancy) == len(fancy) {
+			t.Errorf("symbol %d = %q, want an emoji in the emoji set", s, fancy)
+		}
+
+		SetIcons(IconsUnicode)
+		if s.String() == "" {
+			t.Errorf("symbol %d has no Unicode form", s)
+		}
+
+		SetASCII(true)
+		plain := s.String()
+		if plain == "" {
+			t.Errorf("symbol %d has no ASCII form", s)
+		}
+		for i := 0; i < len(plain); i++ {
+			if plain[i] >= utf8.RuneSelf {
+				t.Errorf("symbol %d = %q in ASCII mode, has multibyte byte %#x", s, plain, plain[i])
+				break
+			}
+		}
+	}
+}
+
+func TestIconSets(t *testing.T) {
+	t.Cleanup(func() { SetASCII(false) })
+
+	tests := []struct {
+		name             string
+		applied, pending string
+		ok, fail         string
+	}{
+		{name: "unicode", applied: "[✓]", pending: "[ ]", ok: "✓", fail: "✗"},
+		{name: "ascii", applied: "[APPLIED]", pending: "[PENDING]", ok: "[OK]", fail: "[FAIL]"},
+		{name: "emoji", applied: "✅", pending: "⏳", ok: "✅", fail: "❌"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseIconSet(" " + tt.name + " ")
+			if err != nil {
+				t.Fatalf("ParseIconSet(%q) error = %v", tt.name, err)
+			}
+			if set.String() != tt.name {
+				t.Errorf("String() = %q, want %q", set, tt.name)
+			}
+			SetIcons(set)
+			got := []string{Applied.String(), Pending.String(), OK.String(), Fail.String()}
+			want := []string{tt.applied, tt.pending, tt.ok, tt.fail}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("markers = %q, want %q", got, want)
+					break
+				}
+			}
+		})
+	}
+
+	if set, err := ParseIconSet(""); err != nil || set != IconsUnicode {
+		t.Errorf("ParseIconSet(\"\") = %v, %v; want the unicode default", set, err)
+	}
+	if _, err := ParseIconSet("fancy"); err == nil {
+		t.Error("ParseIconSet(\"fancy\") succeeded")
+	}
+
+	SetIcons(IconsEmoji)
+	SetASCII(false)
+	if Icons() != IconsUnicode {
+		t.Errorf("SetASCII(false) left %s, want the unicode default", Icons())
+	}
+}
@@ -1,5 +1,12 @@
 package mcp
 
+import (
+	"errors"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/dbconn"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+)
+
 type ErrorMcp string
 
 func (e ErrorMcp) Error() string {
@@ -17,4 +24,44 @@ const (
 	ErrFailedToUpdateMigration = ErrorMcp("failed to update migration")
 	ErrFailedToDeleteMigration = ErrorMcp("failed to delete migration")
 	ErrMigrationNotFound       = ErrorMcp("migration not found")
+	ErrInvalidArguments        = ErrorMcp("invalid arguments")
+)
+
+// Error codes returned in the error_code field of failed tool results.
+const (
+	codeMigrationFailed   = "migration_failed"
+	codeChecksumMismatch  = "checksum_mismatch"
+	codeMigrationNotFound = "migration_not_found"
+	codeLockUnavailable   = "lock_unavailable"
+	codeLockLost          = "lock_lost"
+	codeReadOnly          = "read_only"
+	codeWrongDatabase     = "wrong_database"
+	codeConnectionFailed  = "connection_failed"
+	codeInvalidArguments  = "invalid_arguments"
+	codeInternal          = "internal_error"
 )
+
+func errorCode(err error) string {
+	switch {
+	case errors.Is(err, migration.ErrChecksumMismatch):
+		return codeChecksumMismatch
+	case errors.Is(err, migration.ErrLockLost):
+		return codeLockLost
+	case errors.Is(err, migration.ErrReadOnly):
+		return codeReadOnly
+	case errors.Is(err, migration.ErrWrongDatabase):
+		return codeWrongDatabase
+	case errors.Is(err, migration.ErrFailedToLock):
+		return codeLockUnavailable
+	case errors.Is(err, migration.ErrMigrationNotFound), errors.Is(err, ErrMigrationNotFound):
+		return codeMigrationNotFound
+	case errors.Is(err, migration.ErrFailedToRunMigration):
+		return codeMigrationFailed
+	case errors.Is(err, dbconn.ErrFailedToConnect), errors.Is(err, dbconn.ErrUnreachable):
+		return codeConnectionFailed
+	case errors.Is(err, ErrInvalidArguments):
+		return codeInvalidArguments
+	default:
+		return codeInternal
+	}
+}
@@ -2,9 +2,13 @@ package mcp
 
 import (
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
@@ -16,11 +20,11 @@ func formatStatusTable(status []migration.MigrationStatus) string {
 	b.WriteString("| :--- | :--- | :--- | :--- |\n")
 
 	for _, st := range status {
-		applied := "⏳ Pending"
+		applied := ui.Pending.String() + " Pending"
 		appliedAt := "N/A"
 
 		if st.Applied {
-			applied = "✅ Applied"
+			applied = ui.Applied.String() + " Applied"
 			if st.AppliedAt != nil {
 				appliedAt = st.AppliedAt.Format("2006-01-02 15:04")
 			}
@@ -32,6 +36,157 @@ func formatStatusTable(status []migration.MigrationStatus) string {
 	return b.String()
 }
 
+func formatCatalogTable(catalog []migration.CatalogEntry) string {
+	if len(catalog) == 0 {
+		return "No migrations registered."
+	}
+	var b strings.Builder
+	b.WriteString("### Registered Migrations\n\n")
+	b.WriteString("| Version | Description |\n")
+	b.WriteString("| :--- | :--- |\n")
+	for _, e := range catalog {
+		fmt.Fprintf(&b, "| %s | %s |\n", e.Version, e.Description)
+	}
+	return b.String()
+}
+
+// formatVerboseStatusTable is formatStatusTable plus a short checksum and the Up duration
+// taken from the applied records. Pending migrations and older records show a dash.
+func formatVerboseStatusTable(status []migration.MigrationStatus, records []migration.MigrationRecord) string {
+	byVersion := make(map[string]migration.MigrationRecord, len(records))
+	for _, rec := range records {
+		byVersion[rec.Version] = rec
+	}
+
+	var b strings.Builder
+	b.WriteString("### Migration Status\n\n")
+	b.WriteString("| Version | Status | Applied At | Checksum | Duration | Description |\n")
+	b.WriteString("| :--- | :--- | :--- | :--- | :--- | :--- |\n")
+
+	for _, st := range status {
+		applied, appliedAt, checksum, duration := ui.Pending.String()+" Pending", "N/A", "-", "-"
+		if st.Applied {
+			applied = ui.Applied.String() + " Applied"
+			if st.AppliedAt != nil {
+				appliedAt = st.AppliedAt.Format("2006-01-02 15:04")
+			}
+		}
+		if rec, ok := byVersion[st.Version]; ok {
+			if rec.Checksum != "" {
+				checksum = "`" + rec.Checksum[:min(len(rec.Checksum), 8)] + "`"
+			}
+			if rec.DurationMS > 0 {
+				duration = (time.Duration(rec.DurationMS) * time.Millisecond).String()
+			}
+		}
+
+		b.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s |\n",
+			st.Version, applied, appliedAt, checksum, duration, st.Description))
+	}
+	return b.String()
+}
+
+func formatRunReport(run migration.RunResult, elapsed time.Duration) string {
+	if run.Pending == 0 {
+		if run.Direction == migration.DirectionDown {
+			return "No applied migrations to roll back."
+		}
+		return "Already up to date (0 pending)."
+	}
+
+	verb := "Applied"
+	if run.Direction == migration.DirectionDown {
+		verb = "Rolled back"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %d migration(s) in %s:\n", ui.OK, verb, len(run.Executed), elapsed.Round(time.Millisecond))
+	for _, v := range run.Executed {
+		fmt.Fprintf(&b, "- `%s`\n", v)
+	}
+	if len(run.Skipped) > 0 {
+		fmt.Fprintf(&b, "%d left pending for other environments:\n", len(run.Skipped))
+		for _, v := range run.Skipped {
+			fmt.Fprintf(&b, "- `%s`\n", v)
+		}
+	}
+	return b.String()
+}
+
+// Bounds of the document preview in database_oplog. Zero or negative max_fields and
+// max_len select the default; larger values are capped so one entry cannot flood the
+// assistant's context.
+const (
+	defaultPreviewFields = 5
+	maxPreviewFields     = 50
+	defaultPreviewLen    = 80
+	minPreviewLen        = 10
+	maxPreviewLen        = 1000
+)
+
+func formatOplogTable(entries []oplogEntry, maxFields, maxLen int) string {
+	if len(entries) == 0 {
+		return "No oplog entries."
+	}
+	var b strings.Builder
+	b.WriteString("### Oplog\n\n")
+	b.WriteString("| Time | Op | Namespace | Data |\n")
+	b.WriteString("| :--- | :--- | :--- | :--- |\n")
+	for _, e := range entries {
+		ts := time.Unix(int64(e.TS.T), 0).UTC()
+		if e.Wall != nil {
+			ts = e.Wall.UTC()
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", ts.Format("2006-01-02 15:04:05"), e.Op, e.NS,
+			strings.ReplaceAll(formatData(e.O, maxFields, maxLen), "|", `\|`))
+	}
+	return b.String()
+}
+
+// formatData renders doc as a one-line preview of at most maxFields fields in key order,
+// cut to maxLen characters. _id is skipped. Left-out fields are counted as "(+N more)" and
+// a cut preview ends in "...".
+func formatData(doc bson.M, maxFields, maxLen int) string {
+	maxFields = boundPreview(maxFields, defaultPreviewFields, 1, maxPreviewFields)
+	maxLen = boundPreview(maxLen, defaultPreviewLen, minPreviewLen, maxPreviewLen)
+
+	keys := make([]string, 0, len(doc))
+	for k := range doc {
+		if k != "_id" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return "-"
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, 0, min(len(keys), maxFields)+1)
+	for _, k := range keys[:min(len(keys), maxFields)] {
+		value, err := jsonutil.Marshal(doc[k])
+		if err != nil {
+			value = []byte(fmt.Sprint(doc[k]))
+		}
+		parts = append(parts, k+"="+string(value))
+	}
+	if extra := len(keys) - maxFields; extra > 0 {
+		parts = append(parts, fmt.Sprintf("(+%d more)", extra))
+	}
+
+	preview := []rune(strings.Join(parts, " "))
+	if len(preview) <= maxLen {
+		return string(preview)
+	}
+	return string(preview[:maxLen-3]) + "..."
+}
+
+// boundPreview returns def for a zero or negative n and n clamped to [lo, hi] otherwise.
+func boundPreview(n, def, lo, hi int) int {
+	if n <= 0 {
+		return def
+	}
+	return min(max(n, lo), hi)
+}
+
 func formatIndexKeys(keys interface{}) string {
 	var keyParts []string
 	if doc, ok := keys.(bson.D); ok {
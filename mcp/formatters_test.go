@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestFormatStatusTableVerbose(t *testing.T) {
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	status := []migration.MigrationStatus{
+		{Version: "20240101_001", Description: "add users", Applied: true, AppliedAt: &appliedAt},
+		{Version: "20240102_001", Description: "add orders"},
+	}
+	records := []migration.MigrationRecord{
+		{Version: "20240101_001", Checksum: "0123456789abcdef", DurationMS: 1500},
+	}
+
+	compact := formatStatusTable(status)
+	for _, column := range []string{"Checksum", "Duration"} {
+		if strings.Contains(compact, column) {
+			t.Errorf("compact table has a %s column:\n%s", column, compact)
+		}
+	}
+
+	verbose := formatVerboseStatusTable(status, records)
+	for _, want := range []string{
+		"| Version | Status | Applied At | Checksum | Duration | Description |",
+		"| 20240101_001 | [✓] Applied | 2024-01-02 03:04 | `01234567` | 1.5s | add users |",
+		"| 20240102_001 | [ ] Pending | N/A | - | - | add orders |",
+	} {
+		if !strings.Contains(verbose, want) {
+			t.Errorf("verbose table is missing %q:\n%s", want, verbose)
+		}
+	}
+}
+
+func TestFormattersASCII(t *testing.T) {
+	ui.SetASCII(true)
+	t.Cleanup(func() { ui.SetASCII(false) })
+
+	appliedAt := time.Date(2024, 1, 2, 3, 4, 0, 0, time.UTC)
+	status := []migration.MigrationStatus{
+		{Version: "20240101_001", Description: "add users", Applied: true, AppliedAt: &appliedAt},
+		{Version: "20240102_001", Description: "add orders"},
+	}
+	res, _, _ := newErrorResult(errors.New("boom"))
+	outputs := []string{
+		formatStatusTable(status),
+		formatVerboseStatusTable(status, nil),
+		formatRunReport(migration.RunResult{
+			Direction: migration.DirectionUp, Pending: 1, Executed: []string{"20240101_001"},
+		}, time.Second),
+		res.Content[0].(*mcp.TextContent).Text,
+	}
+	for _, out := range outputs {
+		for i := 0; i < len(out); i++ {
+			if out[i] >= utf8.RuneSelf {
+				t.Fatalf("multibyte byte %#x in ASCII output:\n%s", out[i], out)
+			}
+		}
+	}
+	if !strings.Contains(outputs[0], "[APPLIED] Applied") || !strings.Contains(outputs[3], "[FAIL] boom") {
+		t.Errorf("ASCII tags missing:\n%s\n%s", outputs[0], outputs[3])
+	}
+}
+
+func TestFormatData(t *testing.T) {
+	doc := bson.M{"_id": "u1", "a": 1, "b": "two", "c": true}
+
+	tests := []struct {
+		name              string
+		maxFields, maxLen int
+		want              string
+	}{
+		{name: "Defaults", want: `a=1 b="two" c=true`},
+		{name: "Field cap", maxFields: 2, want: `a=1 b="two" (+1 more)`},
+		{name: "Length cap", maxLen: 12, want: `a=1 b="tw...`},
+		{name: "Below the minimum length", maxLen: 1, want: `a=1 b="...`},
+		{name: "Above the field cap", maxFields: 1000, want: `a=1 b="two" c=true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatData(doc, tt.maxFields, tt.maxLen); got != tt.want {
+				t.Errorf("formatData(%d, %d) = %q, want %q", tt.maxFields, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+
+	if got := formatData(bson.M{"_id": "s9"}, 0, 0); got != "-" {
+		t.Errorf("formatData() of an _id-only document = %q, want -", got)
+	}
+	long := formatData(bson.M{"text": strings.Repeat("x", 5000)}, 0, 5000)
+	if len(long) != maxPreviewLen || !strings.HasSuffix(long, "...") {
+		t.Errorf("formatData() with max_len above the cap is %d characters, want %d ending in ...", len(long), maxPreviewLen)
+	}
+	many := bson.M{}
+	for i := range 60 {
+		many[fmt.Sprintf("f%02d", i)] = i
+	}
+	if got := formatData(many, 1000, maxPreviewLen); !strings.HasSuffix(got, "(+10 more)") {
+		t.Errorf("formatData() with max_fields above the cap = %q, want %d fields and (+10 more)", got, maxPreviewFields)
+	}
+}
+
+func TestBoundPreview(t *testing.T) {
+	tests := []struct{ n, want int }{{-1, 5}, {0, 5}, {1, 2}, {3, 3}, {50, 9}}
+	for _, tt := range tests {
+		if got := boundPreview(tt.n, 5, 2, 9); got != tt.want {
+			t.Errorf("boundPreview(%d) = %d, want %d", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatOplogTable(t *testing.T) {
+	if got := formatOplogTable(nil, 0, 0); got != "No oplog entries." {
+		t.Errorf("empty oplog = %q", got)
+	}
+
+	wall := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	table := formatOplogTable([]oplogEntry{
+		{Op: "i", NS: "app.users", Wall: &wall, O: bson.M{"_id": 1, "name": "a|b", "age": 3}},
+	}, 1, 0)
+	want := `| 2024-01-02 03:04:05 | i | app.users | age=3 (+1 more) |`
+	if !strings.Contains(table, want) {
+		t.Errorf("table is missing %q:\n%s", want, table)
+	}
+
+	escaped := formatOplogTable([]oplogEntry{{Op: "i", NS: "app.users", O: bson.M{"name": "a|b"}}}, 0, 0)
+	if !strings.Contains(escaped, `name="a\|b"`) {
+		t.Errorf("a | in the data must be escaped:\n%s", escaped)
+	}
+}
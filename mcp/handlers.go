@@ -3,6 +3,7 @@ package mcp
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,45 +11,91 @@ import (
 	"time"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
 	"github.com/drewjocham/mongo-migration-tool/internal/parser"
+	"github.com/drewjocham/mongo-migration-tool/internal/schema"
+	"github.com/drewjocham/mongo-migration-tool/internal/ui"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 func (s *MCPServer) registerTools() {
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "migration_status",
 		Description: "Check applied and pending migrations.",
+		InputSchema: inputSchema[statusArgs](map[string]any{"verbose": true}),
 	}, s.handleStatus)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name:        "migration_list",
+		Description: "List the registered migrations, sorted by version, without connecting to the database.",
+		InputSchema: inputSchema[listArgs](nil),
+	}, s.handleList)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "migration_up",
-		Description: "Apply pending migrations.",
+		Description: "Apply pending migrations, up to and including version when given.",
+		InputSchema: inputSchema[versionArgs](map[string]any{"version": "20240101_001"}),
 	}, s.handleUp)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "migration_down",
-		Description: "Roll back migrations.",
+		Description: "Roll back applied migrations, down to and including version when given.",
+		InputSchema: inputSchema[versionArgs](map[string]any{"version": "20240101_001"}),
 	}, s.handleDown)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
-		Name:        "migration_create",
-		Description: "Generate a new migration file.",
+		Name: "migration_create",
+		Description: "Generate a new migration file in the migrations directory and return its absolute path. " +
+			"With dry_run, only report the path.",
+		InputSchema: inputSchema[createMigrationArgs](map[string]any{
+			"name":        "add_user_email_index",
+			"description": "Add a unique index on users.email",
+		}),
 	}, s.handleCreate)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "database_schema",
-		Description: "View collections and indexes.",
+		Description: "View collections and indexes, optionally filtered by collection name or regex pattern.",
+		InputSchema: inputSchema[schemaArgs](map[string]any{
+			"collection":      []string{"users"},
+			"pattern":         "^audit_",
+			"max_collections": 20,
+		}),
 	}, s.handleSchema)
 
+	mcp.AddTool(s.mcpServer, &mcp.Tool{
+		Name: "database_oplog",
+		Description: "Show the most recent oplog entries, newest first, with a preview of each document. " +
+			"Needs a replica set member.",
+		InputSchema: inputSchema[oplogArgs](map[string]any{
+			"namespace":  "app.users",
+			"limit":      20,
+			"max_fields": 5,
+			"max_len":    80,
+		}),
+	}, s.handleOplog)
+
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "parse_payload",
 		Description: "Parse JSON or BSON payload into normalized JSON.",
+		InputSchema: inputSchema[parsePayloadArgs](map[string]any{
+			"payload": `{"name":"Ada"}`,
+			"format":  "json",
+		}),
 	}, s.handleParsePayload)
 
 	mcp.AddTool(s.mcpServer, &mcp.Tool{
 		Name:        "validate_payload",
 		Description: "Parse and validate payload using registered types.",
+		InputSchema: inputSchema[parsePayloadArgs](map[string]any{
+			"payload":   `{"kind":"user","name":"Ada"}`,
+			"format":    "json",
+			"typeField": "kind",
+			"type":      "user",
+		}),
 	}, s.handleValidatePayload)
 }
 
@@ -60,76 +107,265 @@ func newMessageResult(text string) (*mcp.CallToolResult, messageOutput) {
 	}, messageOutput{Message: text}
 }
 
+// newErrorResult reports err as a failed tool result instead of a protocol error, so the
+// client gets the message along with a machine-readable error_code and, when a specific
+// migration failed, its version.
+func newErrorResult(err error) (*mcp.CallToolResult, messageOutput, error) {
+	out := messageOutput{Message: err.Error(), ErrorCode: errorCode(err)}
+	var migErr *migration.MigrationError
+	if errors.As(err, &migErr) {
+		out.Version = migErr.Version
+	}
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: ui.Fail.String() + " " + out.Message},
+		},
+	}, out, nil
+}
+
 func (s *MCPServer) handleStatus(
-	ctx context.Context, _ *mcp.CallToolRequest, _ emptyArgs,
+	ctx context.Context, _ *mcp.CallToolRequest, args statusArgs,
 ) (*mcp.CallToolResult, messageOutput, error) {
-	if err := s.ensureConnection(ctx); err != nil {
-		return nil, messageOutput{}, err
+	conn, err := s.ensureConnection(ctx)
+	if err != nil {
+		return newErrorResult(err)
+	}
+	defer conn.release()
+	engine := conn.engine
+	status, err := engine.GetStatus(ctx)
+	if err != nil {
+		return newErrorResult(err)
+	}
+	if !args.Verbose {
+		res, out := newMessageResult(formatStatusTable(status))
+		return res, out, nil
 	}
-	status, err := s.engine.GetStatus(ctx)
+
+	records, err := engine.ListApplied(ctx)
 	if err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(err)
 	}
-	res, out := newMessageResult(formatStatusTable(status))
+	res, out := newMessageResult(formatVerboseStatusTable(status, records))
+	return res, out, nil
+}
+
+func (s *MCPServer) handleList(
+	_ context.Context, _ *mcp.CallToolRequest, _ listArgs,
+) (*mcp.CallToolResult, messageOutput, error) {
+	catalog := migration.Catalog(migration.RegisteredMigrations())
+	res, out := newMessageResult(formatCatalogTable(catalog))
+	out.Migrations = catalog
 	return res, out, nil
 }
 
 func (s *MCPServer) handleUp(
 	ctx context.Context, _ *mcp.CallToolRequest, args versionArgs,
 ) (*mcp.CallToolResult, messageOutput, error) {
-	if err := s.ensureConnection(ctx); err != nil {
-		return nil, messageOutput{}, err
-	}
-	if err := s.engine.Up(ctx, args.Version); err != nil {
-		return nil, messageOutput{}, fmt.Errorf("migration up failed: %w", err)
+	conn, err := s.ensureConnection(ctx)
+	if err != nil {
+		return newErrorResult(err)
 	}
-	res, out := newMessageResult("✅ Migrations applied successfully.")
-	return res, out, nil
+	defer conn.release()
+	return runMigrations(ctx, conn.engine, migration.DirectionUp, args.Version)
 }
 
 func (s *MCPServer) handleDown(
 	ctx context.Context, _ *mcp.CallToolRequest, args versionArgs,
 ) (*mcp.CallToolResult, messageOutput, error) {
-	if err := s.ensureConnection(ctx); err != nil {
-		return nil, messageOutput{}, err
+	conn, err := s.ensureConnection(ctx)
+	if err != nil {
+		return newErrorResult(err)
 	}
-	if err := s.engine.Down(ctx, args.Version); err != nil {
-		return nil, messageOutput{}, fmt.Errorf("migration down failed: %w", err)
+	defer conn.release()
+	return runMigrations(ctx, conn.engine, migration.DirectionDown, args.Version)
+}
+
+// runMigrations runs in dir and reports the migrations it applied or rolled back, with
+// the elapsed time.
+func runMigrations(
+	ctx context.Context, engine *migration.Engine, dir migration.Direction, target string,
+) (*mcp.CallToolResult, messageOutput, error) {
+	start := time.Now()
+	var (
+		res migration.RunResult
+		err error
+	)
+	if dir == migration.DirectionDown {
+		res, err = engine.DownWithResult(ctx, target)
+	} else {
+		res, err = engine.UpWithResult(ctx, target)
 	}
-	res, out := newMessageResult("✅ Rollback completed successfully.")
+	return runResult(res, time.Since(start), err)
+}
+
+func runResult(
+	run migration.RunResult, elapsed time.Duration, err error,
+) (*mcp.CallToolResult, messageOutput, error) {
+	if err != nil {
+		res, out, _ := newErrorResult(fmt.Errorf("migration %s failed: %w", run.Direction, err))
+		if len(run.Executed) > 0 {
+			res.Content = append(res.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("Completed before the failure: %s", strings.Join(run.Executed, ", ")),
+			})
+		}
+		out.Versions = run.Executed
+		out.Skipped = run.Skipped
+		out.DurationMS = elapsed.Milliseconds()
+		return res, out, nil
+	}
+	res, out := newMessageResult(formatRunReport(run, elapsed))
+	out.Versions = run.Executed
+	out.Skipped = run.Skipped
+	out.DurationMS = elapsed.Milliseconds()
 	return res, out, nil
 }
 
 func (s *MCPServer) handleSchema(
-	ctx context.Context, _ *mcp.CallToolRequest, _ emptyArgs,
+	ctx context.Context, _ *mcp.CallToolRequest, args schemaArgs,
 ) (*mcp.CallToolResult, messageOutput, error) {
-	if err := s.ensureConnection(ctx); err != nil {
-		return nil, messageOutput{}, err
+	filter, err := schema.NewCollectionFilter(args.Collection, args.Pattern)
+	if err != nil {
+		return newErrorResult(fmt.Errorf("%w: %w", ErrInvalidArguments, err))
 	}
-	collections, err := s.db.ListCollectionNames(ctx, bson.D{})
+	conn, err := s.ensureConnection(ctx)
 	if err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(err)
+	}
+	defer conn.release()
+	db := conn.db
+	collections, err := db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return newErrorResult(err)
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, schemaWalkTimeout)
+	defer cancel()
+
 	var b strings.Builder
-	fmt.Fprintf(&b, "### Database Schema: `%s`\n\n", s.db.Name())
-	for _, name := range collections {
-		s.appendCollectionSchema(&b, ctx, name)
+	fmt.Fprintf(&b, "### Database Schema: `%s`\n\n", db.Name())
+	appendOne := func(b *strings.Builder, ctx context.Context, name string) {
+		appendCollectionSchema(b, ctx, db, name)
 	}
+	truncated := walkSchema(ctx, &b, filter.Collections(collections), args.MaxCollections, appendOne)
 	res, out := newMessageResult(b.String())
+	out.Truncated = truncated
 	return res, out, nil
 }
 
+const (
+	defaultSchemaCollections = 50
+	maxSchemaCollections     = 500
+	schemaWalkTimeout        = 30 * time.Second
+)
+
+// walkSchema appends each collection's schema until limit collections are written or ctx
+// is done, so a huge database cannot hold up the tool call. It ends with a note and
+// reports true when it stopped early. A limit of zero uses the default; larger limits
+// are capped.
+func walkSchema(
+	ctx context.Context, b *strings.Builder, names []string, limit int,
+	appendOne func(*strings.Builder, context.Context, string),
+) bool {
+	if limit <= 0 {
+		limit = defaultSchemaCollections
+	}
+	limit = min(limit, maxSchemaCollections)
+
+	for i, name := range names {
+		if i == limit {
+			fmt.Fprintf(b, "*Truncated: showing %d of %d collections. "+
+				"Narrow with collection or pattern, or raise max_collections.*\n", i, len(names))
+			return true
+		}
+		if err := ctx.Err(); err != nil {
+			fmt.Fprintf(b, "*Truncated: stopped after %d of %d collections: %v.*\n", i, len(names), err)
+			return true
+		}
+		appendOne(b, ctx, name)
+	}
+	return false
+}
+
+const (
+	defaultOplogEntries = 20
+	maxOplogEntries     = 200
+)
+
+// oplogEntry is the part of an oplog document database_oplog shows.
+type oplogEntry struct {
+	TS   bson.Timestamp `bson:"ts"`
+	Op   string         `bson:"op"`
+	NS   string         `bson:"ns"`
+	Wall *time.Time     `bson:"wall,omitempty"`
+	O    bson.M         `bson:"o"`
+}
+
+func (s *MCPServer) handleOplog(
+	ctx context.Context, _ *mcp.CallToolRequest, args oplogArgs,
+) (*mcp.CallToolResult, messageOutput, error) {
+	filter := bson.D{}
+	if args.Namespace != "" {
+		db, coll, ok := strings.Cut(args.Namespace, ".")
+		if !ok || db == "" || coll == "" {
+			return newErrorResult(fmt.Errorf("%w: namespace %q must be db.collection", ErrInvalidArguments, args.Namespace))
+		}
+		filter = bson.D{{Key: "ns", Value: args.Namespace}}
+	}
+	limit := boundPreview(args.Limit, defaultOplogEntries, 1, maxOplogEntries)
+
+	conn, err := s.ensureConnection(ctx)
+	if err != nil {
+		return newErrorResult(err)
+	}
+	defer conn.release()
+
+	local := conn.client.Database("local")
+	names, err := local.ListCollectionNames(ctx, bson.D{{Key: "name", Value: "oplog.rs"}})
+	if err != nil {
+		return newErrorResult(err)
+	}
+	if len(names) == 0 {
+		return newErrorResult(fmt.Errorf("no oplog: the server is not a replica set member"))
+	}
+	cur, err := local.Collection("oplog.rs").Find(ctx, filter,
+		options.Find().SetSort(bson.D{{Key: "$natural", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return newErrorResult(err)
+	}
+	var entries []oplogEntry
+	if err := cur.All(ctx, &entries); err != nil {
+		return newErrorResult(err)
+	}
+
+	res, out := newMessageResult(formatOplogTable(entries, args.MaxFields, args.MaxLen))
+	return res, out, nil
+}
 
 func (s *MCPServer) handleCreate(
 	ctx context.Context, _ *mcp.CallToolRequest, args createMigrationArgs,
 ) (*mcp.CallToolResult, messageOutput, error) {
 	version := time.Now().Format("20060102_150405")
-	slug := strings.ToLower(strings.ReplaceAll(args.Name, " ", "_"))
-	path := filepath.Join("migrations", fmt.Sprintf("%s_%s.go", version, slug))
+	slug, err := migrationSlug(args.Name)
+	if err != nil {
+		return newErrorResult(err)
+	}
+	// Report absolute paths: a relative MIGRATIONS_PATH resolves against the server's
+	// working directory, which the client cannot see.
+	dir, err := filepath.Abs(s.migrationsDir())
+	if err != nil {
+		return newErrorResult(err)
+	}
+	name := fmt.Sprintf("%s_%s.go", version, slug)
+	path := filepath.Join(dir, name)
 
-	if err := os.MkdirAll("migrations", 0750); err != nil {
-		return nil, messageOutput{}, err
+	if args.DryRun {
+		res, out := newMessageResult(fmt.Sprintf("Would create `%s` in the migrations directory `%s`.", path, dir))
+		out.Path = path
+		return res, out, nil
+	}
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return newErrorResult(err)
 	}
 
 	var buf bytes.Buffer
@@ -140,21 +376,40 @@ func (s *MCPServer) handleCreate(
 	}
 
 	if err := migrationTemplate.Execute(&buf, data); err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(err)
 	}
 
-	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
-		return nil, messageOutput{}, err
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return newErrorResult(err)
 	}
+	defer root.Close()
+	if err := root.WriteFile(name, buf.Bytes(), 0600); err != nil {
+		return newErrorResult(err)
+	}
+	s.addMigrationResource(name)
 
-	res, out := newMessageResult(fmt.Sprintf("🚀 Created migration: `%s`", path))
+	res, out := newMessageResult(fmt.Sprintf("%s Created migration `%s` in the migrations directory `%s`",
+		ui.Created, path, dir))
+	out.Path = path
 	return res, out, nil
 }
 
-func (s *MCPServer) appendCollectionSchema(b *strings.Builder, ctx context.Context, name string) {
+// migrationSlug turns a migration name into the file name part. Anything that could leave
+// the migrations directory, such as a path separator or "..", is refused.
+func migrationSlug(name string) (string, error) {
+	slug := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", "_"))
+	if slug == "" || strings.ContainsAny(slug, `/\`) || strings.Contains(slug, "..") || !filepath.IsLocal(slug) {
+		return "", fmt.Errorf("%w: name %q must be a plain name without path separators or ..",
+			ErrInvalidArguments, name)
+	}
+	return slug, nil
+}
+
+func appendCollectionSchema(b *strings.Builder, ctx context.Context, db *mongo.Database, name string) {
 	fmt.Fprintf(b, "#### Collection: `%s`\n\n| Index Name | Keys | Unique |\n| :--- | :--- | :--- |\n", name)
 
-	cursor, err := s.db.Collection(name).Indexes().List(ctx)
+	cursor, err := db.Collection(name).Indexes().List(ctx)
 	if err != nil {
 		fmt.Fprintf(b, "| *Error: %v* | | |\n\n", err)
 		return
@@ -186,17 +441,17 @@ func (s *MCPServer) handleParsePayload(
 
 	raw, err := parser.DecodePayload(args.Payload, parser.Format(format))
 	if err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(fmt.Errorf("%w: %w", ErrInvalidArguments, err))
 	}
 
 	parsed, err := parser.ParseMap(raw, parser.WithFormat(parser.Format(format)))
 	if err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(fmt.Errorf("%w: %w", ErrInvalidArguments, err))
 	}
 
 	outBytes, err := jsonutil.MarshalIndent(parsed, "", "  ")
 	if err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(err)
 	}
 
 	res, out := newMessageResult(string(outBytes))
@@ -213,24 +468,24 @@ func (s *MCPServer) handleValidatePayload(
 
 	raw, err := parser.DecodePayload(args.Payload, parser.Format(format))
 	if err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(fmt.Errorf("%w: %w", ErrInvalidArguments, err))
 	}
 
 	if args.TypeName == "" && args.TypeField == "" {
-		return nil, messageOutput{}, fmt.Errorf("type or typeField is required")
+		return newErrorResult(fmt.Errorf("%w: type or typeField is required", ErrInvalidArguments))
 	}
 
 	if args.TypeName != "" {
 		ctor := parser.DefaultRegistry[strings.ToLower(args.TypeName)]
 		if ctor == nil {
-			return nil, messageOutput{}, fmt.Errorf("no registered type: %s", args.TypeName)
+			return newErrorResult(fmt.Errorf("%w: no registered type: %s", ErrInvalidArguments, args.TypeName))
 		}
 		instance := ctor()
 		if err := parser.ParseInto(raw, instance,
 			parser.WithFormat(parser.Format(format)),
 			parser.WithValidation(true),
 		); err != nil {
-			return nil, messageOutput{}, err
+			return newErrorResult(fmt.Errorf("%w: %w", ErrInvalidArguments, err))
 		}
 		res, out := newMessageResult("valid")
 		return res, out, nil
@@ -240,7 +495,7 @@ func (s *MCPServer) handleValidatePayload(
 		parser.WithFormat(parser.Format(format)),
 		parser.WithValidation(true),
 	); err != nil {
-		return nil, messageOutput{}, err
+		return newErrorResult(fmt.Errorf("%w: %w", ErrInvalidArguments, err))
 	}
 	res, out := newMessageResult("valid")
 	return res, out, nil
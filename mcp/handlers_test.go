@@ -0,0 +1,346 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/jsonutil"
+	"github.com/drewjocham/mongo-migration-tool/internal/migration"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestNewErrorResultFromFailedMigration(t *testing.T) {
+	cause := &migration.MigrationError{
+		Version:   "20240101_001",
+		Direction: migration.DirectionUp,
+		Err:       errors.New("index build failed"),
+	}
+	res, out, err := newErrorResult(fmt.Errorf("migration up failed: %w", cause))
+	if err != nil {
+		t.Fatalf("newErrorResult returned a protocol error: %v", err)
+	}
+	if !res.IsError {
+		t.Error("expected IsError to be set")
+	}
+	if out.ErrorCode != codeMigrationFailed {
+		t.Errorf("error_code = %q, want %q", out.ErrorCode, codeMigrationFailed)
+	}
+	if out.Version != "20240101_001" {
+		t.Errorf("version = %q, want %q", out.Version, "20240101_001")
+	}
+	if out.Message == "" {
+		t.Error("expected a message")
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "Checksum mismatch wins over migration failure",
+			err: &migration.MigrationError{
+				Version: "20240101_001",
+				Err:     fmt.Errorf("%w for 20240101_001", migration.ErrChecksumMismatch),
+			},
+			want: codeChecksumMismatch,
+		},
+		{name: "Lock held", err: migration.ErrFailedToLock, want: codeLockUnavailable},
+		{name: "Lock lost", err: fmt.Errorf("wrapped: %w", migration.ErrLockLost), want: codeLockLost},
+		{name: "Read only", err: migration.ErrReadOnly, want: codeReadOnly},
+		{name: "Wrong database", err: fmt.Errorf("%w: expected", migration.ErrWrongDatabase), want: codeWrongDatabase},
+		{name: "Unknown version", err: fmt.Errorf("%w: x", migration.ErrMigrationNotFound), want: codeMigrationNotFound},
+		{name: "Invalid arguments", err: fmt.Errorf("%w: bad", ErrInvalidArguments), want: codeInvalidArguments},
+		{name: "Anything else", err: errors.New("boom"), want: codeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.err); got != tt.want {
+				t.Errorf("errorCode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleValidatePayloadInvalidArguments(t *testing.T) {
+	s := &MCPServer{}
+	res, out, err := s.handleValidatePayload(context.Background(), nil, parsePayloadArgs{Payload: `{"a":1}`})
+	if err != nil {
+		t.Fatalf("unexpected protocol error: %v", err)
+	}
+	if !res.IsError || out.ErrorCode != codeInvalidArguments {
+		t.Errorf("got IsError=%v error_code=%q, want invalid_arguments", res.IsError, out.ErrorCode)
+	}
+	if out.Version != "" {
+		t.Errorf("version = %q, want empty", out.Version)
+	}
+}
+
+func TestToolsListInputSchemas(t *testing.T) {
+	ctx := context.Background()
+	srv, err := NewMCPServer(&config.Config{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	res, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+
+	type property struct {
+		Type        string `json:"type"`
+		Description string `json:"description"`
+		Examples    []any  `json:"examples"`
+	}
+	schemas := make(map[string]struct {
+		Type       string              `json:"type"`
+		Required   []string            `json:"required"`
+		Properties map[string]property `json:"properties"`
+	})
+	for _, tool := range res.Tools {
+		raw, err := jsonutil.Marshal(tool.InputSchema)
+		if err != nil {
+			t.Fatalf("marshal schema for %s: %v", tool.Name, err)
+		}
+		s := schemas[tool.Name]
+		if err := jsonutil.Unmarshal(raw, &s); err != nil {
+			t.Fatalf("decode schema for %s: %v", tool.Name, err)
+		}
+		if s.Type != "object" {
+			t.Errorf("%s: schema type = %q, want object", tool.Name, s.Type)
+		}
+		for name, prop := range s.Properties {
+			if prop.Description == "" {
+				t.Errorf("%s: property %q has no description", tool.Name, name)
+			}
+		}
+		schemas[tool.Name] = s
+	}
+
+	create, ok := schemas["migration_create"]
+	if !ok {
+		t.Fatal("migration_create not listed")
+	}
+	for _, field := range []string{"name", "description"} {
+		if !slices.Contains(create.Required, field) {
+			t.Errorf("migration_create: %q is not required (required=%v)", field, create.Required)
+		}
+		prop := create.Properties[field]
+		if prop.Type != "string" || len(prop.Examples) == 0 {
+			t.Errorf("migration_create: property %q = %+v, want a string with an example", field, prop)
+		}
+	}
+}
+
+func TestHandleCreate(t *testing.T) {
+	ctx := context.Background()
+	cwd := t.TempDir()
+	t.Chdir(cwd)
+	srv, err := NewMCPServer(&config.Config{MigrationsPath: "migrations"}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+	dir := filepath.Join(cwd, "migrations")
+
+	t.Run("Absolute path", func(t *testing.T) {
+		res, out, err := srv.handleCreate(ctx, nil, createMigrationArgs{Name: "Add Index", Description: "d"})
+		if err != nil || res.IsError {
+			t.Fatalf("handleCreate() = %+v, %v, want success", out, err)
+		}
+		if !filepath.IsAbs(out.Path) || filepath.Dir(out.Path) != dir || !strings.HasSuffix(out.Path, "_add_index.go") {
+			t.Errorf("path = %q, want an absolute path in %s", out.Path, dir)
+		}
+		if !strings.Contains(out.Message, out.Path) || !strings.Contains(out.Message, "`"+dir+"`") {
+			t.Errorf("message %q should name the file and the migrations directory", out.Message)
+		}
+		if _, err := os.Stat(out.Path); err != nil {
+			t.Errorf("file not written: %v", err)
+		}
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		_, out, _ := srv.handleCreate(ctx, nil, createMigrationArgs{Name: "preview", Description: "d", DryRun: true})
+		if filepath.Dir(out.Path) != dir || !strings.HasPrefix(out.Message, "Would create") {
+			t.Errorf("dry run = %+v, want the path it would write", out)
+		}
+		if _, err := os.Stat(out.Path); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("dry run wrote %s: %v", out.Path, err)
+		}
+	})
+
+	for _, name := range []string{"../escape", "nested/name", `..\escape`, "..", "  "} {
+		t.Run("Rejects "+name, func(t *testing.T) {
+			res, out, err := srv.handleCreate(ctx, nil, createMigrationArgs{Name: name, Description: "d"})
+			if err != nil {
+				t.Fatalf("handleCreate returned a protocol error: %v", err)
+			}
+			if !res.IsError || out.ErrorCode != codeInvalidArguments || out.Path != "" {
+				t.Errorf("handleCreate(%q) = %+v, want invalid_arguments", name, out)
+			}
+		})
+	}
+	if entries, _ := os.ReadDir(cwd); len(entries) != 1 {
+		t.Errorf("files outside the migrations directory: %v", entries)
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	t.Run("Applied some", func(t *testing.T) {
+		run := migration.RunResult{
+			Direction: migration.DirectionUp,
+			Pending:   3,
+			Executed:  []string{"20240101_001", "20240102_001"},
+			Skipped:   []string{"20240103_001"},
+		}
+		res, out, err := runResult(run, 1500*time.Millisecond, nil)
+		if err != nil || res.IsError {
+			t.Fatalf("runResult() = %+v, %v, want success", res, err)
+		}
+		for _, want := range []string{
+			"Applied 2 migration(s) in 1.5s", "`20240101_001`", "`20240102_001`",
+			"1 left pending for other environments", "`20240103_001`",
+		} {
+			if !strings.Contains(out.Message, want) {
+				t.Errorf("message %q is missing %q", out.Message, want)
+			}
+		}
+		if len(out.Versions) != 2 || len(out.Skipped) != 1 || out.DurationMS != 1500 {
+			t.Errorf("versions, skipped, duration_ms = %v, %v, %d, want 2 versions, 1 skipped and 1500",
+				out.Versions, out.Skipped, out.DurationMS)
+		}
+	})
+
+	t.Run("Nothing pending", func(t *testing.T) {
+		_, out, _ := runResult(migration.RunResult{Direction: migration.DirectionUp}, time.Millisecond, nil)
+		if out.Message != "Already up to date (0 pending)." {
+			t.Errorf("message = %q, want %q", out.Message, "Already up to date (0 pending).")
+		}
+		_, out, _ = runResult(migration.RunResult{Direction: migration.DirectionDown}, time.Millisecond, nil)
+		if out.Message != "No applied migrations to roll back." {
+			t.Errorf("down message = %q", out.Message)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		cause := &migration.MigrationError{
+			Version: "20240102_001", Direction: migration.DirectionDown, Err: errors.New("boom"),
+		}
+		run := migration.RunResult{Direction: migration.DirectionDown, Pending: 1}
+		res, out, err := runResult(run, time.Second, cause)
+		if err != nil {
+			t.Fatalf("runResult returned a protocol error: %v", err)
+		}
+		if !res.IsError || out.ErrorCode != codeMigrationFailed || out.Version != "20240102_001" {
+			t.Errorf("runResult() = %+v, want a failed result for 20240102_001", out)
+		}
+		if !strings.HasPrefix(out.Message, "migration down failed") || len(out.Versions) != 0 {
+			t.Errorf("message, versions = %q, %v", out.Message, out.Versions)
+		}
+	})
+
+	t.Run("Error after applying some", func(t *testing.T) {
+		cause := &migration.MigrationError{
+			Version: "20240102_001", Direction: migration.DirectionUp, Err: errors.New("boom"),
+		}
+		run := migration.RunResult{Direction: migration.DirectionUp, Pending: 2, Executed: []string{"20240101_001"}}
+		res, out, _ := runResult(run, time.Second, cause)
+		if !res.IsError || out.Version != "20240102_001" {
+			t.Errorf("runResult() = %+v, want a failed result for 20240102_001", out)
+		}
+		if len(out.Versions) != 1 || out.Versions[0] != "20240101_001" {
+			t.Errorf("versions = %v, want the migration applied before the failure", out.Versions)
+		}
+		last, _ := res.Content[len(res.Content)-1].(*mcp.TextContent)
+		if last == nil || !strings.Contains(last.Text, "20240101_001") {
+			t.Errorf("content does not list the applied migration: %+v", res.Content)
+		}
+	})
+}
+
+func TestWalkSchemaTruncates(t *testing.T) {
+	names := make([]string, 120)
+	for i := range names {
+		names[i] = fmt.Sprintf("coll_%03d", i)
+	}
+	appendName := func(b *strings.Builder, _ context.Context, name string) {
+		fmt.Fprintf(b, "#### Collection: `%s`\n", name)
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		wantCount int
+	}{
+		{name: "Default limit", limit: 0, wantCount: defaultSchemaCollections},
+		{name: "Explicit limit", limit: 7, wantCount: 7},
+		{name: "Limit above collection count", limit: 200, wantCount: 120},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			truncated := walkSchema(context.Background(), &b, names, tt.limit, appendName)
+			if got := strings.Count(b.String(), "#### Collection:"); got != tt.wantCount {
+				t.Errorf("described %d collections, want %d", got, tt.wantCount)
+			}
+			wantTruncated := tt.wantCount < len(names)
+			if truncated != wantTruncated || strings.Contains(b.String(), "*Truncated") != wantTruncated {
+				t.Errorf("truncated = %v, want %v\n%s", truncated, wantTruncated, b.String())
+			}
+		})
+	}
+
+	t.Run("Cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var b strings.Builder
+		calls := 0
+		truncated := walkSchema(ctx, &b, names, 100, func(b *strings.Builder, ctx context.Context, name string) {
+			appendName(b, ctx, name)
+			if calls++; calls == 3 {
+				cancel()
+			}
+		})
+		if !truncated || !strings.Contains(b.String(), "stopped after 3 of 120 collections") {
+			t.Errorf("truncated = %v, output:\n%s", truncated, b.String())
+		}
+	})
+}
+
+func TestHandleOplogRejectsBadNamespace(t *testing.T) {
+	srv, err := NewMCPServer(&config.Config{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+	for _, ns := range []string{"users", ".users", "app."} {
+		res, out, err := srv.handleOplog(context.Background(), nil, oplogArgs{Namespace: ns})
+		if err != nil {
+			t.Fatalf("handleOplog returned a protocol error: %v", err)
+		}
+		if !res.IsError || out.ErrorCode != codeInvalidArguments {
+			t.Errorf("namespace %q: error_code = %q, want %s", ns, out.ErrorCode, codeInvalidArguments)
+		}
+	}
+}
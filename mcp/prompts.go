@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const promptAuthorMigration = "author_migration"
+
+func (s *MCPServer) registerPrompts() {
+	s.mcpServer.AddPrompt(&mcp.Prompt{
+		Name:        promptAuthorMigration,
+		Description: "Guide writing a new migration for one collection using this project's conventions.",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "goal", Description: "What the migration should achieve, e.g. add a unique index on email.", Required: true},
+			{Name: "collection", Description: "Collection the migration changes, e.g. users.", Required: true},
+		},
+	}, s.handleAuthorMigration)
+}
+
+// handleAuthorMigration renders the author_migration prompt. Unlike tool calls, a bad
+// prompt request is a protocol error: there is no result to carry it.
+func (s *MCPServer) handleAuthorMigration(
+	_ context.Context, req *mcp.GetPromptRequest,
+) (*mcp.GetPromptResult, error) {
+	data := authorMigrationData{
+		Goal:       strings.TrimSpace(req.Params.Arguments["goal"]),
+		Collection: strings.TrimSpace(req.Params.Arguments["collection"]),
+	}
+	if data.Goal == "" || data.Collection == "" {
+		return nil, fmt.Errorf("%w: goal and collection are required", ErrInvalidArguments)
+	}
+
+	var b strings.Builder
+	if err := authorMigrationPrompt.Execute(&b, data); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrFailedToRenderTemplate, err)
+	}
+	return &mcp.GetPromptResult{
+		Description: "Author a migration on " + data.Collection + ": " + data.Goal,
+		Messages: []*mcp.PromptMessage{
+			{Role: "user", Content: &mcp.TextContent{Text: b.String()}},
+		},
+	}, nil
+}
@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAuthorMigrationPrompt(t *testing.T) {
+	ctx := context.Background()
+	srv, err := NewMCPServer(&config.Config{}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	list, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("prompts/list: %v", err)
+	}
+	var prompt *mcp.Prompt
+	for _, p := range list.Prompts {
+		if p.Name == promptAuthorMigration {
+			prompt = p
+		}
+	}
+	if prompt == nil {
+		t.Fatalf("%s not listed", promptAuthorMigration)
+	}
+	for i, want := range []string{"goal", "collection"} {
+		if arg := prompt.Arguments[i]; arg.Name != want || !arg.Required || arg.Description == "" {
+			t.Errorf("argument %d = %+v, want a described, required %q", i, arg, want)
+		}
+	}
+
+	res, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      promptAuthorMigration,
+		Arguments: map[string]string{"goal": "add a unique index on email", "collection": "users"},
+	})
+	if err != nil {
+		t.Fatalf("prompts/get: %v", err)
+	}
+	if len(res.Messages) != 1 || res.Messages[0].Role != "user" {
+		t.Fatalf("messages = %+v, want one user message", res.Messages)
+	}
+	text, ok := res.Messages[0].Content.(*mcp.TextContent)
+	if !ok {
+		t.Fatalf("content = %T, want text", res.Messages[0].Content)
+	}
+	wants := []string{"Goal: add a unique index on email", "Collection: `users`", `["users"]`, "`migration_create`"}
+	for _, want := range wants {
+		if !strings.Contains(text.Text, want) {
+			t.Errorf("prompt does not contain %q:\n%s", want, text.Text)
+		}
+	}
+
+	_, err = session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      promptAuthorMigration,
+		Arguments: map[string]string{"collection": "users"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "goal and collection are required") {
+		t.Errorf("prompts/get without goal = %v, want an invalid arguments error", err)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	migrationURIScheme = "migrations"
+	migrationURIPrefix = migrationURIScheme + ":///"
+	goSourceMIMEType   = "text/x-go"
+)
+
+// registerResources lists every migration source file in the migrations directory as a
+// resource. The template lets clients read files created after startup as well.
+func (s *MCPServer) registerResources() {
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "migration_file",
+		URITemplate: migrationURIPrefix + "{name}",
+		Description: "Go source of a migration file in the migrations directory.",
+		MIMEType:    goSourceMIMEType,
+	}, s.handleReadMigration)
+
+	entries, err := os.ReadDir(s.migrationsDir())
+	if err != nil {
+		s.logger.Debug("migrations directory not listed as resources", "path", s.migrationsDir(), "error", err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && isMigrationSource(entry.Name()) {
+			s.addMigrationResource(entry.Name())
+		}
+	}
+}
+
+func (s *MCPServer) addMigrationResource(name string) {
+	s.mcpServer.AddResource(&mcp.Resource{
+		Name:        name,
+		URI:         migrationURIPrefix + url.PathEscape(name),
+		Description: "Migration source file " + name,
+		MIMEType:    goSourceMIMEType,
+	}, s.handleReadMigration)
+}
+
+// handleReadMigration returns the contents of one migration file. The file is opened
+// through an os.Root on the migrations directory, so neither ".." nor a symlink can reach
+// anything outside it.
+func (s *MCPServer) handleReadMigration(
+	_ context.Context, req *mcp.ReadResourceRequest,
+) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	name, err := migrationFileName(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := os.OpenRoot(s.migrationsDir())
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	defer root.Close()
+
+	data, err := root.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: goSourceMIMEType, Text: string(data)}},
+	}, nil
+}
+
+// migrationFileName extracts the file name from a migrations:/// URI. Only a plain .go
+// file name directly inside the migrations directory is accepted.
+func migrationFileName(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != migrationURIScheme {
+		return "", fmt.Errorf("%w: %q is not a %s URI", ErrInvalidArguments, uri, migrationURIPrefix)
+	}
+	name := strings.TrimPrefix(u.Path, "/")
+	if !filepath.IsLocal(name) || strings.ContainsAny(name, `/\`) || !isMigrationSource(name) {
+		return "", fmt.Errorf("%w: %q is not a migration file in the migrations directory", ErrInvalidArguments, uri)
+	}
+	return name, nil
+}
+
+func isMigrationSource(name string) bool {
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go")
+}
+
+// migrationsDir is the configured migrations path, or "migrations" when none is set.
+func (s *MCPServer) migrationsDir() string {
+	if s.config.MigrationsPath == "" {
+		return "migrations"
+	}
+	return s.config.MigrationsPath
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestMigrationResources(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	dir := filepath.Join(base, "migrations")
+	files := map[string]string{
+		"20240101_001_add_index.go":      "package migrations\n",
+		"20240101_001_add_index_test.go": "package migrations\n",
+		"README.md":                      "notes\n",
+	}
+	if err := os.Mkdir(dir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(base, "secret.go"), []byte("package secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv, err := NewMCPServer(&config.Config{MigrationsPath: dir}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := srv.mcpServer.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect: %v", err)
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect: %v", err)
+	}
+	defer session.Close()
+
+	list, err := session.ListResources(ctx, nil)
+	if err != nil {
+		t.Fatalf("resources/list: %v", err)
+	}
+	if len(list.Resources) != 1 || list.Resources[0].URI != "migrations:///20240101_001_add_index.go" {
+		for _, r := range list.Resources {
+			t.Logf("listed %s", r.URI)
+		}
+		t.Fatalf("listed %d resources, want only the migration source file", len(list.Resources))
+	}
+
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: list.Resources[0].URI})
+	if err != nil {
+		t.Fatalf("resources/read: %v", err)
+	}
+	if len(res.Contents) != 1 || res.Contents[0].Text != "package migrations\n" {
+		t.Fatalf("contents = %+v, want the file's source", res.Contents)
+	}
+	if res.Contents[0].MIMEType != goSourceMIMEType {
+		t.Errorf("mimeType = %q, want %q", res.Contents[0].MIMEType, goSourceMIMEType)
+	}
+
+	for _, uri := range []string{
+		"migrations:///..%2Fsecret.go",
+		"migrations:///../secret.go",
+		"migrations:///20240101_001_add_index_test.go",
+		"migrations:///missing.go",
+	} {
+		if res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri}); err == nil {
+			t.Errorf("resources/read %s = %+v, want an error", uri, res.Contents)
+		}
+	}
+}
+
+func TestMigrationFileName(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: "migrations:///20240101_001_add_index.go", want: "20240101_001_add_index.go"},
+		{uri: "migrations:///..%2Fsecret.go"},
+		{uri: "migrations:///nested%2Fm.go"},
+		{uri: "migrations:///%2Fetc%2Fpasswd.go"},
+		{uri: "migrations:///notes.md"},
+		{uri: "file:///20240101_001_add_index.go"},
+	}
+	for _, tt := range tests {
+		got, err := migrationFileName(tt.uri)
+		if got != tt.want || (tt.want == "") != (err != nil) {
+			t.Errorf("migrationFileName(%q) = %q, %v; want %q", tt.uri, got, err, tt.want)
+		}
+	}
+}
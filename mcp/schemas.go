@@ -0,0 +1,25 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// inputSchema infers the JSON schema for a tool's argument type, including the field
+// descriptions from its jsonschema tags, and attaches one example value per property.
+// It panics on a schema error or an unknown property, like mcp.AddTool does for bad tools.
+func inputSchema[T any](examples map[string]any) *jsonschema.Schema {
+	s, err := jsonschema.For[T](nil)
+	if err != nil {
+		panic(fmt.Sprintf("mcp: input schema: %v", err))
+	}
+	for name, example := range examples {
+		prop, ok := s.Properties[name]
+		if !ok {
+			panic(fmt.Sprintf("mcp: input schema for %T has no property %q", *new(T), name))
+		}
+		prop.Examples = []any{example}
+	}
+	return s
+}
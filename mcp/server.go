@@ -10,25 +10,34 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"github.com/drewjocham/mongo-migration-tool/internal/dbconn"
 	"github.com/drewjocham/mongo-migration-tool/internal/migration"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"go.mongodb.org/mongo-driver/v2/mongo"
-	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 type MCPServer struct {
 	mu        sync.RWMutex
 	mcpServer *mcp.Server
-	engine    *migration.Engine
-	db        *mongo.Database
-	client    *mongo.Client
+	conn      *mongoConn
 	config    *config.Config
 	cancel    context.CancelFunc
 	logger    *slog.Logger
+
+	// dial, ping and disconnect are swapped in tests to simulate a dropped connection.
+	dial       func(ctx context.Context) (*mongo.Client, error)
+	ping       func(ctx context.Context, client *mongo.Client) error
+	disconnect func(ctx context.Context, client *mongo.Client) error
+
+	stopHealth context.CancelFunc
+	health     sync.WaitGroup
 }
 
+const healthPingTimeout = 5 * time.Second
+
 func NewMCPServer(cfg *config.Config, logger *slog.Logger) (*MCPServer, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is required")
@@ -43,33 +52,107 @@ func NewMCPServer(cfg *config.Config, logger *slog.Logger) (*MCPServer, error) {
 		mcpServer: s,
 		config:    cfg,
 		logger:    logger,
+		dial: func(ctx context.Context) (*mongo.Client, error) {
+			return dbconn.ConnectWithRetry(ctx, dbconn.ClientOptions(cfg), dbconn.PolicyFromConfig(cfg))
+		},
+		ping:       func(ctx context.Context, client *mongo.Client) error { return client.Ping(ctx, nil) },
+		disconnect: func(ctx context.Context, client *mongo.Client) error { return client.Disconnect(ctx) },
 	}
 
 	srv.registerTools()
+	srv.registerPrompts()
+	srv.registerResources()
 	return srv, nil
 }
 
-func (s *MCPServer) ensureConnection(ctx context.Context) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// mongoConn is a client with the database and engine built on it. Tool calls hold a
+// reference while they use it, so a reconnect disconnects the replaced client only once
+// the last call using it has released it.
+type mongoConn struct {
+	client *mongo.Client
+	db     *mongo.Database
+	engine *migration.Engine
+	refs   sync.WaitGroup
+}
+
+// release drops a reference taken by ensureConnection.
+func (c *mongoConn) release() { c.refs.Done() }
 
-	if s.client != nil {
-		if err := s.client.Ping(ctx, nil); err == nil {
-			return nil
+// ensureConnection returns the connection for a tool call, reconnecting first when the
+// current client no longer answers. The caller must release it when done. Calls share
+// the read lock; the ping and the dial run outside of it, so a reconnect does not hold up
+// calls on a healthy connection.
+func (s *MCPServer) ensureConnection(ctx context.Context) (*mongoConn, error) {
+	current := s.acquire()
+	if current != nil {
+		pCtx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+		err := s.ping(pCtx, current.client)
+		cancel()
+		if err == nil {
+			return current, nil
 		}
+		current.release()
+		s.logger.Warn("mongodb connection lost, reconnecting", "database", s.config.Database)
 	}
 
-	client, err := mongo.Connect(options.Client().ApplyURI(s.config.MongoURL))
+	fresh, err := s.connect(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to connect to mongodb: %w", err)
+		return nil, err
 	}
 
-	s.client = client
-	s.db = client.Database(s.config.Database)
-	s.engine = migration.NewEngine(s.db, s.config.MigrationsCollection, migration.RegisteredMigrations())
+	s.mu.Lock()
+	if s.conn != current && s.conn != nil {
+		// Another call reconnected while this one dialed; use its connection.
+		winner := s.conn
+		winner.refs.Add(1)
+		s.mu.Unlock()
+		_ = s.disconnect(context.Background(), fresh.client)
+		return winner, nil
+	}
+	replaced := s.conn
+	s.conn = fresh
+	fresh.refs.Add(1)
+	s.mu.Unlock()
 
+	if replaced != nil {
+		go func() {
+			replaced.refs.Wait()
+			_ = s.disconnect(context.Background(), replaced.client)
+		}()
+	}
 	s.logger.Info("connected to mongodb", "database", s.config.Database)
-	return nil
+	return fresh, nil
+}
+
+// acquire returns the current connection with a reference taken, or nil before the first
+// connect. References are only taken under s.mu while the connection is current, so none
+// is added once ensureConnection waits on a replaced one.
+func (s *MCPServer) acquire() *mongoConn {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.conn != nil {
+		s.conn.refs.Add(1)
+	}
+	return s.conn
+}
+
+// connect dials a new client and builds the database and engine on it.
+func (s *MCPServer) connect(ctx context.Context) (*mongoConn, error) {
+	opts, err := migration.OptionsFromConfig(s.config)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	db := client.Database(s.config.Database)
+	// Stdout carries the protocol, so progress goes to stderr with the server's logs.
+	engine := migration.NewEngine(db, s.config.MigrationsCollection, migration.RegisteredMigrations(),
+		append(opts, migration.WithProgress(os.Stderr))...)
+	return &mongoConn{client: client, db: db, engine: engine}, nil
 }
 
 func (s *MCPServer) Start() error {
@@ -95,17 +178,76 @@ func (s *MCPServer) Start() error {
 	return s.Serve(ctx, os.Stdin, os.Stdout)
 }
 
+// Serve runs the MCP session on r and w until it ends or ctx is done. With
+// MCP_HEALTH_INTERVAL set, a background check keeps the MongoDB connection warm and
+// reconnects between tool calls, so a dropped connection does not end the session.
 func (s *MCPServer) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.startHealthCheck(ctx, s.config.MCPHealthInterval)
+	defer s.stopHealthCheck()
+
 	return s.mcpServer.Run(ctx, &mcp.IOTransport{
 		Reader: io.NopCloser(r),
 		Writer: nopWriteCloser{Writer: w},
 	})
 }
 
+func (s *MCPServer) startHealthCheck(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.stopHealth = cancel
+	s.mu.Unlock()
+
+	s.health.Add(1)
+	go func() {
+		defer s.health.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkHealth(ctx)
+			}
+		}
+	}()
+}
+
+// checkHealth pings the client, reconnecting when it does not answer.
+func (s *MCPServer) checkHealth(ctx context.Context) {
+	conn, err := s.ensureConnection(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Warn("mongodb health check failed", "error", err)
+		}
+		return
+	}
+	conn.release()
+}
+
+// stopHealthCheck stops the health check and waits for it, so it cannot reconnect after
+// the server closed the client.
+func (s *MCPServer) stopHealthCheck() {
+	s.mu.Lock()
+	stop := s.stopHealth
+	s.stopHealth = nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+	s.health.Wait()
+}
+
 func (s *MCPServer) Close(ctx context.Context) error {
+	s.stopHealthCheck()
+
 	s.mu.Lock()
-	client := s.client
-	s.client = nil
+	conn := s.conn
+	s.conn = nil
 	cancel := s.cancel
 	s.cancel = nil
 	s.mu.Unlock()
@@ -115,8 +257,8 @@ func (s *MCPServer) Close(ctx context.Context) error {
 	}
 
 	var errs []error
-	if client != nil {
-		if err := client.Disconnect(ctx); err != nil {
+	if conn != nil {
+		if err := s.disconnect(ctx, conn.client); err != nil {
 			errs = append(errs, fmt.Errorf("failed to disconnect mongo client: %w", err))
 		}
 	}
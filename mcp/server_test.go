@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/drewjocham/mongo-migration-tool/internal/config"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func TestHealthCheckReconnectsAfterDrop(t *testing.T) {
+	ctx := context.Background()
+	srv, err := NewMCPServer(&config.Config{Database: "app"}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+
+	var dials atomic.Int32
+	var dropped atomic.Pointer[mongo.Client]
+	srv.dial = func(context.Context) (*mongo.Client, error) {
+		dials.Add(1)
+		// Connect is lazy, so no server is needed as long as ping is faked.
+		return mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	}
+	srv.ping = func(_ context.Context, c *mongo.Client) error {
+		if c == dropped.Load() {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	}
+
+	first, err := srv.ensureConnection(ctx)
+	if err != nil {
+		t.Fatalf("initial connection: %v", err)
+	}
+	first.release()
+	dropped.Store(first.client)
+
+	srv.startHealthCheck(ctx, time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for dials.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("health check did not reconnect after the connection dropped")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	conn, err := srv.ensureConnection(ctx)
+	if err != nil {
+		t.Fatalf("tool call after reconnect: %v", err)
+	}
+	conn.release()
+	if conn.engine == first.engine || conn.db == nil {
+		t.Error("tool call should use the engine built by the reconnect")
+	}
+
+	if err := srv.Close(ctx); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	after := dials.Load()
+	time.Sleep(10 * time.Millisecond)
+	if dials.Load() != after || after != 2 {
+		t.Errorf("dials = %d then %d, want exactly one reconnect and none after Close", after, dials.Load())
+	}
+}
+
+// fakeConnections makes srv dial lazy clients that never reach a server and records the
+// clients it disconnects.
+func fakeConnections(srv *MCPServer) (dropped *atomic.Pointer[mongo.Client], disconnected chan *mongo.Client) {
+	dropped = new(atomic.Pointer[mongo.Client])
+	disconnected = make(chan *mongo.Client, 4)
+	srv.dial = func(context.Context) (*mongo.Client, error) {
+		return mongo.Connect(options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	}
+	srv.ping = func(_ context.Context, c *mongo.Client) error {
+		if c == dropped.Load() {
+			return errors.New("connection reset by peer")
+		}
+		return nil
+	}
+	srv.disconnect = func(_ context.Context, c *mongo.Client) error {
+		disconnected <- c
+		return nil
+	}
+	return dropped, disconnected
+}
+
+func TestReconnectKeepsReplacedClientUntilReleased(t *testing.T) {
+	ctx := context.Background()
+	srv, err := NewMCPServer(&config.Config{Database: "app"}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+	dropped, disconnected := fakeConnections(srv)
+
+	inUse, err := srv.ensureConnection(ctx)
+	if err != nil {
+		t.Fatalf("initial connection: %v", err)
+	}
+	dropped.Store(inUse.client)
+
+	fresh, err := srv.ensureConnection(ctx)
+	if err != nil {
+		t.Fatalf("reconnect: %v", err)
+	}
+	defer fresh.release()
+	if fresh == inUse {
+		t.Fatal("ensureConnection kept the dropped client")
+	}
+
+	select {
+	case c := <-disconnected:
+		t.Fatalf("client %p disconnected while a tool call still used it", c)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	inUse.release()
+	select {
+	case c := <-disconnected:
+		if c != inUse.client {
+			t.Errorf("disconnected %p, want the replaced client %p", c, inUse.client)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("replaced client was not disconnected after its last user released it")
+	}
+}
+
+func TestToolCallsDoNotWaitForEachOther(t *testing.T) {
+	ctx := context.Background()
+	srv, err := NewMCPServer(&config.Config{Database: "app"}, slog.New(slog.DiscardHandler))
+	if err != nil {
+		t.Fatalf("NewMCPServer: %v", err)
+	}
+	fakeConnections(srv)
+	conn, err := srv.ensureConnection(ctx)
+	if err != nil {
+		t.Fatalf("initial connection: %v", err)
+	}
+	conn.release()
+
+	stalled, unblock := make(chan struct{}), make(chan struct{})
+	var pings atomic.Int32
+	srv.ping = func(context.Context, *mongo.Client) error {
+		if pings.Add(1) == 1 {
+			close(stalled)
+			<-unblock
+		}
+		return nil
+	}
+	go func() {
+		if conn, err := srv.ensureConnection(ctx); err == nil {
+			conn.release()
+		}
+	}()
+	<-stalled
+	defer close(unblock)
+
+	done := make(chan error, 1)
+	go func() {
+		conn, err := srv.ensureConnection(ctx)
+		if err == nil {
+			conn.release()
+		}
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("second tool call: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("a tool call waited for another call's ping")
+	}
+}
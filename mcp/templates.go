@@ -11,12 +11,22 @@ var migrationTemplateRaw string
 
 var migrationTemplate = template.Must(template.New("migration").Parse(migrationTemplateRaw))
 
+//go:embed author_migration.md.tmpl
+var authorMigrationPromptRaw string
+
+var authorMigrationPrompt = template.Must(template.New("author_migration").Parse(authorMigrationPromptRaw))
+
 type migrationData struct {
 	StructName  string
 	Version     string
 	Description string
 }
 
+type authorMigrationData struct {
+	Goal       string
+	Collection string
+}
+
 func toCamelCase(s string) string {
 	parts := strings.Split(s, "_")
 	for i, p := range parts {
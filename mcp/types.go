@@ -1,23 +1,55 @@
 package mcp
 
-type emptyArgs struct{}
+import "github.com/drewjocham/mongo-migration-tool/internal/migration"
+
+type listArgs struct{}
+
+type statusArgs struct {
+	Verbose bool `json:"verbose,omitempty" jsonschema:"Add checksum and duration columns for applied migrations."`
+}
 
 type versionArgs struct {
-	Version string `json:"version,omitempty"`
+	Version string `json:"version,omitempty" jsonschema:"Target version, e.g. 20240101_001. Omit for all migrations."`
+}
+
+type schemaArgs struct {
+	Collection     []string `json:"collection,omitempty" jsonschema:"Only show these collections, matched by exact name."`
+	Pattern        string   `json:"pattern,omitempty" jsonschema:"Regular expression that collection names must match."`
+	MaxCollections int      `json:"max_collections,omitempty" jsonschema:"Collection limit (default 50, capped at 500)."`
+}
+
+type oplogArgs struct {
+	Namespace string `json:"namespace,omitempty" jsonschema:"Only show entries for this db.collection namespace."`
+	Limit     int    `json:"limit,omitempty" jsonschema:"Number of most recent entries (default 20, capped at 200)."`
+	MaxFields int    `json:"max_fields,omitempty" jsonschema:"Document fields shown per entry (default 5, capped at 50)."`
+	MaxLen    int    `json:"max_len,omitempty" jsonschema:"Characters shown per document (default 80, 10 to 1000)."`
 }
 
 type messageOutput struct {
-	Message string `json:"message"`
+	Message   string `json:"message"`
+	ErrorCode string `json:"error_code,omitempty"`
+	Version   string `json:"version,omitempty"`
+	// Path is the absolute path of the file migration_create wrote, or would write.
+	Path string `json:"path,omitempty"`
+	// Versions, Skipped and DurationMS describe a completed migration_up or migration_down run.
+	Versions   []string `json:"versions,omitempty"`
+	Skipped    []string `json:"skipped,omitempty"`
+	DurationMS int64    `json:"duration_ms,omitempty"`
+	// Truncated is set when database_schema stopped before the last collection.
+	Truncated bool `json:"truncated,omitempty"`
+	// Migrations is the registered catalog returned by migration_list.
+	Migrations []migration.CatalogEntry `json:"migrations,omitempty"`
 }
 
 type createMigrationArgs struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string `json:"name" jsonschema:"Short snake_case name used in the file name and struct name."`
+	Description string `json:"description" jsonschema:"Human-readable summary recorded with the migration."`
+	DryRun      bool   `json:"dry_run,omitempty" jsonschema:"Only report the absolute path the file would be written to."`
 }
 
 type parsePayloadArgs struct {
-	Payload   string `json:"payload"`
-	Format    string `json:"format,omitempty"`
-	TypeField string `json:"typeField,omitempty"`
-	TypeName  string `json:"type,omitempty"`
+	Payload   string `json:"payload" jsonschema:"JSON document, or base64-encoded BSON when format is bson."`
+	Format    string `json:"format,omitempty" jsonschema:"Payload encoding: json (default) or bson."`
+	TypeField string `json:"typeField,omitempty" jsonschema:"Field in the payload that names its registered type."`
+	TypeName  string `json:"type,omitempty" jsonschema:"Registered type to validate against; overrides typeField."`
 }